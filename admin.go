@@ -0,0 +1,180 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const adminSlowCommands = 32
+
+type adminStats struct {
+	NopMetrics
+
+	localHits, localMisses, redisHits, redisMisses atomic.Uint64
+
+	mu   sync.Mutex
+	slow []AdminSlowCommand
+}
+
+type AdminSlowCommand struct {
+	Command  string        `json:"command"`
+	Duration time.Duration `json:"duration_ns"`
+	At       time.Time     `json:"at"`
+}
+
+func (s *adminStats) ObserveCacheLookup(layer string, hit bool) {
+	switch {
+	case layer == "local" && hit:
+		s.localHits.Add(1)
+	case layer == "local":
+		s.localMisses.Add(1)
+	case hit:
+		s.redisHits.Add(1)
+	default:
+		s.redisMisses.Add(1)
+	}
+}
+
+func (s *adminStats) ObserveSlowCommand(cmd string, duration time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.slow) == adminSlowCommands {
+		s.slow = s.slow[1:]
+	}
+	s.slow = append(s.slow, AdminSlowCommand{Command: cmd, Duration: duration, At: time.Now()})
+}
+
+type adminOnce struct {
+	once  sync.Once
+	stats *adminStats
+}
+
+type AdminHitRatio struct {
+	Hits   uint64  `json:"hits"`
+	Misses uint64  `json:"misses"`
+	Ratio  float64 `json:"ratio"`
+}
+
+func hitRatio(hits, misses uint64) AdminHitRatio {
+	r := AdminHitRatio{Hits: hits, Misses: misses}
+	if hits+misses > 0 {
+		r.Ratio = float64(hits) / float64(hits+misses)
+	}
+	return r
+}
+
+type AdminServerInfo struct {
+	Version string   `json:"version,omitempty"`
+	Mode    string   `json:"mode,omitempty"` // standalone, sentinel or cluster
+	Modules []string `json:"modules,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+type AdminReport struct {
+	Prefix       string                   `json:"prefix"`
+	Ready        bool                     `json:"ready"`
+	Latency      time.Duration            `json:"latency_ns"`
+	Role         string                   `json:"role,omitempty"`
+	Pool         ConnStats                `json:"pool"`
+	Breaker      string                   `json:"breaker"`
+	HitRatios    map[string]AdminHitRatio `json:"hit_ratios"`
+	SlowCommands []AdminSlowCommand       `json:"slow_commands"`
+	Server       AdminServerInfo          `json:"server"`
+}
+
+// AdminHandler returns a JSON diagnostics endpoint, usually mounted at
+// /debug/redis. Hit ratios and slow commands are recorded from the first call
+// on, slow commands need a running SlowLogPoller.
+func (client *Client) AdminHandler() http.Handler {
+	client.admin.once.Do(func() {
+		client.admin.stats = &adminStats{}
+		client.AddMetrics(client.admin.stats)
+	})
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+		defer cancel()
+
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.Encode(client.AdminReport(ctx))
+	})
+}
+
+func (client *Client) AdminReport(ctx context.Context) *AdminReport {
+	rep := &AdminReport{
+		Prefix:  client.config.Prefix,
+		Breaker: client.BreakerState().String(),
+	}
+	if h, _ := client.Health(ctx); h != nil {
+		rep.Ready, rep.Latency, rep.Role, rep.Pool = h.Ready, h.Latency, h.Role, h.Pool
+	}
+	if s := client.admin.stats; s != nil {
+		rep.HitRatios = map[string]AdminHitRatio{
+			"local": hitRatio(s.localHits.Load(), s.localMisses.Load()),
+			"redis": hitRatio(s.redisHits.Load(), s.redisMisses.Load()),
+		}
+		s.mu.Lock()
+		rep.SlowCommands = append([]AdminSlowCommand(nil), s.slow...)
+		s.mu.Unlock()
+	}
+	rep.Server = client.serverInfo(ctx)
+	return rep
+}
+
+func (client *Client) serverInfo(ctx context.Context) AdminServerInfo {
+	var info AdminServerInfo
+	data_str, e := client.client.Info(ctx, "server").Result()
+	if e != nil {
+		info.Error = e.Error()
+		return info
+	}
+	for _, line := range strings.Split(data_str, "\n") {
+		k, v, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		switch k {
+		case "redis_version":
+			info.Version = v
+		case "redis_mode":
+			info.Mode = v
+		}
+	}
+
+	// MODULE LIST is missing on managed offerings, that is not an error here
+	info.Modules, _ = client.moduleList(ctx)
+	return info
+}
+
+func (client *Client) moduleList(ctx context.Context) ([]string, error) {
+	modules, e := client.client.Do(ctx, "module", "list").Slice()
+	if e != nil {
+		return nil, e
+	}
+	var names []string
+	for _, m := range modules {
+		fields, ok := m.([]interface{})
+		if !ok {
+			if mm, ok := m.(map[interface{}]interface{}); ok {
+				if name, ok := mm["name"].(string); ok {
+					names = append(names, name)
+				}
+			}
+			continue
+		}
+		for i := 0; i+1 < len(fields); i += 2 {
+			if fields[i] == "name" {
+				if name, ok := fields[i+1].(string); ok {
+					names = append(names, name)
+				}
+			}
+		}
+	}
+	return names, nil
+}
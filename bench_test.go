@@ -0,0 +1,127 @@
+package redis_test
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/acsl-go/redis/redistest"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// The benchmarks run GET and SET through raw go-redis, the thin client and
+// the full client against the same miniredis server. miniredis is slower than
+// Redis, so the absolute figures are not meaningful, the overhead of the
+// wrapper per op is: compare the raw and client rows with benchstat or
+// cmd/redisbench against a live server.
+
+type benchPayload struct {
+	ID    int    `json:"id"`
+	Value string `json:"value"`
+}
+
+func benchKeys(n int) []string {
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+	}
+	return keys
+}
+
+func BenchmarkSet(b *testing.B) {
+	client, mr := redistest.NewTestClient(b, nil)
+	raw := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	defer raw.Close()
+	thin := client.Thin()
+	ctx := context.Background()
+	value := strings.Repeat("x", 64)
+	p := &benchPayload{ID: 1, Value: value}
+	keys := benchKeys(1000)
+
+	b.Run("raw", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if e := raw.Set(ctx, "test:"+keys[i%len(keys)], value, time.Minute).Err(); e != nil {
+				b.Fatal(e)
+			}
+		}
+	})
+	b.Run("thin", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if e := thin.Set(ctx, "test:"+keys[i%len(keys)], value, time.Minute); e != nil {
+				b.Fatal(e)
+			}
+		}
+	})
+	b.Run("setstr", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if e := client.SetStr(ctx, keys[i%len(keys)], value, 60); e != nil {
+				b.Fatal(e)
+			}
+		}
+	})
+	b.Run("set", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if e := client.Set(ctx, keys[i%len(keys)], p, 60); e != nil {
+				b.Fatal(e)
+			}
+		}
+	})
+}
+
+func BenchmarkGet(b *testing.B) {
+	client, mr := redistest.NewTestClient(b, nil)
+	raw := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	defer raw.Close()
+	thin := client.Thin()
+	ctx := context.Background()
+	value := strings.Repeat("x", 64)
+	keys := benchKeys(1000)
+	for _, key := range keys {
+		if e := client.SetStr(ctx, key, value, 0); e != nil {
+			b.Fatal(e)
+		}
+		if e := client.Set(ctx, "json:"+key, &benchPayload{ID: 1, Value: value}, 0); e != nil {
+			b.Fatal(e)
+		}
+	}
+
+	b.Run("raw", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if e := raw.Get(ctx, "test:"+keys[i%len(keys)]).Err(); e != nil {
+				b.Fatal(e)
+			}
+		}
+	})
+	b.Run("thin", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, e := thin.Get(ctx, "test:"+keys[i%len(keys)]); e != nil {
+				b.Fatal(e)
+			}
+		}
+	})
+	b.Run("getstr", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, e := client.GetStr(ctx, keys[i%len(keys)]); e != nil {
+				b.Fatal(e)
+			}
+		}
+	})
+	b.Run("get", func(b *testing.B) {
+		b.ReportAllocs()
+		var v benchPayload
+		for i := 0; i < b.N; i++ {
+			if e := client.Get(ctx, "json:"+keys[i%len(keys)], &v); e != nil {
+				b.Fatal(e)
+			}
+		}
+	})
+}
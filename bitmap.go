@@ -0,0 +1,171 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// SetBit returns the previous value of the bit.
+func (client *Client) SetBit(ctx context.Context, key string, offset int64, value bool) (bool, error) {
+	key_str := client.fullKey(key)
+	bit := 0
+	if value {
+		bit = 1
+	}
+	old, e := client.client.SetBit(ctx, key_str, offset, bit).Result()
+	if e != nil {
+		return false, client.wrap(e, "RedisSetBit", "setbit", key)
+	}
+	client.invalidateLocal(ctx, key_str)
+	return old == 1, nil
+}
+
+func (client *Client) GetBit(ctx context.Context, key string, offset int64) (bool, error) {
+	key_str := client.fullKey(key)
+	bit, e := client.client.GetBit(ctx, key_str, offset).Result()
+	if e != nil {
+		return false, client.wrap(e, "RedisGetBit", "getbit", key)
+	}
+	return bit == 1, nil
+}
+
+// BitCount counts the set bits, in the byte range [start, end] when given.
+func (client *Client) BitCount(ctx context.Context, key string, byteRange ...int64) (int64, error) {
+	key_str := client.fullKey(key)
+	var bc *goredis.BitCount
+	if len(byteRange) == 2 {
+		bc = &goredis.BitCount{Start: byteRange[0], End: byteRange[1]}
+	}
+	n, e := client.client.BitCount(ctx, key_str, bc).Result()
+	if e != nil {
+		return 0, client.wrap(e, "RedisBitCount", "bitcount", key)
+	}
+	return n, nil
+}
+
+// BitPos returns the position of the first bit set to value, -1 when there
+// is none.
+func (client *Client) BitPos(ctx context.Context, key string, value bool, byteRange ...int64) (int64, error) {
+	key_str := client.fullKey(key)
+	bit := int64(0)
+	if value {
+		bit = 1
+	}
+	pos, e := client.client.BitPos(ctx, key_str, bit, byteRange...).Result()
+	if e != nil {
+		return 0, client.wrap(e, "RedisBitPos", "bitpos", key)
+	}
+	return pos, nil
+}
+
+type BitOperation string
+
+const (
+	BitAnd BitOperation = "AND"
+	BitOr  BitOperation = "OR"
+	BitXor BitOperation = "XOR"
+	BitNot BitOperation = "NOT"
+)
+
+// BitOp stores the result in dest and returns its length in bytes. All keys
+// must share a hash slot on a cluster.
+func (client *Client) BitOp(ctx context.Context, op BitOperation, dest string, keys ...string) (int64, error) {
+	if e := client.checkSlots(append([]string{dest}, keys...)...); e != nil {
+		return 0, client.wrap(e, "RedisBitOp", "bitop", dest)
+	}
+	dest_str := client.fullKey(dest)
+	key_strs := make([]string, len(keys))
+	for i, key := range keys {
+		key_strs[i] = client.fullKey(key)
+	}
+
+	var cmd *goredis.IntCmd
+	switch op {
+	case BitAnd:
+		cmd = client.client.BitOpAnd(ctx, dest_str, key_strs...)
+	case BitOr:
+		cmd = client.client.BitOpOr(ctx, dest_str, key_strs...)
+	case BitXor:
+		cmd = client.client.BitOpXor(ctx, dest_str, key_strs...)
+	case BitNot:
+		cmd = client.client.BitOpNot(ctx, dest_str, firstKey(key_strs))
+	default:
+		return 0, fmt.Errorf("redis: unknown bit operation %q", op)
+	}
+	n, e := cmd.Result()
+	if e != nil {
+		return 0, client.wrap(e, "RedisBitOp", "bitop", dest)
+	}
+	client.invalidateLocal(ctx, dest_str)
+	return n, nil
+}
+
+// DailyActiveTracker sets one bit per user id per UTC day in
+// <prefix>:dau:{<name>}:<yyyy-mm-dd>. The name is a hash tag so the days can
+// be combined with BITOP on a cluster.
+type DailyActiveTracker struct {
+	client    *Client
+	name      string
+	retention time.Duration
+}
+
+func (client *Client) DailyActiveTracker(name string, retention time.Duration) *DailyActiveTracker {
+	if retention <= 0 {
+		retention = 90 * 24 * time.Hour
+	}
+	return &DailyActiveTracker{client: client, name: name, retention: retention}
+}
+
+func (d *DailyActiveTracker) dayKey(day time.Time) string {
+	return "dau:{" + d.name + "}:" + day.UTC().Format("2006-01-02")
+}
+
+func (d *DailyActiveTracker) Mark(ctx context.Context, userID int64, at time.Time) error {
+	key := d.dayKey(at)
+	key_str := d.client.fullKey(key)
+	pipe := d.client.client.Pipeline()
+	pipe.SetBit(ctx, key_str, userID, 1)
+	pipe.Expire(ctx, key_str, d.retention)
+	if _, e := pipe.Exec(ctx); e != nil {
+		return d.client.wrap(e, "RedisDailyActiveMark", "setbit", key)
+	}
+	return nil
+}
+
+func (d *DailyActiveTracker) WasActive(ctx context.Context, userID int64, day time.Time) (bool, error) {
+	return d.client.GetBit(ctx, d.dayKey(day), userID)
+}
+
+func (d *DailyActiveTracker) Count(ctx context.Context, day time.Time) (int64, error) {
+	return d.client.BitCount(ctx, d.dayKey(day))
+}
+
+// CountAll returns the users active on every given day.
+func (d *DailyActiveTracker) CountAll(ctx context.Context, days ...time.Time) (int64, error) {
+	return d.combine(ctx, BitAnd, "and", days)
+}
+
+// CountAny returns the users active on at least one of the given days.
+func (d *DailyActiveTracker) CountAny(ctx context.Context, days ...time.Time) (int64, error) {
+	return d.combine(ctx, BitOr, "or", days)
+}
+
+func (d *DailyActiveTracker) combine(ctx context.Context, op BitOperation, tag string, days []time.Time) (int64, error) {
+	if len(days) == 0 {
+		return 0, nil
+	}
+	keys := make([]string, len(days))
+	for i, day := range days {
+		keys[i] = d.dayKey(day)
+	}
+	dest := "dau:{" + d.name + "}:tmp:" + tag + ":" + strconv.FormatInt(time.Now().UnixNano(), 36)
+	if _, e := d.client.BitOp(ctx, op, dest, keys...); e != nil {
+		return 0, e
+	}
+	defer d.client.client.Del(ctx, d.client.fullKey(dest))
+	return d.client.BitCount(ctx, dest)
+}
@@ -0,0 +1,143 @@
+package redis
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+
+	"github.com/pkg/errors"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const defaultBlobChunkSize = 512 * 1024
+
+// Payloads replaced by a blob reference start with this marker, encoded
+// values never begin with a NUL byte.
+var blobRefMarker = []byte("\x00blobref:")
+
+// Blobs are split in chunks stored under <prefix>:blob:{id}:<n>, the meta hash
+// <prefix>:blob:{id} keeps size and chunk count. The hash tag keeps all parts
+// of a blob on one cluster node.
+func (client *Client) blobKey(id string) string {
+	return client.fullKey("blob:{" + id + "}")
+}
+
+func (client *Client) blobChunkSize() int {
+	if client.config.BlobChunkSize > 0 {
+		return client.config.BlobChunkSize
+	}
+	return defaultBlobChunkSize
+}
+
+// PutBlob stores data in chunks and returns its id.
+func (client *Client) PutBlob(ctx context.Context, data []byte, ttl int) (string, error) {
+	raw := make([]byte, 16)
+	if _, e := rand.Read(raw); e != nil {
+		return "", errors.Wrap(e, "RedisPutBlob")
+	}
+	id := hex.EncodeToString(raw)
+	key_str := client.blobKey(id)
+	expiration := client.expiration(ttl)
+	size := client.blobChunkSize()
+
+	pipe := client.client.TxPipeline()
+	chunks := 0
+	for off := 0; off < len(data) || chunks == 0; off += size {
+		end := min(off+size, len(data))
+		pipe.Set(ctx, key_str+":"+strconv.Itoa(chunks), data[off:end], expiration)
+		chunks++
+	}
+	pipe.HSet(ctx, key_str, "size", len(data), "chunks", chunks)
+	if expiration > 0 {
+		pipe.Expire(ctx, key_str, expiration)
+	}
+	if _, e := pipe.Exec(ctx); e != nil {
+		return "", client.wrap(e, "RedisPutBlob", "set", "blob:"+id)
+	}
+	return id, nil
+}
+
+func (client *Client) GetBlob(ctx context.Context, id string) ([]byte, error) {
+	key_str := client.blobKey(id)
+	meta, e := client.client.HMGet(ctx, key_str, "size", "chunks").Result()
+	if e != nil {
+		return nil, client.wrap(e, "RedisGetBlob", "hmget", "blob:"+id)
+	}
+	size_str, _ := meta[0].(string)
+	chunks_str, _ := meta[1].(string)
+	if chunks_str == "" {
+		return nil, ErrNotFound
+	}
+	size, _ := strconv.Atoi(size_str)
+	chunks, _ := strconv.Atoi(chunks_str)
+
+	pipe := client.client.Pipeline()
+	cmds := make([]*goredis.StringCmd, chunks)
+	for i := range cmds {
+		cmds[i] = pipe.Get(ctx, key_str+":"+strconv.Itoa(i))
+	}
+	if _, e := pipe.Exec(ctx); e != nil {
+		if e == goredis.Nil {
+			return nil, ErrNotFound
+		}
+		return nil, client.wrap(e, "RedisGetBlob", "get", "blob:"+id)
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, size))
+	for _, cmd := range cmds {
+		b, _ := cmd.Bytes()
+		buf.Write(b)
+	}
+	if buf.Len() != size {
+		return nil, client.wrap(errors.New("blob is incomplete"), "RedisGetBlob", "", "blob:"+id)
+	}
+	return buf.Bytes(), nil
+}
+
+func (client *Client) DelBlob(ctx context.Context, id string) error {
+	key_str := client.blobKey(id)
+	chunks, e := client.client.HGet(ctx, key_str, "chunks").Int()
+	if e != nil && e != goredis.Nil {
+		return client.wrap(e, "RedisDelBlob", "hget", "blob:"+id)
+	}
+	keys := []string{key_str}
+	for i := 0; i < chunks; i++ {
+		keys = append(keys, key_str+":"+strconv.Itoa(i))
+	}
+	if e := client.client.Del(ctx, keys...).Err(); e != nil {
+		return client.wrap(e, "RedisDelBlob", "del", "blob:"+id)
+	}
+	return nil
+}
+
+// OffloadPayload moves payloads larger than Config.OffloadThreshold into a
+// blob and returns a small reference to enqueue instead. Smaller payloads and
+// a disabled threshold return payload unchanged.
+func (client *Client) OffloadPayload(ctx context.Context, payload []byte, ttl int) ([]byte, error) {
+	if client.config.OffloadThreshold <= 0 || len(payload) <= client.config.OffloadThreshold {
+		return payload, nil
+	}
+	id, e := client.PutBlob(ctx, payload, ttl)
+	if e != nil {
+		return nil, e
+	}
+	return append(append([]byte{}, blobRefMarker...), id...), nil
+}
+
+// ResolvePayload reverses OffloadPayload, the blob is kept so that a job can
+// be retried, release it with ReleasePayload once the job is done.
+func (client *Client) ResolvePayload(ctx context.Context, payload []byte) ([]byte, error) {
+	if !bytes.HasPrefix(payload, blobRefMarker) {
+		return payload, nil
+	}
+	return client.GetBlob(ctx, string(payload[len(blobRefMarker):]))
+}
+
+func (client *Client) ReleasePayload(ctx context.Context, payload []byte) error {
+	if !bytes.HasPrefix(payload, blobRefMarker) {
+		return nil
+	}
+	return client.DelBlob(ctx, string(payload[len(blobRefMarker):]))
+}
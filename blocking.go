@@ -0,0 +1,112 @@
+package redis
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// blockSlice is the longest server side timeout of one blocking call. Longer
+// waits are split into slices so that a cancelled context is noticed within
+// a slice instead of holding the connection until the full timeout.
+const blockSlice = time.Second
+
+// block calls fn with server side timeouts of at most blockSlice until it
+// returns something other than goredis.Nil, timeout elapses or ctx is done.
+// A timeout of 0 waits until ctx is done. The calls themselves run without
+// the deadline of ctx, which would otherwise break the connection midway,
+// so a reply popped right after cancellation is still returned.
+func (client *Client) block(ctx context.Context, timeout time.Duration, fn func(ctx context.Context, wait time.Duration) error) error {
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	call_ctx := context.WithoutCancel(ctx)
+	for {
+		if e := ctx.Err(); e != nil {
+			return e
+		}
+		wait := blockSlice
+		if !deadline.IsZero() {
+			left := time.Until(deadline)
+			if left <= 0 {
+				return goredis.Nil
+			}
+			wait = min(wait, left)
+		}
+		// The server counts whole seconds, 0 would block forever
+		wait = (wait + time.Second - 1).Truncate(time.Second)
+		if e := fn(call_ctx, wait); e != goredis.Nil {
+			return e
+		}
+	}
+}
+
+// BLPop pops the first element of the first non-empty list among keys,
+// waiting up to timeout for one, 0 waits until ctx is done. It returns the
+// key, without the prefix, and the element, ErrNotFound when the timeout
+// elapsed and the error of ctx when it is done. The server counts whole
+// seconds, so the wait may exceed timeout by up to a second.
+func (client *Client) BLPop(ctx context.Context, timeout time.Duration, keys ...string) (string, string, error) {
+	return client.blockingListPop(ctx, "RedisBLPop", "blpop", client.client.BLPop, timeout, keys)
+}
+
+func (client *Client) BRPop(ctx context.Context, timeout time.Duration, keys ...string) (string, string, error) {
+	return client.blockingListPop(ctx, "RedisBRPop", "brpop", client.client.BRPop, timeout, keys)
+}
+
+func (client *Client) blockingListPop(ctx context.Context, op, cmd string, pop func(ctx context.Context, timeout time.Duration, keys ...string) *goredis.StringSliceCmd, timeout time.Duration, keys []string) (string, string, error) {
+	if e := client.checkSlots(keys...); e != nil {
+		return "", "", client.wrap(e, op, cmd, firstKey(keys))
+	}
+	key_strs := client.prefixKeys(keys)
+	var res []string
+	e := client.block(ctx, timeout, func(ctx context.Context, wait time.Duration) error {
+		var e error
+		res, e = pop(ctx, wait, key_strs...).Result()
+		return e
+	})
+	if e != nil {
+		return "", "", client.blockErr(e, op, cmd, keys)
+	}
+	return strings.TrimPrefix(res[0], client.config.Prefix+":"), res[1], nil
+}
+
+// BZPopMin pops the member with the lowest score of the first non-empty
+// sorted set among keys, see BLPop for timeout.
+func (client *Client) BZPopMin(ctx context.Context, timeout time.Duration, keys ...string) (string, goredis.Z, error) {
+	return client.blockingZPop(ctx, "RedisBZPopMin", "bzpopmin", client.client.BZPopMin, timeout, keys)
+}
+
+func (client *Client) BZPopMax(ctx context.Context, timeout time.Duration, keys ...string) (string, goredis.Z, error) {
+	return client.blockingZPop(ctx, "RedisBZPopMax", "bzpopmax", client.client.BZPopMax, timeout, keys)
+}
+
+func (client *Client) blockingZPop(ctx context.Context, op, cmd string, pop func(ctx context.Context, timeout time.Duration, keys ...string) *goredis.ZWithKeyCmd, timeout time.Duration, keys []string) (string, goredis.Z, error) {
+	if e := client.checkSlots(keys...); e != nil {
+		return "", goredis.Z{}, client.wrap(e, op, cmd, firstKey(keys))
+	}
+	key_strs := client.prefixKeys(keys)
+	var res *goredis.ZWithKey
+	e := client.block(ctx, timeout, func(ctx context.Context, wait time.Duration) error {
+		var e error
+		res, e = pop(ctx, wait, key_strs...).Result()
+		return e
+	})
+	if e != nil {
+		return "", goredis.Z{}, client.blockErr(e, op, cmd, keys)
+	}
+	return strings.TrimPrefix(res.Key, client.config.Prefix+":"), res.Z, nil
+}
+
+func (client *Client) blockErr(e error, op, cmd string, keys []string) error {
+	switch {
+	case e == goredis.Nil:
+		return ErrNotFound
+	case e == context.Canceled || e == context.DeadlineExceeded:
+		return e
+	}
+	return client.wrap(e, op, cmd, firstKey(keys))
+}
@@ -0,0 +1,209 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+var ErrCircuitOpen = errors.New("redis: circuit breaker is open")
+
+type BreakerState int
+
+const (
+	BreakerClosed BreakerState = iota
+	BreakerOpen
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half_open"
+	}
+	return "unknown"
+}
+
+type BreakerConfig struct {
+	Enabled          bool          `mapstructure:"enabled"`
+	FailureRate      float64       `mapstructure:"failure_rate"`       // Share of failed commands that opens the breaker, defaults to 0.5
+	MinRequests      int           `mapstructure:"min_requests"`       // Commands needed in a window before the rate is evaluated, defaults to 20
+	Window           time.Duration `mapstructure:"window"`             // Defaults to ten seconds
+	OpenTimeout      time.Duration `mapstructure:"open_timeout"`       // Time spent open before probing, defaults to five seconds
+	HalfOpenRequests int           `mapstructure:"half_open_requests"` // Probes that must succeed to close again, defaults to 1
+
+	// Fallback is called instead of the command while the breaker is open, it
+	// may fill the command's value and return nil. ErrCircuitOpen is returned
+	// when it is not set. Pipelined commands get a call each.
+	Fallback      func(ctx context.Context, cmd goredis.Cmder) error `mapstructure:"-"`
+	OnStateChange func(from, to BreakerState)                        `mapstructure:"-"`
+}
+
+type breaker struct {
+	cfg BreakerConfig
+
+	mu          sync.Mutex
+	state       BreakerState
+	windowStart time.Time
+	requests    int
+	failures    int
+	openedAt    time.Time
+	probes      int // Probes in flight while half open
+	successes   int // Successful probes while half open
+}
+
+func newBreaker(cfg BreakerConfig) *breaker {
+	if cfg.FailureRate <= 0 {
+		cfg.FailureRate = 0.5
+	}
+	if cfg.MinRequests <= 0 {
+		cfg.MinRequests = 20
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = 10 * time.Second
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = 5 * time.Second
+	}
+	if cfg.HalfOpenRequests <= 0 {
+		cfg.HalfOpenRequests = 1
+	}
+	return &breaker{cfg: cfg, windowStart: time.Now()}
+}
+
+// BreakerState returns the state of the circuit breaker, BreakerClosed when it is disabled.
+func (client *Client) BreakerState() BreakerState {
+	if client.breaker == nil {
+		return BreakerClosed
+	}
+	client.breaker.mu.Lock()
+	defer client.breaker.mu.Unlock()
+	return client.breaker.state
+}
+
+func (b *breaker) setState(to BreakerState) {
+	from := b.state
+	if from == to {
+		return
+	}
+	b.state = to
+	b.requests, b.failures, b.probes, b.successes = 0, 0, 0, 0
+	b.windowStart = time.Now()
+	if to == BreakerOpen {
+		b.openedAt = time.Now()
+	}
+	if b.cfg.OnStateChange != nil {
+		go b.cfg.OnStateChange(from, to)
+	}
+}
+
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.cfg.OpenTimeout {
+			return false
+		}
+		b.setState(BreakerHalfOpen)
+		fallthrough
+	case BreakerHalfOpen:
+		if b.probes >= b.cfg.HalfOpenRequests {
+			return false
+		}
+		b.probes++
+	}
+	return true
+}
+
+func (b *breaker) done(e error) {
+	failed := isBreakerFailure(e)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerHalfOpen:
+		if failed {
+			b.setState(BreakerOpen)
+			return
+		}
+		if b.successes++; b.successes >= b.cfg.HalfOpenRequests {
+			b.setState(BreakerClosed)
+		}
+	case BreakerClosed:
+		if time.Since(b.windowStart) > b.cfg.Window {
+			b.windowStart, b.requests, b.failures = time.Now(), 0, 0
+		}
+		b.requests++
+		if failed {
+			b.failures++
+		}
+		if b.requests >= b.cfg.MinRequests && float64(b.failures)/float64(b.requests) >= b.cfg.FailureRate {
+			b.setState(BreakerOpen)
+		}
+	}
+}
+
+// Only failures that hint at an unhealthy server count, not misses or
+// errors caused by the command itself such as WRONGTYPE.
+func isBreakerFailure(e error) bool {
+	return IsRetryable(e) || errors.Is(e, context.DeadlineExceeded) || isPoolTimeout(e)
+}
+
+type breakerHook struct {
+	b *breaker
+}
+
+func (h *breakerHook) DialHook(next goredis.DialHook) goredis.DialHook {
+	return next
+}
+
+func (h *breakerHook) ProcessHook(next goredis.ProcessHook) goredis.ProcessHook {
+	return func(ctx context.Context, cmd goredis.Cmder) error {
+		if !h.b.allow() {
+			return h.reject(ctx, cmd)
+		}
+		e := next(ctx, cmd)
+		h.b.done(e)
+		return e
+	}
+}
+
+func (h *breakerHook) ProcessPipelineHook(next goredis.ProcessPipelineHook) goredis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []goredis.Cmder) error {
+		if !h.b.allow() {
+			// Like Exec, the first failed command is the error of the pipeline
+			var first error
+			for _, cmd := range cmds {
+				if e := h.reject(ctx, cmd); e != nil && first == nil {
+					first = e
+				}
+			}
+			return first
+		}
+		e := next(ctx, cmds)
+		h.b.done(e)
+		return e
+	}
+}
+
+// reject answers cmd from the Fallback while the circuit is open, or fails it
+// with ErrCircuitOpen.
+func (h *breakerHook) reject(ctx context.Context, cmd goredis.Cmder) error {
+	if h.b.cfg.Fallback == nil {
+		cmd.SetErr(ErrCircuitOpen)
+		return ErrCircuitOpen
+	}
+	e := h.b.cfg.Fallback(ctx, cmd)
+	if e != nil {
+		cmd.SetErr(e)
+	}
+	return e
+}
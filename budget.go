@@ -0,0 +1,49 @@
+package redis
+
+import (
+	"context"
+	"time"
+)
+
+// Budget splits the remaining deadline of a context across the steps of an
+// operation. Every step gets an equal share of what is left when it starts,
+// so a slow step only eats into its own share and the time the later steps
+// leave unused.
+type Budget struct {
+	deadline time.Time
+	steps    int
+	min      time.Duration
+}
+
+// NewBudget plans steps calls within the deadline of ctx. A step never gets
+// less than min, so the last steps still get a chance when the budget is
+// almost spent. Without a deadline the steps are not limited.
+func NewBudget(ctx context.Context, steps int, min time.Duration) *Budget {
+	deadline, _ := ctx.Deadline()
+	return &Budget{deadline: deadline, steps: steps, min: min}
+}
+
+// Step returns the context for the next step, the cancel func must be called
+// once the step is done.
+func (b *Budget) Step(ctx context.Context) (context.Context, context.CancelFunc) {
+	if b.deadline.IsZero() {
+		return ctx, func() {}
+	}
+	left := max(b.steps, 1)
+	if b.steps > 1 {
+		b.steps--
+	}
+	share := time.Until(b.deadline) / time.Duration(left)
+	if share < b.min {
+		share = b.min
+	}
+	return context.WithTimeout(ctx, share)
+}
+
+// Remaining is the time left before the deadline, -1 without deadline.
+func (b *Budget) Remaining() time.Duration {
+	if b.deadline.IsZero() {
+		return -1
+	}
+	return time.Until(b.deadline)
+}
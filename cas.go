@@ -0,0 +1,98 @@
+package redis
+
+import (
+	"context"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// ARGV[3] is the expiration in milliseconds, -1 keeps the current one and 0
+// stores the value without expiry.
+var setIfEqualsScript = NewScript(`
+if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+local ms = tonumber(ARGV[3])
+if ms > 0 then
+	redis.call("SET", KEYS[1], ARGV[2], "PX", ms)
+elseif ms == -1 then
+	redis.call("SET", KEYS[1], ARGV[2], "KEEPTTL")
+else
+	redis.call("SET", KEYS[1], ARGV[2])
+end
+return 1
+`)
+
+var delIfEqualsScript = NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// SetIfEquals replaces the value of key by v only while it still holds
+// expected, both encoded with the codec. False means the key is missing or
+// holds something else. With KeepTTL as ttl the current expiry is kept.
+func (client *Client) SetIfEquals(ctx context.Context, key string, expected, v interface{}, ttl int) (bool, error) {
+	expected_str, e := client.codec.Marshal(expected)
+	if e != nil {
+		return false, client.wrap(e, "RedisSetIfEquals:JSONMarshal", "", key)
+	}
+	data_str, e := client.codec.Marshal(v)
+	if e != nil {
+		return false, client.wrap(e, "RedisSetIfEquals:JSONMarshal", "", key)
+	}
+	if e := client.checkSize("RedisSetIfEquals", key, data_str); e != nil {
+		return false, e
+	}
+	return client.setIfEquals(ctx, "RedisSetIfEquals", key, string(expected_str), string(data_str), ttl)
+}
+
+// SetStrIfEquals is SetIfEquals for raw strings, e.g. tokens stored with
+// SetStr or SetNXStr.
+func (client *Client) SetStrIfEquals(ctx context.Context, key, expected, v string, ttl int) (bool, error) {
+	return client.setIfEquals(ctx, "RedisSetStrIfEquals", key, expected, v, ttl)
+}
+
+func (client *Client) setIfEquals(ctx context.Context, op, key, expected, data_str string, ttl int) (bool, error) {
+	expiration := client.expiration(ttl)
+	ms := expiration.Milliseconds()
+	if expiration == goredis.KeepTTL {
+		ms = -1
+	}
+
+	n, e := setIfEqualsScript.Run(ctx, client, []string{key}, expected, data_str, ms).Int64()
+	if e != nil {
+		return false, client.wrap(e, op, "evalsha", key)
+	}
+	if n == 1 {
+		client.invalidateLocal(ctx, client.fullKey(key))
+	}
+	return n == 1, nil
+}
+
+// DelIfEquals deletes key only while it holds expected, encoded with the
+// codec, e.g. to release a lock or revoke a token without removing one that
+// has been replaced in the meantime.
+func (client *Client) DelIfEquals(ctx context.Context, key string, expected interface{}) (bool, error) {
+	expected_str, e := client.codec.Marshal(expected)
+	if e != nil {
+		return false, client.wrap(e, "RedisDelIfEquals:JSONMarshal", "", key)
+	}
+	return client.delIfEquals(ctx, "RedisDelIfEquals", key, string(expected_str))
+}
+
+func (client *Client) DelStrIfEquals(ctx context.Context, key, expected string) (bool, error) {
+	return client.delIfEquals(ctx, "RedisDelStrIfEquals", key, expected)
+}
+
+func (client *Client) delIfEquals(ctx context.Context, op, key, expected string) (bool, error) {
+	n, e := delIfEqualsScript.Run(ctx, client, []string{key}, expected).Int64()
+	if e != nil {
+		return false, client.wrap(e, op, "evalsha", key)
+	}
+	if n == 1 {
+		client.invalidateLocal(ctx, client.fullKey(key))
+	}
+	return n == 1, nil
+}
@@ -0,0 +1,86 @@
+package redis
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// lifecycle is shared by a client and all its views.
+type lifecycle struct {
+	ctx      context.Context // Cancelled on Close, parent of the client's background goroutines
+	cancel   context.CancelFunc
+	inflight atomic.Int64
+	closing  atomic.Bool
+	once     sync.Once
+	err      error
+}
+
+func newLifecycle() *lifecycle {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &lifecycle{ctx: ctx, cancel: cancel}
+}
+
+// Close stops the background work of the client, waits up to
+// Config.DrainTimeout for in-flight commands and pipelines and closes the
+// connection pool. Commands issued after Close fail with goredis.ErrClosed.
+func (client *Client) Close() error {
+	lc := client.lifecycle
+	lc.once.Do(func() {
+		lc.closing.Store(true)
+		lc.cancel()
+		client.drain(client.config.DrainTimeout)
+		lc.err = client.client.Close()
+		if client.replicas != nil {
+			client.replicas.close()
+		}
+	})
+	return lc.err
+}
+
+func (client *Client) drain(timeout time.Duration) {
+	if timeout <= 0 {
+		return
+	}
+	deadline := time.Now().Add(timeout)
+	for client.lifecycle.inflight.Load() > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+type lifecycleHook struct {
+	lc *lifecycle
+}
+
+func (h *lifecycleHook) DialHook(next goredis.DialHook) goredis.DialHook {
+	return next
+}
+
+func (h *lifecycleHook) ProcessHook(next goredis.ProcessHook) goredis.ProcessHook {
+	return func(ctx context.Context, cmd goredis.Cmder) error {
+		if h.lc.closing.Load() {
+			cmd.SetErr(goredis.ErrClosed)
+			return goredis.ErrClosed
+		}
+		h.lc.inflight.Add(1)
+		defer h.lc.inflight.Add(-1)
+		return next(ctx, cmd)
+	}
+}
+
+func (h *lifecycleHook) ProcessPipelineHook(next goredis.ProcessPipelineHook) goredis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []goredis.Cmder) error {
+		if h.lc.closing.Load() {
+			for _, cmd := range cmds {
+				cmd.SetErr(goredis.ErrClosed)
+			}
+			return goredis.ErrClosed
+		}
+		h.lc.inflight.Add(1)
+		defer h.lc.inflight.Add(-1)
+		return next(ctx, cmds)
+	}
+}
@@ -0,0 +1,99 @@
+// Command redisbench compares GET/SET through raw go-redis, the thin client
+// and the full client against a live server, to keep the overhead of the
+// wrapper in check. The target is at most 5% over raw go-redis for GET and
+// SET with the default configuration. BenchmarkGet and BenchmarkSet in the
+// root package measure the same paths against miniredis, e.g. for allocations.
+//
+//	go run ./cmd/redisbench -addr localhost:6379 -n 100000 -c 16
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/acsl-go/redis"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+type payload struct {
+	ID    int    `json:"id"`
+	Value string `json:"value"`
+}
+
+func main() {
+	addr := flag.String("addr", "localhost:6379", "server address")
+	n := flag.Int("n", 100000, "operations per benchmark")
+	c := flag.Int("c", 16, "concurrent workers")
+	size := flag.Int("size", 64, "value size in bytes")
+	flag.Parse()
+
+	ctx := context.Background()
+	client, e := redis.NewClient(&redis.Config{Addresses: []string{*addr}, Prefix: "bench", PoolSize: *c})
+	if e != nil {
+		fmt.Fprintln(os.Stderr, e)
+		os.Exit(1)
+	}
+	defer client.Close()
+	raw := goredis.NewClient(&goredis.Options{Addr: *addr, PoolSize: *c})
+	defer raw.Close()
+	thin := client.Thin()
+
+	value := strings.Repeat("x", *size)
+	p := &payload{ID: 1, Value: value}
+
+	type bench struct {
+		name string
+		fn   func(i int) error
+	}
+	benches := []bench{
+		{"raw/set", func(i int) error { return raw.Set(ctx, "bench:raw:"+strconv.Itoa(i%1000), value, time.Minute).Err() }},
+		{"thin/set", func(i int) error { return thin.Set(ctx, "bench:thin:"+strconv.Itoa(i%1000), value, time.Minute) }},
+		{"client/setstr", func(i int) error { return client.SetStr(ctx, "str:"+strconv.Itoa(i%1000), value, 60) }},
+		{"client/set", func(i int) error { return client.Set(ctx, "json:"+strconv.Itoa(i%1000), p, 60) }},
+		{"raw/get", func(i int) error { return raw.Get(ctx, "bench:raw:"+strconv.Itoa(i%1000)).Err() }},
+		{"thin/get", func(i int) error { _, e := thin.Get(ctx, "bench:thin:"+strconv.Itoa(i%1000)); return e }},
+		{"client/getstr", func(i int) error { _, e := client.GetStr(ctx, "str:"+strconv.Itoa(i%1000)); return e }},
+		{"client/get", func(i int) error { var v payload; return client.Get(ctx, "json:"+strconv.Itoa(i%1000), &v) }},
+	}
+
+	results := make(map[string]time.Duration)
+	for _, b := range benches {
+		per, e := run(*n, *c, b.fn)
+		if e != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", b.name, e)
+			os.Exit(1)
+		}
+		results[b.name] = per
+		op := b.name[strings.Index(b.name, "/")+1:]
+		base := results["raw/"+op[:3]]
+		fmt.Printf("%-14s %10v/op  %+6.1f%% vs raw\n", b.name, per, 100*(float64(per)/float64(base)-1))
+	}
+}
+
+// run spreads n calls over c workers and returns the wall time per call.
+func run(n, c int, fn func(i int) error) (time.Duration, error) {
+	var wg sync.WaitGroup
+	var once sync.Once
+	var first error
+	start := time.Now()
+	for w := 0; w < c; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < n; i += c {
+				if e := fn(i); e != nil {
+					once.Do(func() { first = e })
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	return time.Since(start) / time.Duration(n), first
+}
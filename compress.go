@@ -0,0 +1,122 @@
+package redis
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/zstd"
+)
+
+type CompressionConfig struct {
+	Algorithm string `mapstructure:"algorithm"` // gzip, snappy or zstd, empty disables compression
+	Threshold int    `mapstructure:"threshold"` // Only values of at least this many encoded bytes are compressed, defaults to 1024
+}
+
+// Compressed values start with compressMarker and a byte naming the
+// algorithm, anything else is decoded as is. This keeps values written before
+// compression was enabled, or below the threshold, readable.
+const compressMarker = "\x00z"
+
+const (
+	compressGzip   byte = 'g'
+	compressSnappy byte = 's'
+	compressZstd   byte = 'z'
+)
+
+// CompressedCodec compresses the output of another codec. NewClient wraps
+// the codec with it when Config.Compression is set.
+type CompressedCodec struct {
+	Codec     Codec
+	algorithm byte
+	threshold int
+}
+
+func NewCompressedCodec(codec Codec, cfg CompressionConfig) (*CompressedCodec, error) {
+	c := &CompressedCodec{Codec: codec, threshold: cfg.Threshold}
+	if c.threshold <= 0 {
+		c.threshold = 1024
+	}
+	switch cfg.Algorithm {
+	case "gzip":
+		c.algorithm = compressGzip
+	case "snappy":
+		c.algorithm = compressSnappy
+	case "zstd":
+		c.algorithm = compressZstd
+	default:
+		return nil, fmt.Errorf("redis: unknown compression algorithm %q", cfg.Algorithm)
+	}
+	return c, nil
+}
+
+var (
+	zstdOnce    sync.Once
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+)
+
+func zstdCodec() (*zstd.Encoder, *zstd.Decoder) {
+	zstdOnce.Do(func() {
+		zstdEncoder, _ = zstd.NewWriter(nil)
+		zstdDecoder, _ = zstd.NewReader(nil)
+	})
+	return zstdEncoder, zstdDecoder
+}
+
+func (c *CompressedCodec) Marshal(v interface{}) ([]byte, error) {
+	data, e := c.Codec.Marshal(v)
+	if e != nil || len(data) < c.threshold {
+		return data, e
+	}
+
+	out := append([]byte(compressMarker), c.algorithm)
+	switch c.algorithm {
+	case compressGzip:
+		buf := bytes.NewBuffer(out)
+		w := gzip.NewWriter(buf)
+		if _, e := w.Write(data); e != nil {
+			return nil, e
+		}
+		if e := w.Close(); e != nil {
+			return nil, e
+		}
+		return buf.Bytes(), nil
+	case compressSnappy:
+		return append(out, s2.EncodeSnappy(nil, data)...), nil
+	default:
+		enc, _ := zstdCodec()
+		return enc.EncodeAll(data, out), nil
+	}
+}
+
+func (c *CompressedCodec) Unmarshal(data []byte, v interface{}) error {
+	if !bytes.HasPrefix(data, []byte(compressMarker)) || len(data) <= len(compressMarker) {
+		return c.Codec.Unmarshal(data, v)
+	}
+	algorithm, body := data[len(compressMarker)], data[len(compressMarker)+1:]
+
+	var plain []byte
+	var e error
+	switch algorithm {
+	case compressGzip:
+		var r *gzip.Reader
+		if r, e = gzip.NewReader(bytes.NewReader(body)); e == nil {
+			plain, e = io.ReadAll(r)
+		}
+	case compressSnappy:
+		plain, e = s2.Decode(nil, body)
+	case compressZstd:
+		_, dec := zstdCodec()
+		plain, e = dec.DecodeAll(body, nil)
+	default:
+		e = fmt.Errorf("redis: unknown compression %q", algorithm)
+	}
+	if e != nil {
+		return e
+	}
+	return c.Codec.Unmarshal(plain, v)
+}
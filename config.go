@@ -1,8 +1,105 @@
 package redis
 
+import "time"
+
 type Config struct {
+	URL string `mapstructure:"url"` // redis:// or rediss:// DSN, overrides address, credentials and database
+
 	Addresses []string `mapstructure:"addresses"`
+	Username  string   `mapstructure:"username"` // Redis 6 ACL user, empty authenticates as default
 	Password  string   `mapstructure:"password"`
 	DB        int      `mapstructure:"database"`
 	Prefix    string   `mapstructure:"prefix"`
+
+	ClientName string `mapstructure:"client_name"` // Sent with CLIENT SETNAME on every connection
+	Region     string `mapstructure:"region"`      // Origin recorded by SetTagged in active-active setups, required by the CRDTs
+
+	// Sentinel, Addresses are the sentinel nodes when MasterName is set
+	MasterName              string `mapstructure:"master_name"`
+	SentinelUsername        string `mapstructure:"sentinel_username"` // AUTH user for the sentinel nodes, Username is used for the data nodes
+	SentinelPassword        string `mapstructure:"sentinel_password"`
+	ReplicaOnly             bool   `mapstructure:"replica_only"`
+	UseDisconnectedReplicas bool   `mapstructure:"use_disconnected_replicas"`
+
+	// Cluster, enabled automatically with more than one address
+	Cluster      bool `mapstructure:"cluster"`
+	MaxRedirects int  `mapstructure:"max_redirects"`
+	ReadOnly     bool `mapstructure:"read_only"` // Allow reads from replica nodes
+
+	// Read replicas of a standalone primary, the read only wrappers use them
+	// unless called with WithConsistentRead. Cluster and sentinel setups route
+	// reads with ReadOnly and the flags below instead.
+	Replicas []string `mapstructure:"replicas"`
+
+	// Read routing to replicas, for cluster and sentinel
+	RouteByLatency bool `mapstructure:"route_by_latency"`
+	RouteRandomly  bool `mapstructure:"route_randomly"`
+
+	TLS TLSConfig `mapstructure:"tls"`
+
+	// NewClient does not fail when the server is unreachable, it keeps retrying
+	// in the background, see Client.IsReady and Client.WaitReady
+	LazyConnect          bool          `mapstructure:"lazy_connect"`
+	ConnectRetryInterval time.Duration `mapstructure:"connect_retry_interval"`
+
+	Retry   RetryConfig   `mapstructure:"retry"`
+	Breaker BreakerConfig `mapstructure:"breaker"`
+
+	DrainTimeout time.Duration `mapstructure:"drain_timeout"` // How long Close waits for in-flight commands
+
+	// Deadline of commands whose context has none, CommandTimeouts overrides it
+	// by lower case command name. Blocking commands are exempt. Per call see
+	// WithOperationTimeout.
+	OperationTimeout time.Duration            `mapstructure:"operation_timeout"`
+	CommandTimeouts  map[string]time.Duration `mapstructure:"command_timeouts"`
+
+	// Connection pool, zero values keep the go-redis defaults
+	PoolSize        int           `mapstructure:"pool_size"`
+	MinIdleConns    int           `mapstructure:"min_idle_conns"`
+	MaxIdleConns    int           `mapstructure:"max_idle_conns"`
+	MaxActiveConns  int           `mapstructure:"max_active_conns"`
+	ConnMaxIdleTime time.Duration `mapstructure:"conn_max_idle_time"`
+	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`
+	PoolTimeout     time.Duration `mapstructure:"pool_timeout"`
+	DialTimeout     time.Duration `mapstructure:"dial_timeout"`
+	ReadTimeout     time.Duration `mapstructure:"read_timeout"`
+	WriteTimeout    time.Duration `mapstructure:"write_timeout"`
+
+	LocalCache LocalCacheConfig `mapstructure:"local_cache"`
+
+	// Queue payloads larger than OffloadThreshold bytes are stored as chunked blobs
+	OffloadThreshold int `mapstructure:"offload_threshold"`
+	BlobChunkSize    int `mapstructure:"blob_chunk_size"`
+
+	Functions []string `mapstructure:"functions"` // Function library sources loaded once connected, e.g. from go:embed
+
+	NegativeTTL int `mapstructure:"negative_ttl"` // Seconds GetOrLoad caches a miss, 0 disables negative caching
+
+	TTLJitter float64 `mapstructure:"ttl_jitter"` // Random ±fraction applied to TTLs, see WithTTLJitter
+
+	UpdateRetries int `mapstructure:"update_retries"` // Attempts of Update under contention, defaults to 10
+
+	// Set and SetNX reject encoded values larger than MaxValueSize bytes, or
+	// only log them with MaxValueSizeWarn. 0 disables the check.
+	MaxValueSize     int  `mapstructure:"max_value_size"`
+	MaxValueSizeWarn bool `mapstructure:"max_value_size_warn"`
+
+	Compression CompressionConfig `mapstructure:"compression"` // Applied to the values encoded by the codec
+
+	RedisJSON bool `mapstructure:"redis_json"` // Codec values are stored as documents when the RedisJSON module is loaded
+
+	InvalidateResultCache bool `mapstructure:"invalidate_result_cache"` // Drop Cached* results when their source keys are written
+
+	TouchTracking TouchTrackingConfig `mapstructure:"touch_tracking"` // Sampled access tracking for TouchReport
+
+	KeyProvider KeyProvider `mapstructure:"-"` // Replaces keys after the prefix by their HMAC
+
+	RedactKeys  bool                    `mapstructure:"redact_keys"`
+	KeyRedactor func(key string) string `mapstructure:"-"`
+
+	Tracing bool `mapstructure:"tracing"` // OpenTelemetry spans through the global tracer provider
+
+	Logger  Logger  `mapstructure:"-"` // Defaults to slog.Default()
+	Metrics Metrics `mapstructure:"-"`
+	Hooks   []Hook  `mapstructure:"-"`
 }
@@ -0,0 +1,315 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+type ConsumerConfig struct {
+	Stream      string
+	Group       string
+	Name        string        // Consumer name, must be unique within the group
+	Concurrency int           // Messages handled in parallel, defaults to 1
+	Count       int64         // Messages fetched per XREADGROUP, defaults to Concurrency
+	Block       time.Duration // Server side block of XREADGROUP, defaults to two seconds
+	PresenceTTL time.Duration // Consumers without heartbeat for this long count as gone, defaults to 30 seconds
+
+	// Passes over the messages left pending by this consumer name before new
+	// messages are read, defaults to 3. Messages still failing then stay
+	// pending, e.g. for Drain to hand over.
+	PendingPasses int
+
+	// Envelopes opens the payloads written with ProduceEnvelope and validates
+	// them against the schema registry before the handler runs. Messages that
+	// fail stay pending like failed ones.
+	Envelopes bool
+}
+
+type StreamMessage struct {
+	ID       string
+	Payload  []byte // The payload of Envelope with ConsumerConfig.Envelopes
+	Envelope *Envelope
+	Values   map[string]interface{}
+}
+
+// Consumer processes a stream through a consumer group. Messages are acked
+// when the handler returns nil, failed ones stay pending in the group.
+// Running consumers announce themselves in the <prefix>:consumers:<stream>:<group>
+// presence zset so that Drain can hand pending work to a live peer.
+type Consumer struct {
+	worker
+	client  *Client
+	cfg     ConsumerConfig
+	handler func(ctx context.Context, msg *StreamMessage) error
+
+	started  atomic.Bool
+	stopping atomic.Bool
+	cancel   context.CancelFunc
+	mu       sync.Mutex
+	inflight sync.WaitGroup
+	done     chan struct{}
+}
+
+// Produce appends payload to stream, offloading it to a blob when it exceeds Config.OffloadThreshold.
+func (client *Client) Produce(ctx context.Context, stream string, payload []byte) (string, error) {
+	data, e := client.OffloadPayload(ctx, payload, 0)
+	if e != nil {
+		return "", e
+	}
+	id, e := client.client.XAdd(ctx, &goredis.XAddArgs{
+		Stream: client.fullKey(stream),
+		Values: []interface{}{"payload", data},
+	}).Result()
+	if e != nil {
+		return "", client.wrap(e, "RedisProduce", "xadd", stream)
+	}
+	return id, nil
+}
+
+// NewConsumer returns a consumer that is not reading yet. Either call Run or
+// Start, which runs it under ctx.
+func (client *Client) NewConsumer(ctx context.Context, cfg ConsumerConfig, handler func(ctx context.Context, msg *StreamMessage) error) *Consumer {
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+	if cfg.Count <= 0 {
+		cfg.Count = int64(cfg.Concurrency)
+	}
+	if cfg.Block <= 0 {
+		cfg.Block = 2 * time.Second
+	}
+	if cfg.PresenceTTL <= 0 {
+		cfg.PresenceTTL = 30 * time.Second
+	}
+	if cfg.PendingPasses <= 0 {
+		cfg.PendingPasses = 3
+	}
+	c := &Consumer{
+		client:  client,
+		cfg:     cfg,
+		handler: handler,
+		done:    make(chan struct{}),
+	}
+	c.init(client, "RedisConsumer", ctx, func(ctx context.Context, _ func(error)) error {
+		return c.Run(ctx)
+	})
+	return c
+}
+
+func (c *Consumer) streamKey() string {
+	return c.client.fullKey(c.cfg.Stream)
+}
+
+func (c *Consumer) presenceKey() string {
+	return c.client.fullKey("consumers:" + c.cfg.Stream + ":" + c.cfg.Group)
+}
+
+// Run fetches and handles messages until ctx is done or Drain is called. It
+// first works off the messages still pending for this consumer name, see
+// ConsumerConfig.PendingPasses. Run can only be called once.
+func (c *Consumer) Run(ctx context.Context) error {
+	if c.cfg.Name == "" {
+		return ErrConsumerName
+	}
+	if !c.started.CompareAndSwap(false, true) {
+		return ErrServiceStarted
+	}
+	defer close(c.done)
+
+	fetchCtx, cancel := context.WithCancel(ctx)
+	c.mu.Lock()
+	c.cancel = cancel
+	c.mu.Unlock()
+	defer cancel()
+
+	e := c.client.client.XGroupCreateMkStream(ctx, c.streamKey(), c.cfg.Group, "0").Err()
+	if e != nil && !strings.HasPrefix(e.Error(), "BUSYGROUP") {
+		return c.client.wrap(e, "RedisConsumer", "xgroup", c.cfg.Stream)
+	}
+
+	sem := make(chan struct{}, c.cfg.Concurrency)
+	// start walks the own backlog from "0" past the IDs already read, one pass
+	// at a time, and is ">" for new messages afterwards
+	start, passes, read := "0", 1, 0
+	lastBeat := time.Time{}
+	for !c.stopping.Load() {
+		if time.Since(lastBeat) > c.cfg.PresenceTTL/3 {
+			c.heartbeat(fetchCtx)
+			lastBeat = time.Now()
+		}
+
+		streams, e := c.client.client.XReadGroup(fetchCtx, &goredis.XReadGroupArgs{
+			Group:    c.cfg.Group,
+			Consumer: c.cfg.Name,
+			Streams:  []string{c.streamKey(), start},
+			Count:    c.cfg.Count,
+			Block:    c.cfg.Block,
+		}).Result()
+		if e != nil {
+			if fetchCtx.Err() != nil {
+				break
+			}
+			if e != goredis.Nil {
+				c.client.log().Warn("RedisConsumer:Read", "stream", c.cfg.Stream, "error", e)
+				time.Sleep(time.Second)
+			}
+			continue
+		}
+
+		var msgs []goredis.XMessage
+		if len(streams) > 0 {
+			msgs = streams[0].Messages
+		}
+		if start != ">" {
+			if len(msgs) == 0 {
+				// End of a pass, let it finish so that no message is read
+				// again while it is still being handled
+				c.inflight.Wait()
+				if read == 0 || passes >= c.cfg.PendingPasses {
+					start = ">"
+				} else {
+					start, passes, read = "0", passes+1, 0
+				}
+				continue
+			}
+			start = msgs[len(msgs)-1].ID
+			read += len(msgs)
+		}
+		for _, msg := range msgs {
+			sem <- struct{}{}
+			c.inflight.Add(1)
+			go func(msg goredis.XMessage) {
+				defer func() { <-sem; c.inflight.Done() }()
+				c.handle(ctx, msg)
+			}(msg)
+		}
+	}
+	c.inflight.Wait()
+	return nil
+}
+
+func (c *Consumer) heartbeat(ctx context.Context) {
+	e := c.client.client.ZAdd(ctx, c.presenceKey(), goredis.Z{
+		Score:  float64(time.Now().UnixMilli()),
+		Member: c.cfg.Name,
+	}).Err()
+	if e != nil {
+		c.client.log().Warn("RedisConsumer:Heartbeat", "stream", c.cfg.Stream, "error", e)
+	}
+}
+
+func (c *Consumer) handle(ctx context.Context, xmsg goredis.XMessage) {
+	msg := &StreamMessage{ID: xmsg.ID, Values: xmsg.Values}
+	raw, _ := xmsg.Values["payload"].(string)
+	payload, e := c.client.ResolvePayload(ctx, []byte(raw))
+	if e != nil {
+		c.client.log().Warn("RedisConsumer:Resolve", "stream", c.cfg.Stream, "id", xmsg.ID, "error", e)
+		return
+	}
+	msg.Payload = payload
+	if c.cfg.Envelopes {
+		if msg.Envelope, e = c.client.OpenEnvelope(ctx, payload, nil); e != nil {
+			c.client.log().Warn("RedisConsumer:Envelope", "stream", c.cfg.Stream, "id", xmsg.ID, "error", e)
+			return
+		}
+		msg.Payload = msg.Envelope.Payload
+	}
+
+	if e := c.callHandler(ctx, msg); e != nil {
+		c.client.log().Warn("RedisConsumer:Handle", "stream", c.cfg.Stream, "id", xmsg.ID, "error", e)
+		return
+	}
+	if e := c.client.client.XAck(ctx, c.streamKey(), c.cfg.Group, xmsg.ID).Err(); e != nil {
+		c.client.log().Warn("RedisConsumer:Ack", "stream", c.cfg.Stream, "id", xmsg.ID, "error", e)
+		return
+	}
+	if e := c.client.ReleasePayload(ctx, []byte(raw)); e != nil {
+		c.client.log().Warn("RedisConsumer:Release", "stream", c.cfg.Stream, "id", xmsg.ID, "error", e)
+	}
+}
+
+// A panicking handler leaves the message pending like a failed one.
+func (c *Consumer) callHandler(ctx context.Context, msg *StreamMessage) (e error) {
+	defer c.client.recoverPanic("consumer", &e)
+	return c.handler(ctx, msg)
+}
+
+// Drain stops fetching, waits for the in-flight messages, leaves the presence
+// set and hands the messages still pending for this consumer to a live peer.
+// Without a live peer they stay pending until this name runs again.
+func (c *Consumer) Drain(ctx context.Context) error {
+	c.stopping.Store(true)
+	c.mu.Lock()
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.mu.Unlock()
+
+	if c.started.Load() {
+		select {
+		case <-c.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if e := c.client.client.ZRem(ctx, c.presenceKey(), c.cfg.Name).Err(); e != nil {
+		return c.client.wrap(e, "RedisConsumerDrain", "zrem", c.cfg.Stream)
+	}
+	return c.handBack(ctx)
+}
+
+func (c *Consumer) handBack(ctx context.Context) error {
+	alive := strconv.FormatInt(time.Now().Add(-c.cfg.PresenceTTL).UnixMilli(), 10)
+	peers, e := c.client.client.ZRangeByScore(ctx, c.presenceKey(), &goredis.ZRangeBy{Min: alive, Max: "+inf"}).Result()
+	if e != nil {
+		return c.client.wrap(e, "RedisConsumerDrain", "zrangebyscore", c.cfg.Stream)
+	}
+	if len(peers) == 0 {
+		return nil
+	}
+
+	for i := 0; ; i++ {
+		pending, e := c.client.client.XPendingExt(ctx, &goredis.XPendingExtArgs{
+			Stream:   c.streamKey(),
+			Group:    c.cfg.Group,
+			Start:    "-",
+			End:      "+",
+			Count:    100,
+			Consumer: c.cfg.Name,
+		}).Result()
+		if e != nil {
+			return c.client.wrap(e, "RedisConsumerDrain", "xpending", c.cfg.Stream)
+		}
+		if len(pending) == 0 {
+			break
+		}
+		ids := make([]string, len(pending))
+		for j, p := range pending {
+			ids[j] = p.ID
+		}
+		// Spread the batches over the peers
+		if e := c.client.client.XClaimJustID(ctx, &goredis.XClaimArgs{
+			Stream:   c.streamKey(),
+			Group:    c.cfg.Group,
+			Consumer: peers[i%len(peers)],
+			Messages: ids,
+		}).Err(); e != nil {
+			return c.client.wrap(e, "RedisConsumerDrain", "xclaim", c.cfg.Stream)
+		}
+	}
+
+	if e := c.client.client.XGroupDelConsumer(ctx, c.streamKey(), c.cfg.Group, c.cfg.Name).Err(); e != nil {
+		return c.client.wrap(e, "RedisConsumerDrain", "xgroup", c.cfg.Stream)
+	}
+	return nil
+}
+
+var ErrConsumerName = errors.New("redis: consumer name required")
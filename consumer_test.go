@@ -0,0 +1,148 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/acsl-go/redis"
+	"github.com/acsl-go/redis/redistest"
+)
+
+// consumerRun records the payloads a consumer handled, in order.
+type consumerRun struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *consumerRun) add(payload string) {
+	r.mu.Lock()
+	r.calls = append(r.calls, payload)
+	r.mu.Unlock()
+}
+
+func (r *consumerRun) snapshot() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.calls...)
+}
+
+// waitCalls waits until n calls were recorded.
+func (r *consumerRun) waitCalls(t *testing.T, n int) []string {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if calls := r.snapshot(); len(calls) >= n {
+			return calls
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("handler calls %v, want %d", r.snapshot(), n)
+	return nil
+}
+
+// startConsumer runs a consumer until the returned stop is called.
+func startConsumer(t *testing.T, client *redis.Client, cfg redis.ConsumerConfig, fail func(payload string, calls int) bool) (*consumerRun, func()) {
+	t.Helper()
+	run := &consumerRun{}
+	seen := map[string]int{}
+	var mu sync.Mutex
+	ctx, cancel := context.WithCancel(context.Background())
+	c := client.NewConsumer(ctx, cfg, func(ctx context.Context, msg *redis.StreamMessage) error {
+		payload := string(msg.Payload)
+		mu.Lock()
+		seen[payload]++
+		n := seen[payload]
+		mu.Unlock()
+		run.add(payload)
+		if fail(payload, n) {
+			return errors.New("failed")
+		}
+		return nil
+	})
+	done := make(chan error, 1)
+	go func() { done <- c.Run(ctx) }()
+	return run, func() {
+		cancel()
+		if e := <-done; e != nil {
+			t.Errorf("Run = %v", e)
+		}
+	}
+}
+
+var testConsumer = redis.ConsumerConfig{Stream: "jobs", Group: "workers", Name: "w1", Block: 20 * time.Millisecond}
+
+func produce(t *testing.T, client *redis.Client, payloads ...string) {
+	t.Helper()
+	for _, p := range payloads {
+		if _, e := client.Produce(context.Background(), "jobs", []byte(p)); e != nil {
+			t.Fatal(e)
+		}
+	}
+}
+
+func TestConsumerRetriesPendingFirst(t *testing.T) {
+	client, _ := redistest.NewTestClient(t, nil)
+	produce(t, client, "m1", "m2")
+
+	// A failing run leaves both pending for the consumer name
+	run, stop := startConsumer(t, client, testConsumer, func(string, int) bool { return true })
+	run.waitCalls(t, 2)
+	stop()
+
+	// m3 is new, it waits until the backlog passes are done
+	produce(t, client, "m3")
+	run, stop = startConsumer(t, client, testConsumer, func(payload string, n int) bool {
+		return payload == "m1" && n == 1
+	})
+	defer stop()
+	calls := run.waitCalls(t, 4)
+
+	want := []string{"m1", "m2", "m1", "m3"}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Fatalf("handler calls %v, want %v", calls, want)
+		}
+	}
+}
+
+func TestConsumerPendingDoesNotStarve(t *testing.T) {
+	client, _ := redistest.NewTestClient(t, nil)
+	produce(t, client, "poison")
+
+	run, stop := startConsumer(t, client, testConsumer, func(string, int) bool { return true })
+	run.waitCalls(t, 1)
+	stop()
+
+	cfg := testConsumer
+	cfg.PendingPasses = 2
+	produce(t, client, "fresh")
+	run, stop = startConsumer(t, client, cfg, func(payload string, _ int) bool { return payload == "poison" })
+	defer stop()
+	calls := run.waitCalls(t, 3)
+
+	// Two passes over the backlog, then the new message
+	want := []string{"poison", "poison", "fresh"}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Fatalf("handler calls %v, want %v", calls, want)
+		}
+	}
+}
+
+func TestConsumerRunOnce(t *testing.T) {
+	client, _ := redistest.NewTestClient(t, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := client.NewConsumer(ctx, testConsumer, func(context.Context, *redis.StreamMessage) error { return nil })
+	done := make(chan error, 1)
+	go func() { done <- c.Run(ctx) }()
+	time.Sleep(20 * time.Millisecond)
+	if e := c.Run(ctx); e != redis.ErrServiceStarted {
+		t.Errorf("second Run = %v, want ErrServiceStarted", e)
+	}
+	cancel()
+	<-done
+}
@@ -0,0 +1,214 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+type CounterWindow int
+
+const (
+	WindowMinute CounterWindow = iota
+	WindowHour
+	WindowDay
+)
+
+func (w CounterWindow) size() time.Duration {
+	switch w {
+	case WindowMinute:
+		return time.Minute
+	case WindowHour:
+		return time.Hour
+	}
+	return 24 * time.Hour
+}
+
+func (w CounterWindow) suffix(t time.Time) string {
+	switch w {
+	case WindowMinute:
+		return "m:" + t.Format("200601021504")
+	case WindowHour:
+		return "h:" + t.Format("2006010215")
+	}
+	return "d:" + t.Format("20060102")
+}
+
+type CounterConfig struct {
+	Windows       []CounterWindow // Windows every increment is counted in, defaults to all of them
+	FlushInterval time.Duration   // Increments are buffered locally this long, defaults to one second
+
+	// How long buckets are kept, default to 2, 35 and 400 days
+	MinuteRetention time.Duration
+	HourRetention   time.Duration
+	DayRetention    time.Duration
+}
+
+type CounterBucket struct {
+	Start time.Time
+	Count int64
+}
+
+// Counter counts events per id in time buckets. Increments are summed in
+// memory and flushed with one INCRBY per bucket, so a busy id costs a round
+// trip per flush instead of one per event. Buckets live under
+// <prefix>:cnt:{<name>:<id>}:<window>:<time>, the hash tag keeps the buckets of
+// an id on one node so ranges are read with a single MGET. Times are UTC.
+type Counter struct {
+	worker
+	client *Client
+	name   string
+	cfg    CounterConfig
+
+	mu      sync.Mutex
+	pending map[string]int64 // Full bucket key to delta
+	expiry  map[string]time.Duration
+}
+
+// NewCounter returns a counter that buffers until Flush is called, Start
+// flushes it every FlushInterval under ctx and once more when it stops.
+func (client *Client) NewCounter(ctx context.Context, name string, cfg CounterConfig) *Counter {
+	if len(cfg.Windows) == 0 {
+		cfg.Windows = []CounterWindow{WindowMinute, WindowHour, WindowDay}
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = time.Second
+	}
+	if cfg.MinuteRetention <= 0 {
+		cfg.MinuteRetention = 2 * 24 * time.Hour
+	}
+	if cfg.HourRetention <= 0 {
+		cfg.HourRetention = 35 * 24 * time.Hour
+	}
+	if cfg.DayRetention <= 0 {
+		cfg.DayRetention = 400 * 24 * time.Hour
+	}
+	c := &Counter{
+		client:  client,
+		name:    name,
+		cfg:     cfg,
+		pending: make(map[string]int64),
+		expiry:  make(map[string]time.Duration),
+	}
+	c.init(client, "RedisCounter:Flush", ctx, c.loop)
+	return c
+}
+
+func (c *Counter) retention(w CounterWindow) time.Duration {
+	switch w {
+	case WindowMinute:
+		return c.cfg.MinuteRetention
+	case WindowHour:
+		return c.cfg.HourRetention
+	}
+	return c.cfg.DayRetention
+}
+
+func (c *Counter) keyName(id string, w CounterWindow, t time.Time) string {
+	return "cnt:{" + c.name + ":" + id + "}:" + w.suffix(t.UTC())
+}
+
+// Incr adds n to the current bucket of every window, it does not block on
+// Redis.
+func (c *Counter) Incr(id string, n int64) {
+	now := time.Now()
+	c.mu.Lock()
+	for _, w := range c.cfg.Windows {
+		key_str := c.client.fullKey(c.keyName(id, w, now))
+		c.pending[key_str] += n
+		c.expiry[key_str] = c.retention(w)
+	}
+	c.mu.Unlock()
+}
+
+// Flush writes the buffered increments in one pipeline. Increments that could
+// not be written are buffered again for the next flush.
+func (c *Counter) Flush(ctx context.Context) error {
+	c.mu.Lock()
+	pending, expiry := c.pending, c.expiry
+	c.pending, c.expiry = make(map[string]int64), make(map[string]time.Duration)
+	c.mu.Unlock()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	pipe := c.client.client.Pipeline()
+	incrs := make(map[string]*goredis.IntCmd, len(pending))
+	for key_str, n := range pending {
+		incrs[key_str] = pipe.IncrBy(ctx, key_str, n)
+		pipe.Expire(ctx, key_str, expiry[key_str])
+	}
+	if _, e := pipe.Exec(ctx); e != nil {
+		// Only the increments that failed are buffered again, the others
+		// were counted already
+		c.mu.Lock()
+		for key_str, cmd := range incrs {
+			if cmd.Err() != nil {
+				c.pending[key_str] += pending[key_str]
+				c.expiry[key_str] = expiry[key_str]
+			}
+		}
+		c.mu.Unlock()
+		return c.client.wrap(e, "RedisCounterFlush", "incrby", "cnt:"+c.name)
+	}
+	return nil
+}
+
+func (c *Counter) loop(ctx context.Context, report func(error)) error {
+	ticker := time.NewTicker(c.cfg.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			flush_ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+			defer cancel()
+			return c.Flush(flush_ctx)
+		case <-ticker.C:
+			if e := c.Flush(ctx); e != nil && ctx.Err() == nil {
+				report(e)
+			}
+		}
+	}
+}
+
+// Range returns the flushed counts of the buckets of a window between from and
+// to, both included, with zero counts for empty buckets.
+func (c *Counter) Range(ctx context.Context, id string, w CounterWindow, from, to time.Time) ([]CounterBucket, error) {
+	from, to = from.UTC().Truncate(w.size()), to.UTC()
+	var buckets []CounterBucket
+	var keys []string
+	for t := from; !t.After(to); t = t.Add(w.size()) {
+		buckets = append(buckets, CounterBucket{Start: t})
+		keys = append(keys, c.client.fullKey(c.keyName(id, w, t)))
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	values, e := c.client.client.MGet(ctx, keys...).Result()
+	if e != nil && e != goredis.Nil {
+		return nil, c.client.wrap(e, "RedisCounterRange", "mget", c.keyName(id, w, from))
+	}
+	for i, v := range values {
+		if s, ok := v.(string); ok {
+			buckets[i].Count, _ = strconv.ParseInt(s, 10, 64)
+		}
+	}
+	return buckets, nil
+}
+
+// Sum adds up the buckets Range returns.
+func (c *Counter) Sum(ctx context.Context, id string, w CounterWindow, from, to time.Time) (int64, error) {
+	buckets, e := c.Range(ctx, id, w, from, to)
+	if e != nil {
+		return 0, e
+	}
+	var total int64
+	for _, b := range buckets {
+		total += b.Count
+	}
+	return total, nil
+}
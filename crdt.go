@@ -0,0 +1,167 @@
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrNoRegion is returned by the CRDT constructors without Config.Region, the
+// fields of every region would collide otherwise.
+var ErrNoRegion = errors.New("redis: Config.Region is required")
+
+// PNCounter is a counter for active-active deployments. Every region only
+// writes its own fields of the <prefix>:pn:<key> hash (<region>:p and
+// <region>:n), so replication never has to merge concurrent writes of one
+// field and no increment is lost. The value is merged on read.
+type PNCounter struct {
+	client *Client
+	key    string
+}
+
+func (client *Client) PNCounter(key string) (*PNCounter, error) {
+	if client.config.Region == "" {
+		return nil, ErrNoRegion
+	}
+	return &PNCounter{client: client, key: key}, nil
+}
+
+func (c *PNCounter) keyStr() string {
+	return c.client.fullKey("pn:" + c.key)
+}
+
+func (c *PNCounter) Add(ctx context.Context, delta int64) error {
+	field := c.client.config.Region + ":p"
+	if delta < 0 {
+		field, delta = c.client.config.Region+":n", -delta
+	}
+	if e := c.client.client.HIncrBy(ctx, c.keyStr(), field, delta).Err(); e != nil {
+		return c.client.wrap(e, "RedisPNCounterAdd", "hincrby", c.key)
+	}
+	return nil
+}
+
+func (c *PNCounter) Value(ctx context.Context) (int64, error) {
+	fields, e := c.client.client.HGetAll(ctx, c.keyStr()).Result()
+	if e != nil {
+		return 0, c.client.wrap(e, "RedisPNCounterValue", "hgetall", c.key)
+	}
+	var total int64
+	for field, v := range fields {
+		n, _ := strconv.ParseInt(v, 10, 64)
+		if strings.HasSuffix(field, ":n") {
+			total -= n
+		} else {
+			total += n
+		}
+	}
+	return total, nil
+}
+
+// ORSet is an observed-remove set with add-wins semantics: every add gets a
+// unique tag, a remove only tombstones the tags it has seen, so an add that
+// happened concurrently in another region survives the remove.
+type ORSet struct {
+	client *Client
+	key    string
+}
+
+func (client *Client) ORSet(key string) (*ORSet, error) {
+	if client.config.Region == "" {
+		return nil, ErrNoRegion
+	}
+	return &ORSet{client: client, key: key}, nil
+}
+
+// Both hashes share a hash tag so they live in the same cluster slot
+func (s *ORSet) keys() (string, string) {
+	base := s.client.fullKey("orset:{" + s.key + "}")
+	return base + ":a", base + ":r"
+}
+
+func (s *ORSet) tag() string {
+	raw := make([]byte, 8)
+	rand.Read(raw)
+	return s.client.config.Region + "-" + hex.EncodeToString(raw)
+}
+
+func (s *ORSet) Add(ctx context.Context, members ...string) error {
+	adds, _ := s.keys()
+	values := make([]interface{}, 0, 2*len(members))
+	for _, m := range members {
+		values = append(values, m+"\x00"+s.tag(), 1)
+	}
+	if e := s.client.client.HSet(ctx, adds, values...).Err(); e != nil {
+		return s.client.wrap(e, "RedisORSetAdd", "hset", s.key)
+	}
+	return nil
+}
+
+func (s *ORSet) Remove(ctx context.Context, members ...string) error {
+	adds, removes := s.keys()
+	fields, e := s.client.client.HKeys(ctx, adds).Result()
+	if e != nil {
+		return s.client.wrap(e, "RedisORSetRemove", "hkeys", s.key)
+	}
+
+	drop := make(map[string]bool, len(members))
+	for _, m := range members {
+		drop[m] = true
+	}
+	var values []interface{}
+	for _, f := range fields {
+		if m, _, ok := strings.Cut(f, "\x00"); ok && drop[m] {
+			values = append(values, f, 1)
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	if e := s.client.client.HSet(ctx, removes, values...).Err(); e != nil {
+		return s.client.wrap(e, "RedisORSetRemove", "hset", s.key)
+	}
+	return nil
+}
+
+func (s *ORSet) Members(ctx context.Context) ([]string, error) {
+	adds, removes := s.keys()
+	pipe := s.client.client.Pipeline()
+	added := pipe.HKeys(ctx, adds)
+	removed := pipe.HKeys(ctx, removes)
+	if _, e := pipe.Exec(ctx); e != nil {
+		return nil, s.client.wrap(e, "RedisORSetMembers", "hkeys", s.key)
+	}
+
+	tombstones := make(map[string]bool, len(removed.Val()))
+	for _, f := range removed.Val() {
+		tombstones[f] = true
+	}
+	seen := make(map[string]bool)
+	var members []string
+	for _, f := range added.Val() {
+		m, _, ok := strings.Cut(f, "\x00")
+		if !ok || tombstones[f] || seen[m] {
+			continue
+		}
+		seen[m] = true
+		members = append(members, m)
+	}
+	return members, nil
+}
+
+func (s *ORSet) Contains(ctx context.Context, member string) (bool, error) {
+	members, e := s.Members(ctx)
+	if e != nil {
+		return false, e
+	}
+	for _, m := range members {
+		if m == member {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,166 @@
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/pkg/errors"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Delayed messages wait in the zset <prefix>:delay:{<topic>} scored by the
+// time they become ready, members are a 32 character id followed by the
+// payload. A DelayQueue moves ready messages to the stream <prefix>:<topic>
+// with the same layout as Produce, so a Consumer of the topic processes them.
+
+const delayIDLen = 32
+
+func delayKeys(topic string) []string {
+	key := "delay:{" + topic + "}"
+	return []string{key, key + ":moving"}
+}
+
+// Enqueue stores payload until delay passed and returns the message id.
+// Payloads above Config.OffloadThreshold are offloaded to a blob that lives one
+// day longer than the delay.
+func (client *Client) Enqueue(ctx context.Context, topic string, payload []byte, delay time.Duration) (string, error) {
+	raw := make([]byte, delayIDLen/2)
+	if _, e := rand.Read(raw); e != nil {
+		return "", errors.Wrap(e, "RedisEnqueue")
+	}
+	id := hex.EncodeToString(raw)
+	data, e := client.OffloadPayload(ctx, payload, int((delay+24*time.Hour)/time.Second))
+	if e != nil {
+		return "", e
+	}
+
+	ready := time.Now().Add(delay).UnixMilli()
+	member := id + string(data)
+	if e := client.client.ZAdd(ctx, client.fullKey(delayKeys(topic)[0]), goredis.Z{Score: float64(ready), Member: member}).Err(); e != nil {
+		return "", client.wrap(e, "RedisEnqueue", "zadd", delayKeys(topic)[0])
+	}
+	return id, nil
+}
+
+// DelayedCount returns the number of messages of topic that are not moved yet.
+func (client *Client) DelayedCount(ctx context.Context, topic string) (int64, error) {
+	n, e := client.client.ZCard(ctx, client.fullKey(delayKeys(topic)[0])).Result()
+	if e != nil {
+		return 0, client.wrap(e, "RedisDelayedCount", "zcard", delayKeys(topic)[0])
+	}
+	return n, nil
+}
+
+type DelayQueueConfig struct {
+	Topics       []string
+	PollInterval time.Duration // Defaults to one second
+	Batch        int           // Messages moved per round trip, defaults to 100
+	Visibility   time.Duration // Messages claimed by a mover that died are moved again after this, defaults to 30 seconds
+}
+
+// DelayQueue moves ready messages to their streams. A message is claimed into
+// <prefix>:delay:{<topic>}:moving before XADD and dropped from there after, so
+// several instances can run movers and a crash between the two steps causes a
+// duplicate rather than a lost message.
+type DelayQueue struct {
+	worker
+	client *Client
+	cfg    DelayQueueConfig
+}
+
+// NewDelayQueue returns a mover that does not move yet, Start runs it under ctx.
+func (client *Client) NewDelayQueue(ctx context.Context, cfg DelayQueueConfig) *DelayQueue {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+	if cfg.Batch <= 0 {
+		cfg.Batch = 100
+	}
+	if cfg.Visibility <= 0 {
+		cfg.Visibility = 30 * time.Second
+	}
+	q := &DelayQueue{client: client, cfg: cfg}
+	q.init(client, "RedisDelayQueue:Move", ctx, q.loop)
+	return q
+}
+
+var delayClaimScript = NewScript(`
+local stale = redis.call("ZRANGEBYSCORE", KEYS[2], "-inf", ARGV[1], "LIMIT", 0, ARGV[3])
+for _, m in ipairs(stale) do
+	redis.call("ZREM", KEYS[2], m)
+	redis.call("ZADD", KEYS[1], ARGV[1], m)
+end
+local due = redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[1], "LIMIT", 0, ARGV[3])
+for _, m in ipairs(due) do
+	redis.call("ZREM", KEYS[1], m)
+	redis.call("ZADD", KEYS[2], ARGV[2], m)
+end
+return due
+`)
+
+// Move moves the ready messages of all topics and returns how many it moved.
+func (q *DelayQueue) Move(ctx context.Context) (int, error) {
+	total := 0
+	for _, topic := range q.cfg.Topics {
+		n, e := q.moveTopic(ctx, topic)
+		total += n
+		if e != nil {
+			return total, e
+		}
+	}
+	return total, nil
+}
+
+func (q *DelayQueue) moveTopic(ctx context.Context, topic string) (int, error) {
+	now := time.Now()
+	due, e := delayClaimScript.Run(ctx, q.client, delayKeys(topic),
+		now.UnixMilli(), now.Add(q.cfg.Visibility).UnixMilli(), q.cfg.Batch).StringSlice()
+	if e != nil {
+		return 0, q.client.wrap(e, "RedisDelayQueueMove", "evalsha", delayKeys(topic)[0])
+	}
+	if len(due) == 0 {
+		return 0, nil
+	}
+
+	stream := q.client.fullKey(topic)
+	pipe := q.client.client.Pipeline()
+	for _, m := range due {
+		if len(m) < delayIDLen {
+			continue
+		}
+		pipe.XAdd(ctx, &goredis.XAddArgs{
+			Stream: stream,
+			Values: []interface{}{"payload", m[delayIDLen:], "delay_id", m[:delayIDLen]},
+		})
+	}
+	if _, e := pipe.Exec(ctx); e != nil {
+		// The claim expires and the messages are moved again
+		return 0, q.client.wrap(e, "RedisDelayQueueMove", "xadd", topic)
+	}
+
+	members := make([]interface{}, len(due))
+	for i, m := range due {
+		members[i] = m
+	}
+	if e := q.client.client.ZRem(ctx, q.client.fullKey(delayKeys(topic)[1]), members...).Err(); e != nil {
+		return len(due), q.client.wrap(e, "RedisDelayQueueMove", "zrem", delayKeys(topic)[1])
+	}
+	return len(due), nil
+}
+
+func (q *DelayQueue) loop(ctx context.Context, report func(error)) error {
+	ticker := time.NewTicker(q.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if _, e := q.Move(ctx); e != nil && ctx.Err() == nil {
+				report(e)
+			}
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package redis
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Dependency edges are stored as sets, <prefix>:deps:<key> holds the keys
+// derived from key. Edges outlive the entries they point at so that a rebuilt
+// entry stays covered without declaring it again.
+
+const DefaultCascadeDepth = 8
+
+var ErrCascadeDepth = errors.New("redis: invalidation cascade too deep")
+
+// DependsOn records that derived is built from each of the sources. A ttl of
+// 0 keeps the edges until RemoveDependency.
+func (client *Client) DependsOn(ctx context.Context, derived string, ttl int, sources ...string) error {
+	pipe := client.client.Pipeline()
+	for _, src := range sources {
+		deps_key := client.fullKey("deps:" + src)
+		pipe.SAdd(ctx, deps_key, derived)
+		if ttl > 0 {
+			pipe.Expire(ctx, deps_key, client.expiration(ttl))
+		}
+	}
+	if _, e := pipe.Exec(ctx); e != nil {
+		return client.wrap(e, "RedisDependsOn", "sadd", derived)
+	}
+	return nil
+}
+
+func (client *Client) RemoveDependency(ctx context.Context, derived string, sources ...string) error {
+	pipe := client.client.Pipeline()
+	for _, src := range sources {
+		pipe.SRem(ctx, client.fullKey("deps:"+src), derived)
+	}
+	if _, e := pipe.Exec(ctx); e != nil {
+		return client.wrap(e, "RedisRemoveDependency", "srem", derived)
+	}
+	return nil
+}
+
+// Dependents returns the keys directly derived from key.
+func (client *Client) Dependents(ctx context.Context, key string) ([]string, error) {
+	keys, e := client.client.SMembers(ctx, client.fullKey("deps:"+key)).Result()
+	if e != nil {
+		return nil, client.wrap(e, "RedisDependents", "smembers", key)
+	}
+	return keys, nil
+}
+
+// InvalidateCascade deletes key and everything derived from it, level by
+// level. Cycles are skipped, and a graph deeper than maxDepth levels (0 means
+// DefaultCascadeDepth) stops with ErrCascadeDepth after deleting what it
+// reached. It returns the deleted keys.
+func (client *Client) InvalidateCascade(ctx context.Context, key string, maxDepth int) ([]string, error) {
+	if maxDepth <= 0 {
+		maxDepth = DefaultCascadeDepth
+	}
+
+	seen := map[string]bool{key: true}
+	level := []string{key}
+	var deleted []string
+	for depth := 0; len(level) > 0; depth++ {
+		// One DEL per key, derived entries live in other cluster slots
+		pipe := client.client.Pipeline()
+		for _, k := range level {
+			pipe.Del(ctx, client.fullKey(k))
+		}
+		if _, e := pipe.Exec(ctx); e != nil {
+			return deleted, client.wrap(e, "RedisInvalidateCascade", "del", key)
+		}
+		for _, k := range level {
+			client.invalidateLocal(ctx, client.fullKey(k))
+		}
+		deleted = append(deleted, level...)
+
+		pipe = client.client.Pipeline()
+		cmds := make([]*goredis.StringSliceCmd, len(level))
+		for i, k := range level {
+			cmds[i] = pipe.SMembers(ctx, client.fullKey("deps:"+k))
+		}
+		if _, e := pipe.Exec(ctx); e != nil {
+			return deleted, client.wrap(e, "RedisInvalidateCascade", "smembers", key)
+		}
+
+		var next []string
+		for _, cmd := range cmds {
+			for _, k := range cmd.Val() {
+				if !seen[k] {
+					seen[k] = true
+					next = append(next, k)
+				}
+			}
+		}
+		if len(next) > 0 && depth+1 > maxDepth {
+			return deleted, client.wrap(ErrCascadeDepth, "RedisInvalidateCascade", "", key)
+		}
+		level = next
+	}
+	return deleted, nil
+}
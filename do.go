@@ -0,0 +1,83 @@
+package redis
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const keyPlaceholder = "{key:"
+
+// Do sends an arbitrary command. String arguments may contain {key:name}
+// placeholders which are replaced by the prefixed key, e.g.
+//
+//	client.Do(ctx, "OBJECT", "FREQ", "{key:user:1}")
+//
+// Braces inside the name must be balanced, so hash tagged keys of KeyTag
+// work as well, {key:{user:1}:profile} names {user:1}:profile.
+//
+// On a tenant client arguments naming keys of other tenants fail with
+// ErrCrossTenant.
+func (client *Client) Do(ctx context.Context, args ...interface{}) *goredis.Cmd {
+	args = client.expandKeys(args)
+	if key, ok := client.crossTenant(args); ok {
+		return errCmd(ctx, errors.Wrap(ErrCrossTenant, key), args...)
+	}
+	return client.client.Do(ctx, args...)
+}
+
+func (client *Client) expandKeys(args []interface{}) []interface{} {
+	out := make([]interface{}, len(args))
+	for i, arg := range args {
+		s, ok := arg.(string)
+		if !ok {
+			out[i] = arg
+			continue
+		}
+		out[i] = client.expandKey(s)
+	}
+	return out
+}
+
+// expandKey replaces the placeholders of s, an unbalanced one is left as is.
+func (client *Client) expandKey(s string) string {
+	var b strings.Builder
+	for {
+		start := strings.Index(s, keyPlaceholder)
+		if start < 0 {
+			break
+		}
+		end := -1
+		depth := 1
+		for i := start + len(keyPlaceholder); i < len(s) && end < 0; i++ {
+			switch s[i] {
+			case '{':
+				depth++
+			case '}':
+				if depth--; depth == 0 {
+					end = i
+				}
+			}
+		}
+		if end < 0 {
+			break
+		}
+		b.WriteString(s[:start])
+		b.WriteString(client.fullKey(s[start+len(keyPlaceholder) : end]))
+		s = s[end+1:]
+	}
+	if b.Len() == 0 {
+		return s
+	}
+	b.WriteString(s)
+	return b.String()
+}
+
+// errCmd is a command failed before it was sent.
+func errCmd(ctx context.Context, e error, args ...interface{}) *goredis.Cmd {
+	cmd := goredis.NewCmd(ctx, args...)
+	cmd.SetErr(e)
+	return cmd
+}
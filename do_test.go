@@ -0,0 +1,26 @@
+package redis_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/acsl-go/redis"
+	"github.com/acsl-go/redis/redistest"
+)
+
+func TestDoExpandsKeyTagKeys(t *testing.T) {
+	client, _ := redistest.NewTestClient(t, nil)
+	ctx := context.Background()
+	key := redis.KeyTag("user", "1").Key("profile")
+
+	if e := client.Do(ctx, "SET", "{key:"+key+"}", "v").Err(); e != nil {
+		t.Fatal(e)
+	}
+	if got, e := client.GetStr(ctx, key); e != nil || got != "v" {
+		t.Fatalf("got %q, %v", got, e)
+	}
+	n, e := client.Do(ctx, "EXISTS", "{key:"+key+"}", "{key:plain}", "{key:{open").Int()
+	if e != nil || n != 1 {
+		t.Errorf("got %d, %v, want 1", n, e)
+	}
+}
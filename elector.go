@@ -0,0 +1,239 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+type ElectorConfig struct {
+	ID            string        // Identifies this instance, defaults to host:pid:nanotime
+	TTL           time.Duration // Leadership expires this long after the last renewal, defaults to 10 seconds
+	RenewInterval time.Duration // Defaults to a third of TTL
+
+	// OnElected runs in its own goroutine when leadership is won, ctx is
+	// cancelled as soon as it is lost, at the latest when TTL passed since the
+	// last successful renewal. token increases with every election and can be
+	// passed to downstream systems to fence off a stale leader.
+	OnElected func(ctx context.Context, token int64)
+	OnLost    func()
+}
+
+// LeaderElector campaigns for <prefix>:elect:{<name>} so that one instance
+// cluster wide is the leader. The value holds "<token>:<id>", the token comes
+// from INCR on <prefix>:elect:{<name>}:fence.
+type LeaderElector struct {
+	worker
+	client *Client
+	name   string
+	cfg    ElectorConfig
+
+	mu      sync.Mutex
+	token   int64
+	value   string
+	renewed time.Time
+	cancel  context.CancelFunc // Of the OnElected context, nil when not leading
+	expiry  *time.Timer        // Cancels the term once the lease ran out
+}
+
+var electAcquireScript = NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 1 then
+	return 0
+end
+local token = redis.call("INCR", KEYS[2])
+redis.call("SET", KEYS[1], token .. ":" .. ARGV[1], "PX", ARGV[2])
+return token
+`)
+
+// The fence key is kept, tokens must keep increasing across terms
+var electResignScript = NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// NewLeaderElector returns an elector that does not campaign yet, Start runs
+// it under ctx and Stop resigns.
+func (client *Client) NewLeaderElector(ctx context.Context, name string, cfg ElectorConfig) *LeaderElector {
+	if cfg.ID == "" {
+		host, _ := os.Hostname()
+		cfg.ID = fmt.Sprintf("%s:%d:%d", host, os.Getpid(), time.Now().UnixNano())
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = 10 * time.Second
+	}
+	if cfg.RenewInterval <= 0 {
+		cfg.RenewInterval = cfg.TTL / 3
+	}
+	el := &LeaderElector{client: client, name: name, cfg: cfg}
+	el.init(client, "RedisElector", ctx, el.loop)
+	return el
+}
+
+func (el *LeaderElector) keys() []string {
+	key := "elect:{" + el.name + "}"
+	return []string{key, key + ":fence"}
+}
+
+// IsLeader reports whether this instance holds leadership as of its last
+// renewal, it turns false once TTL passed without one.
+func (el *LeaderElector) IsLeader() bool {
+	_, ok := el.Token()
+	return ok
+}
+
+// Token returns the fencing token of the current term.
+func (el *LeaderElector) Token() (int64, bool) {
+	el.mu.Lock()
+	defer el.mu.Unlock()
+	if el.value == "" || time.Since(el.renewed) >= el.cfg.TTL {
+		return 0, false
+	}
+	return el.token, true
+}
+
+// Leader returns the id and token of the current leader, ErrNotFound when
+// there is none.
+func (el *LeaderElector) Leader(ctx context.Context) (string, int64, error) {
+	data_str, e := el.client.client.Get(ctx, el.client.fullKey(el.keys()[0])).Result()
+	if e != nil {
+		if e == goredis.Nil {
+			return "", 0, ErrNotFound
+		}
+		return "", 0, el.client.wrap(e, "RedisElectorLeader", "get", el.keys()[0])
+	}
+	token_str, id, _ := strings.Cut(data_str, ":")
+	token, _ := strconv.ParseInt(token_str, 10, 64)
+	return id, token, nil
+}
+
+func (el *LeaderElector) campaign(ctx context.Context) error {
+	// The lease may start as soon as the script is sent
+	start := time.Now()
+	token, e := electAcquireScript.Run(ctx, el.client, el.keys(), el.cfg.ID, el.cfg.TTL.Milliseconds()).Int64()
+	if e != nil {
+		return el.client.wrap(e, "RedisElectorCampaign", "evalsha", el.keys()[0])
+	}
+	if token == 0 {
+		return nil
+	}
+
+	elected_ctx, cancel := context.WithCancel(ctx)
+	el.mu.Lock()
+	el.token, el.value, el.renewed, el.cancel = token, strconv.FormatInt(token, 10)+":"+el.cfg.ID, start, cancel
+	el.expiry = time.AfterFunc(el.cfg.TTL-time.Since(start), func() { el.expire(token) })
+	el.mu.Unlock()
+	if el.cfg.OnElected != nil {
+		go func() {
+			defer el.client.recoverPanic("elector", nil)
+			el.cfg.OnElected(elected_ctx, token)
+		}()
+	}
+	return nil
+}
+
+func (el *LeaderElector) renew(ctx context.Context) error {
+	el.mu.Lock()
+	value := el.value
+	el.mu.Unlock()
+
+	start := time.Now()
+	n, e := leaseRenewScript.Run(ctx, el.client, el.keys(), value, el.cfg.TTL.Milliseconds()).Int64()
+	switch {
+	case e != nil:
+		// Keep leading until TTL ran out, Redis may be back before that
+		el.mu.Lock()
+		expired := time.Since(el.renewed) >= el.cfg.TTL
+		el.mu.Unlock()
+		if expired {
+			el.lose()
+		}
+		return el.client.wrap(e, "RedisElectorRenew", "evalsha", el.keys()[0])
+	case n == 0:
+		el.lose()
+	default:
+		el.mu.Lock()
+		if el.value == value {
+			el.renewed = start
+			el.expiry.Reset(el.cfg.TTL - time.Since(start))
+		}
+		el.mu.Unlock()
+	}
+	return nil
+}
+
+// expire ends the term of token when its lease ran out without a renewal.
+func (el *LeaderElector) expire(token int64) {
+	el.mu.Lock()
+	current := el.token == token && el.value != "" && time.Since(el.renewed) >= el.cfg.TTL
+	el.mu.Unlock()
+	if current {
+		el.lose()
+	}
+}
+
+func (el *LeaderElector) lose() {
+	el.mu.Lock()
+	cancel := el.cancel
+	if el.expiry != nil {
+		el.expiry.Stop()
+	}
+	el.token, el.value, el.cancel, el.expiry = 0, "", nil, nil
+	el.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	if el.cfg.OnLost != nil {
+		defer el.client.recoverPanic("elector", nil)
+		el.cfg.OnLost()
+	}
+}
+
+// Resign gives up leadership so another instance can take over without
+// waiting for TTL.
+func (el *LeaderElector) Resign(ctx context.Context) error {
+	el.mu.Lock()
+	value := el.value
+	el.mu.Unlock()
+	if value == "" {
+		return nil
+	}
+	defer el.lose()
+	if e := electResignScript.Run(ctx, el.client, el.keys()[:1], value).Err(); e != nil {
+		return el.client.wrap(e, "RedisElectorResign", "evalsha", el.keys()[0])
+	}
+	return nil
+}
+
+func (el *LeaderElector) loop(ctx context.Context, report func(error)) error {
+	ticker := time.NewTicker(el.cfg.RenewInterval)
+	defer ticker.Stop()
+	for {
+		var e error
+		if el.IsLeader() {
+			e = el.renew(ctx)
+		} else {
+			el.lose()
+			e = el.campaign(ctx)
+		}
+		if e != nil && ctx.Err() == nil {
+			report(e)
+		}
+
+		select {
+		case <-ctx.Done():
+			resign_ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Second)
+			defer cancel()
+			return el.Resign(resign_ctx)
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package redis
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+var ErrSchemaIncompatible = errors.New("redis: payload does not match registered schema")
+
+// SchemaRegistry validates and versions message payloads. Adapters for a
+// Confluent style or in-house HTTP registry implement it.
+type SchemaRegistry interface {
+	// ProduceVersion returns the schema version new payloads of subject are written with
+	ProduceVersion(ctx context.Context, subject string) (int, error)
+	// Validate fails, preferably with ErrSchemaIncompatible, when payload does not fit version
+	Validate(ctx context.Context, subject string, version int, payload []byte) error
+}
+
+// Envelope wraps pub/sub and stream payloads with their schema so consumers
+// can reject incompatible messages before decoding them. Envelope and payload
+// are both encoded with the codec of the client.
+type Envelope struct {
+	Subject string `json:"subject"`
+	Version int    `json:"version"`
+	Payload []byte `json:"payload"`
+}
+
+func WithSchemaRegistry(registry SchemaRegistry) Option {
+	return func(client *Client) {
+		client.schemas = registry
+	}
+}
+
+func (client *Client) Publish(ctx context.Context, channel string, v interface{}) error {
+	data_str, e := client.codec.Marshal(v)
+	if e != nil {
+		return client.wrap(e, "RedisPublish:Marshal", "", channel)
+	}
+	if e := client.client.Publish(ctx, client.config.Prefix+":"+channel, data_str).Err(); e != nil {
+		return client.wrap(e, "RedisPublish", "publish", channel)
+	}
+	return nil
+}
+
+// PublishEnvelope encodes v, validates it against the producing schema
+// version of subject and publishes it inside an Envelope.
+func (client *Client) PublishEnvelope(ctx context.Context, channel, subject string, v interface{}) error {
+	data_str, e := client.sealEnvelope(ctx, subject, v)
+	if e != nil {
+		return client.wrap(e, "RedisPublishEnvelope", "", channel)
+	}
+	if e := client.client.Publish(ctx, client.config.Prefix+":"+channel, data_str).Err(); e != nil {
+		return client.wrap(e, "RedisPublishEnvelope", "publish", channel)
+	}
+	return nil
+}
+
+// ProduceEnvelope is PublishEnvelope for streams, consumers with
+// ConsumerConfig.Envelopes open it before the handler runs.
+func (client *Client) ProduceEnvelope(ctx context.Context, stream, subject string, v interface{}) (string, error) {
+	data, e := client.sealEnvelope(ctx, subject, v)
+	if e != nil {
+		return "", client.wrap(e, "RedisProduceEnvelope", "", stream)
+	}
+	return client.Produce(ctx, stream, data)
+}
+
+func (client *Client) sealEnvelope(ctx context.Context, subject string, v interface{}) ([]byte, error) {
+	env, e := client.seal(ctx, subject, v)
+	if e != nil {
+		return nil, e
+	}
+	data, e := client.codec.Marshal(env)
+	if e != nil {
+		return nil, errors.Wrap(e, "Marshal")
+	}
+	return data, nil
+}
+
+func (client *Client) seal(ctx context.Context, subject string, v interface{}) (*Envelope, error) {
+	payload, e := client.codec.Marshal(v)
+	if e != nil {
+		return nil, errors.Wrap(e, "Marshal")
+	}
+	env := &Envelope{Subject: subject, Payload: payload}
+	if client.schemas == nil {
+		return env, nil
+	}
+	if env.Version, e = client.schemas.ProduceVersion(ctx, subject); e != nil {
+		return nil, e
+	}
+	if e := client.schemas.Validate(ctx, subject, env.Version, payload); e != nil {
+		return nil, e
+	}
+	return env, nil
+}
+
+// OpenEnvelope decodes a message published with PublishEnvelope or
+// ProduceEnvelope into v after validating it against the version it was
+// produced with. A nil v only opens and validates the envelope.
+func (client *Client) OpenEnvelope(ctx context.Context, data []byte, v interface{}) (*Envelope, error) {
+	env := &Envelope{}
+	if e := client.codec.Unmarshal(data, env); e != nil {
+		return nil, errors.Wrap(e, "RedisOpenEnvelope:Unmarshal")
+	}
+	if client.schemas != nil {
+		if e := client.schemas.Validate(ctx, env.Subject, env.Version, env.Payload); e != nil {
+			return env, errors.Wrap(e, "RedisOpenEnvelope")
+		}
+	}
+	if v != nil {
+		if e := client.DecodePayload(env, v); e != nil {
+			return env, e
+		}
+	}
+	return env, nil
+}
+
+// DecodePayload decodes the payload of an opened envelope into v.
+func (client *Client) DecodePayload(env *Envelope, v interface{}) error {
+	if e := client.codec.Unmarshal(env.Payload, v); e != nil {
+		return errors.Wrap(e, "RedisOpenEnvelope:Unmarshal")
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Errors are classified so callers can test for them with errors.Is instead of
+// matching server messages. ErrNotFound also matches wrapped goredis.Nil.
+var (
+	ErrTimeout  = errors.New("redis: timeout")
+	ErrReadOnly = errors.New("redis: node is read only")    // Writes sent to a replica, e.g. during failover
+	ErrLoading  = errors.New("redis: node is loading data") // The server is loading its dataset after a restart
+
+	ErrCrossSlot = errors.New("redis: keys in different cluster slots") // Multi-key command over several slots
+)
+
+// MovedError is a MOVED or ASK redirection that reached the caller, usually
+// because cluster mode is not enabled for a clustered server or the
+// redirections exceeded Config.MaxRedirects.
+type MovedError struct {
+	Ask  bool // ASK during slot migration rather than MOVED
+	Slot int
+	Addr string
+	Err  error
+}
+
+func (e *MovedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *MovedError) Unwrap() error {
+	return e.Err
+}
+
+func asMovedError(e error) *MovedError {
+	var rerr goredis.Error
+	if !errors.As(e, &rerr) {
+		return nil
+	}
+	kind, rest, _ := strings.Cut(rerr.Error(), " ")
+	if kind != "MOVED" && kind != "ASK" {
+		return nil
+	}
+	slot_str, addr, _ := strings.Cut(rest, " ")
+	slot, _ := strconv.Atoi(slot_str)
+	return &MovedError{Ask: kind == "ASK", Slot: slot, Addr: addr, Err: e}
+}
+
+// errorKind returns the sentinel e is classified as, nil when none applies.
+func errorKind(e error) error {
+	var nerr net.Error
+	var rerr goredis.Error
+	switch {
+	case errors.Is(e, goredis.Nil):
+		return ErrNotFound
+	case errors.Is(e, context.DeadlineExceeded), errors.Is(e, os.ErrDeadlineExceeded),
+		errors.As(e, &nerr) && nerr.Timeout(), isPoolTimeout(e):
+		return ErrTimeout
+	case errors.As(e, &rerr) && strings.HasPrefix(rerr.Error(), "READONLY "):
+		return ErrReadOnly
+	case errors.As(e, &rerr) && strings.HasPrefix(rerr.Error(), "LOADING "):
+		return ErrLoading
+	case errors.As(e, &rerr) && strings.HasPrefix(rerr.Error(), "CROSSSLOT "):
+		return ErrCrossSlot
+	}
+	return nil
+}
+
+// CommandError carries the context of a failed wrapper call, retrieve it with errors.As.
+type CommandError struct {
+	Op      string // Wrapper operation, e.g. RedisGet
+	Command string // Redis command, empty for local failures like encoding
+	Key     string // Unprefixed key, redacted when Config.RedactKeys is set
+	Attempt int
+	Addr    string // Node address when it is known
+	Err     error
+}
+
+func (e *CommandError) Error() string {
+	return e.Op + ": " + e.Err.Error()
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+// Cause keeps github.com/pkg/errors.Cause working like it did with errors.Wrap
+func (e *CommandError) Cause() error {
+	return e.Err
+}
+
+// Is matches the sentinel the underlying error is classified as, e.g.
+// errors.Is(e, ErrTimeout).
+func (e *CommandError) Is(target error) bool {
+	kind := errorKind(e.Err)
+	return kind != nil && kind == target
+}
+
+func (client *Client) wrap(e error, op, cmd, key string) error {
+	if e == nil {
+		return nil
+	}
+	if client.config.RedactKeys {
+		key = client.redact(key)
+	}
+	attempt := 1
+	if re, ok := e.(*retriedError); ok {
+		attempt = re.attempts
+		e = re.err
+	}
+	if moved := asMovedError(e); moved != nil {
+		e = moved
+	}
+	return &CommandError{
+		Op:      op,
+		Command: cmd,
+		Key:     key,
+		Attempt: attempt,
+		Addr:    client.nodeAddr(),
+		Err:     e,
+	}
+}
+
+func (client *Client) redact(key string) string {
+	if client.config.KeyRedactor != nil {
+		return client.config.KeyRedactor(key)
+	}
+	return RedactKey(key)
+}
+
+func (client *Client) nodeAddr() string {
+	if len(client.config.Addresses) == 1 {
+		return client.config.Addresses[0]
+	}
+	return ""
+}
+
+// RedactKey masks every ':' separated segment that contains a digit, so
+// "user:12345:profile" is reported as "user:*:profile".
+func RedactKey(key string) string {
+	segs := strings.Split(key, ":")
+	for i, seg := range segs {
+		if strings.IndexFunc(seg, unicode.IsDigit) >= 0 {
+			segs[i] = "*"
+		}
+	}
+	return strings.Join(segs, ":")
+}
@@ -0,0 +1,243 @@
+package redis
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+type EvictorConfig struct {
+	Interval  time.Duration // Memory check interval, defaults to one minute
+	Threshold float64       // Start evicting above this share of maxmemory, defaults to 0.9
+	MaxMemory int64         // Used when the server has no maxmemory set, in bytes
+	Sample    int           // Keys sampled per batch, defaults to 1000
+	Batch     int           // Coldest keys unlinked per batch, defaults to 100
+	Pause     time.Duration // Between batches, defaults to 100ms
+	MinIdle   time.Duration // Never evict keys read or written more recently
+
+	// Namespaces limits eviction to the keys under <prefix>:<namespace>, e.g.
+	// "cache:". Without it every key is a candidate except the state of the
+	// subsystems of this package and streams.
+	Namespaces []string
+}
+
+// ErrIdleTimeUnavailable is returned when the server uses an LFU
+// maxmemory-policy, it does not track the idle time the evictor ranks by.
+var ErrIdleTimeUnavailable = errors.New("redis: OBJECT IDLETIME is not available under an LFU maxmemory-policy")
+
+// ColdKeyEvictor unlinks the least recently used keys under the prefix while
+// the server memory is above the threshold. It protects the other users of a
+// shared instance before the server's own eviction policy kicks in, which
+// does not care whose keys it drops.
+type ColdKeyEvictor struct {
+	worker
+	client *Client
+	cfg    EvictorConfig
+}
+
+// NewColdKeyEvictor returns a stopped evictor, Start runs it under ctx.
+func (client *Client) NewColdKeyEvictor(ctx context.Context, cfg EvictorConfig) *ColdKeyEvictor {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Minute
+	}
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = 0.9
+	}
+	if cfg.Sample <= 0 {
+		cfg.Sample = 1000
+	}
+	if cfg.Batch <= 0 {
+		cfg.Batch = 100
+	}
+	if cfg.Pause <= 0 {
+		cfg.Pause = 100 * time.Millisecond
+	}
+	ev := &ColdKeyEvictor{client: client, cfg: cfg}
+	ev.init(client, "RedisEvict", ctx, ev.loop)
+	return ev
+}
+
+// Evict runs batches until memory is below the threshold on every node and
+// returns the number of unlinked keys.
+func (ev *ColdKeyEvictor) Evict(ctx context.Context) (int, error) {
+	if cc, ok := ev.client.client.(*goredis.ClusterClient); ok {
+		var total atomic.Int64
+		e := cc.ForEachMaster(ctx, func(ctx context.Context, node *goredis.Client) error {
+			n, e := ev.evictNode(ctx, node)
+			total.Add(int64(n))
+			return e
+		})
+		return int(total.Load()), e
+	}
+	return ev.evictNode(ctx, ev.client.client)
+}
+
+func (ev *ColdKeyEvictor) evictNode(ctx context.Context, node goredis.Cmdable) (int, error) {
+	var evicted int
+	var cursor uint64
+	for {
+		over, e := ev.overThreshold(ctx, node)
+		if e != nil || !over {
+			return evicted, e
+		}
+		n, e := ev.batch(ctx, node, &cursor)
+		evicted += n
+		if e != nil || (n == 0 && cursor == 0) {
+			return evicted, e
+		}
+		select {
+		case <-ctx.Done():
+			return evicted, ctx.Err()
+		case <-time.After(ev.cfg.Pause):
+		}
+	}
+}
+
+func (ev *ColdKeyEvictor) overThreshold(ctx context.Context, node goredis.Cmdable) (bool, error) {
+	info, e := node.Info(ctx, "memory").Result()
+	if e != nil {
+		return false, ev.client.wrap(e, "RedisEvict", "info", "")
+	}
+	var used, limit int64
+	for _, line := range strings.Split(info, "\n") {
+		k, v, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		switch k {
+		case "used_memory":
+			used, _ = strconv.ParseInt(v, 10, 64)
+		case "maxmemory":
+			limit, _ = strconv.ParseInt(v, 10, 64)
+		}
+	}
+	if limit == 0 {
+		limit = ev.cfg.MaxMemory
+	}
+	return limit > 0 && float64(used) > ev.cfg.Threshold*float64(limit), nil
+}
+
+// Subsystem state that must survive memory pressure. With a KeyProvider the
+// keys are obscured and only MinIdle protects them, Namespaces can not be
+// used then either.
+var evictProtected = []string{
+	"lease:", "migrations", "consumers:", "deps:", "sched:", "delay:", "elect:",
+	"sess:", "rollout:", "cnt:", "blob:", "orset:", "pn:", "origin:", "scaling:",
+	"ratelimit:", "probe:", "lb:", "dau:",
+}
+
+// batch samples the keys from the SCAN cursor on and unlinks the coldest.
+func (ev *ColdKeyEvictor) batch(ctx context.Context, node goredis.Cmdable, cursor *uint64) (int, error) {
+	prefix := ev.client.config.Prefix + ":"
+	var keys []string
+	for len(keys) < ev.cfg.Sample {
+		page, next, e := node.Scan(ctx, *cursor, prefix+"*", int64(ev.cfg.Sample)).Result()
+		if e != nil {
+			return 0, ev.client.wrap(e, "RedisEvict", "scan", "")
+		}
+		for _, key := range page {
+			if !ev.protected(strings.TrimPrefix(key, prefix)) {
+				keys = append(keys, key)
+			}
+		}
+		if *cursor = next; next == 0 {
+			break
+		}
+	}
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	// Streams carry work that is not cached anywhere else
+	pipe := node.Pipeline()
+	types := make([]*goredis.StatusCmd, len(keys))
+	idles := make([]*goredis.DurationCmd, len(keys))
+	for i, key := range keys {
+		types[i] = pipe.Type(ctx, key)
+		idles[i] = pipe.ObjectIdleTime(ctx, key)
+	}
+	pipe.Exec(ctx)
+
+	type candidate struct {
+		key  string
+		idle time.Duration
+	}
+	var candidates []candidate
+	for i, cmd := range idles {
+		idle, e := cmd.Result()
+		if e != nil && strings.Contains(e.Error(), "LFU") {
+			return 0, ev.client.wrap(ErrIdleTimeUnavailable, "RedisEvict", "object", "")
+		}
+		if e == nil && idle >= ev.cfg.MinIdle && types[i].Val() != "stream" {
+			candidates = append(candidates, candidate{keys[i], idle})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].idle > candidates[j].idle })
+	if len(candidates) > ev.cfg.Batch {
+		candidates = candidates[:ev.cfg.Batch]
+	}
+	if len(candidates) == 0 {
+		return 0, nil
+	}
+
+	pipe = node.Pipeline()
+	for _, c := range candidates {
+		pipe.Unlink(ctx, c.key)
+	}
+	if _, e := pipe.Exec(ctx); e != nil {
+		return 0, ev.client.wrap(e, "RedisEvict", "unlink", "")
+	}
+	for _, c := range candidates {
+		ev.client.invalidateLocal(ctx, c.key)
+	}
+	return len(candidates), nil
+}
+
+func (ev *ColdKeyEvictor) protected(key string) bool {
+	if len(ev.cfg.Namespaces) > 0 {
+		for _, ns := range ev.cfg.Namespaces {
+			if strings.HasPrefix(key, ns) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, p := range evictProtected {
+		if strings.HasPrefix(key, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// Run checks every Interval until ctx is done.
+func (ev *ColdKeyEvictor) Run(ctx context.Context) {
+	ev.loop(ctx, func(e error) {
+		ev.client.log().Warn("RedisEvict", "error", e)
+	})
+}
+
+func (ev *ColdKeyEvictor) loop(ctx context.Context, report func(error)) error {
+	ticker := time.NewTicker(ev.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			n, e := ev.Evict(ctx)
+			if e != nil && ctx.Err() == nil {
+				report(e)
+			}
+			if n > 0 {
+				ev.client.log().Info("RedisEvict", "unlinked", n)
+			}
+		}
+	}
+}
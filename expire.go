@@ -0,0 +1,73 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrInvalidTTL is returned for a ttl of 0 or less, PEXPIRE would delete the
+// key instead of expiring it.
+var ErrInvalidTTL = errors.New("redis: ttl must be positive")
+
+type ExpireCondition string
+
+// Conditions of Redis 7, GT and LT treat a key without expiry as having an
+// infinite ttl.
+const (
+	ExpireAlways ExpireCondition = ""
+	ExpireNX     ExpireCondition = "NX" // Only when the key has no expiry
+	ExpireXX     ExpireCondition = "XX" // Only when the key has an expiry
+	ExpireGT     ExpireCondition = "GT" // Only when the new expiry is later
+	ExpireLT     ExpireCondition = "LT" // Only when the new expiry is sooner
+)
+
+// Expire sets the ttl of key with millisecond precision, a key that does not
+// exist is not an error.
+func (client *Client) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	_, e := client.ExpireIf(ctx, key, ttl, ExpireAlways)
+	return e
+}
+
+// ExpireAt expires key at t, with millisecond precision.
+func (client *Client) ExpireAt(ctx context.Context, key string, t time.Time) error {
+	if e := client.client.PExpireAt(ctx, client.fullKey(key), t).Err(); e != nil {
+		return client.wrap(e, "RedisExpireAt", "pexpireat", key)
+	}
+	return nil
+}
+
+// ExpireIf sets the ttl of key when cond holds and reports whether it did,
+// false also means the key does not exist. A ttl below a millisecond is
+// rounded up to one, use Del to drop a key.
+func (client *Client) ExpireIf(ctx context.Context, key string, ttl time.Duration, cond ExpireCondition) (bool, error) {
+	if ttl <= 0 {
+		return false, client.wrap(ErrInvalidTTL, "RedisExpire", "pexpire", key)
+	}
+	args := []interface{}{"PEXPIRE", client.fullKey(key), max(ttl.Milliseconds(), 1)}
+	if cond != ExpireAlways {
+		args = append(args, string(cond))
+	}
+	n, e := client.client.Do(ctx, args...).Int64()
+	if e != nil {
+		return false, client.wrap(e, "RedisExpire", "pexpire", key)
+	}
+	return n == 1, nil
+}
+
+func (client *Client) ExpireNX(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return client.ExpireIf(ctx, key, ttl, ExpireNX)
+}
+
+func (client *Client) ExpireXX(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return client.ExpireIf(ctx, key, ttl, ExpireXX)
+}
+
+func (client *Client) ExpireGT(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return client.ExpireIf(ctx, key, ttl, ExpireGT)
+}
+
+func (client *Client) ExpireLT(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return client.ExpireIf(ctx, key, ttl, ExpireLT)
+}
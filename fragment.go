@@ -0,0 +1,153 @@
+package redis
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+type FragmentCacheConfig struct {
+	TTL      time.Duration // Fresh for TTL
+	Grace    time.Duration // Then served stale for Grace while one caller re-renders
+	MaxBytes int           // Larger fragments are rendered but not cached, 0 means no limit
+}
+
+type FragmentStats struct {
+	Hits       int64
+	StaleHits  int64
+	Misses     int64
+	Renders    int64
+	Oversized  int64
+	BytesSaved int64 // Total size of the fragments written
+}
+
+// FragmentCache caches rendered fragments in hashes under
+// <prefix>:frag:<name>:<vary>, with the body and the time it turns stale.
+type FragmentCache struct {
+	client *Client
+	name   string
+	cfg    FragmentCacheConfig
+
+	hits, stale, misses, renders, oversized, bytes atomic.Int64
+}
+
+func (client *Client) FragmentCache(name string, cfg FragmentCacheConfig) *FragmentCache {
+	if cfg.TTL <= 0 {
+		cfg.TTL = time.Minute
+	}
+	return &FragmentCache{client: client, name: name, cfg: cfg}
+}
+
+// Key builds the fragment key, vary parameters are sorted so their order does
+// not matter. Long keys are hashed.
+func (f *FragmentCache) Key(vary map[string]string) string {
+	values := url.Values{}
+	for k, v := range vary {
+		values.Set(k, v)
+	}
+	vary_str := values.Encode()
+	if len(vary_str) > 128 {
+		sum := sha1.Sum([]byte(vary_str))
+		vary_str = hex.EncodeToString(sum[:])
+	}
+	return "frag:" + f.name + ":" + vary_str
+}
+
+func (f *FragmentCache) Stats() FragmentStats {
+	return FragmentStats{
+		Hits:       f.hits.Load(),
+		StaleHits:  f.stale.Load(),
+		Misses:     f.misses.Load(),
+		Renders:    f.renders.Load(),
+		Oversized:  f.oversized.Load(),
+		BytesSaved: f.bytes.Load(),
+	}
+}
+
+// Get returns the cached fragment, rendering it on a miss. A stale fragment is
+// returned as is while a single background refresh renders the new one.
+func (f *FragmentCache) Get(ctx context.Context, vary map[string]string, render func(ctx context.Context) ([]byte, error)) ([]byte, error) {
+	key := f.Key(vary)
+	key_str := f.client.fullKey(key)
+
+	// Leave most of the deadline to the render and the store on a miss
+	budget := NewBudget(ctx, 3, 10*time.Millisecond)
+	lookup_ctx, cancel := budget.Step(ctx)
+	res, e := f.client.client.HMGet(lookup_ctx, key_str, "body", "fresh").Result()
+	cancel()
+	if e != nil && e != goredis.Nil {
+		f.client.log().Warn("RedisFragmentCache:Get", "key", f.client.logKey(key), "error", e)
+	}
+	if len(res) == 2 && res[0] != nil {
+		body := []byte(res[0].(string))
+		fresh, _ := strconv.ParseInt(stringOf(res[1]), 10, 64)
+		if time.Now().UnixMilli() < fresh {
+			f.hits.Add(1)
+			return body, nil
+		}
+		f.stale.Add(1)
+		if ok, _ := f.client.client.SetNX(ctx, key_str+":refresh", 1, f.cfg.Grace+time.Second).Result(); ok {
+			go func() {
+				ctx := context.WithoutCancel(ctx)
+				if _, e := f.render(ctx, key, key_str, render); e != nil {
+					f.client.log().Warn("RedisFragmentCache:Refresh", "key", f.client.logKey(key), "error", e)
+				}
+				f.client.client.Del(ctx, key_str+":refresh")
+			}()
+		}
+		return body, nil
+	}
+
+	f.misses.Add(1)
+	return f.render(ctx, key, key_str, render)
+}
+
+func (f *FragmentCache) render(ctx context.Context, key, key_str string, render func(ctx context.Context) ([]byte, error)) ([]byte, error) {
+	f.renders.Add(1)
+	body, e := f.callRender(ctx, render)
+	if e != nil {
+		return nil, e
+	}
+	if f.cfg.MaxBytes > 0 && len(body) > f.cfg.MaxBytes {
+		f.oversized.Add(1)
+		return body, nil
+	}
+
+	fresh := time.Now().Add(f.cfg.TTL).UnixMilli()
+	pipe := f.client.client.TxPipeline()
+	pipe.HSet(ctx, key_str, "body", body, "fresh", fresh)
+	pipe.Expire(ctx, key_str, f.cfg.TTL+f.cfg.Grace)
+	if _, e := pipe.Exec(ctx); e != nil {
+		f.client.log().Warn("RedisFragmentCache:Store", "key", f.client.logKey(key), "error", e)
+	} else {
+		f.bytes.Add(int64(len(body)))
+	}
+	return body, nil
+}
+
+func (f *FragmentCache) callRender(ctx context.Context, render func(ctx context.Context) ([]byte, error)) (body []byte, e error) {
+	defer f.client.recoverPanic("fragment", &e)
+	return render(ctx)
+}
+
+// Purge drops a fragment so the next Get renders it again.
+func (f *FragmentCache) Purge(ctx context.Context, vary map[string]string) error {
+	key := f.Key(vary)
+	if e := f.client.client.Del(ctx, f.client.fullKey(key)).Err(); e != nil {
+		return f.client.wrap(e, "RedisFragmentPurge", "del", key)
+	}
+	return nil
+}
+
+func stringOf(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return ""
+}
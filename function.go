@@ -0,0 +1,76 @@
+package redis
+
+import (
+	"context"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// FunctionLoad loads a Redis 7 function library, replacing a loaded library
+// of the same name. In cluster mode it is loaded on every master.
+func (client *Client) FunctionLoad(ctx context.Context, code string) error {
+	if cc, ok := client.client.(*goredis.ClusterClient); ok {
+		e := cc.ForEachMaster(ctx, func(ctx context.Context, node *goredis.Client) error {
+			return node.FunctionLoadReplace(ctx, code).Err()
+		})
+		if e != nil {
+			return client.wrap(e, "RedisFunctionLoad", "function", "")
+		}
+		return nil
+	}
+	if e := client.client.FunctionLoadReplace(ctx, code).Err(); e != nil {
+		return client.wrap(e, "RedisFunctionLoad", "function", "")
+	}
+	return nil
+}
+
+func (client *Client) loadFunctions(ctx context.Context) error {
+	for _, code := range client.config.Functions {
+		if e := client.FunctionLoad(ctx, code); e != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+// FCall calls a loaded function with the client prefix applied to keys.
+// Errors of the returned command are not wrapped so that replies can be
+// inspected. Keys in different cluster slots fail with ErrCrossSlot.
+func (client *Client) FCall(ctx context.Context, function string, keys []string, args ...interface{}) *goredis.Cmd {
+	if e := client.checkSlots(keys...); e != nil {
+		return errCmd(ctx, e)
+	}
+	return client.client.FCall(ctx, function, client.prefixKeys(keys), args...)
+}
+
+// FCallRO calls a read-only function, which may be served by a replica.
+func (client *Client) FCallRO(ctx context.Context, function string, keys []string, args ...interface{}) *goredis.Cmd {
+	if e := client.checkSlots(keys...); e != nil {
+		return errCmd(ctx, e)
+	}
+	return client.client.FCallRo(ctx, function, client.prefixKeys(keys), args...)
+}
+
+func (client *Client) FunctionDelete(ctx context.Context, library string) error {
+	if cc, ok := client.client.(*goredis.ClusterClient); ok {
+		e := cc.ForEachMaster(ctx, func(ctx context.Context, node *goredis.Client) error {
+			return node.FunctionDelete(ctx, library).Err()
+		})
+		if e != nil {
+			return client.wrap(e, "RedisFunctionDelete", "function", "")
+		}
+		return nil
+	}
+	if e := client.client.FunctionDelete(ctx, library).Err(); e != nil {
+		return client.wrap(e, "RedisFunctionDelete", "function", "")
+	}
+	return nil
+}
+
+func (client *Client) prefixKeys(keys []string) []string {
+	key_strs := make([]string, len(keys))
+	for i, key := range keys {
+		key_strs[i] = client.fullKey(key)
+	}
+	return key_strs
+}
@@ -0,0 +1,131 @@
+package redis
+
+import (
+	"context"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+type GeoPoint struct {
+	Name      string
+	Longitude float64
+	Latitude  float64
+}
+
+type GeoMatch struct {
+	Name      string
+	Longitude float64
+	Latitude  float64
+	Dist      float64 // In the unit of the query
+}
+
+// GeoQuery searches around a member or a coordinate, within a radius or a box.
+// Unit is one of m, km, mi or ft and defaults to km.
+type GeoQuery struct {
+	Member    string
+	Longitude float64
+	Latitude  float64
+
+	Radius    float64
+	BoxWidth  float64
+	BoxHeight float64
+	Unit      string
+
+	Count int  // 0 means no limit
+	Any   bool // Stop after Count matches instead of returning the nearest
+	Desc  bool
+}
+
+func (q *GeoQuery) args() goredis.GeoSearchQuery {
+	unit := q.Unit
+	if unit == "" {
+		unit = "km"
+	}
+	sort := "ASC"
+	if q.Desc {
+		sort = "DESC"
+	}
+	return goredis.GeoSearchQuery{
+		Member:     q.Member,
+		Longitude:  q.Longitude,
+		Latitude:   q.Latitude,
+		Radius:     q.Radius,
+		RadiusUnit: unit,
+		BoxWidth:   q.BoxWidth,
+		BoxHeight:  q.BoxHeight,
+		BoxUnit:    unit,
+		Sort:       sort,
+		Count:      q.Count,
+		CountAny:   q.Any,
+	}
+}
+
+func (client *Client) GeoAdd(ctx context.Context, key string, points ...GeoPoint) (int64, error) {
+	key_str := client.fullKey(key)
+	locations := make([]*goredis.GeoLocation, len(points))
+	for i, p := range points {
+		locations[i] = &goredis.GeoLocation{Name: p.Name, Longitude: p.Longitude, Latitude: p.Latitude}
+	}
+	n, e := client.client.GeoAdd(ctx, key_str, locations...).Result()
+	if e != nil {
+		return 0, client.wrap(e, "RedisGeoAdd", "geoadd", key)
+	}
+	client.invalidateLocal(ctx, key_str)
+	return n, nil
+}
+
+// GeoSearch returns the matches with their coordinates and distance, nearest
+// first unless the query says otherwise.
+func (client *Client) GeoSearch(ctx context.Context, key string, q *GeoQuery) ([]GeoMatch, error) {
+	key_str := client.fullKey(key)
+	locations, e := client.client.GeoSearchLocation(ctx, key_str, &goredis.GeoSearchLocationQuery{
+		GeoSearchQuery: q.args(),
+		WithCoord:      true,
+		WithDist:       true,
+	}).Result()
+	if e != nil {
+		return nil, client.wrap(e, "RedisGeoSearch", "geosearch", key)
+	}
+	matches := make([]GeoMatch, len(locations))
+	for i, l := range locations {
+		matches[i] = GeoMatch{Name: l.Name, Longitude: l.Longitude, Latitude: l.Latitude, Dist: l.Dist}
+	}
+	return matches, nil
+}
+
+// GeoRadius finds the matches within radius of a coordinate.
+func (client *Client) GeoRadius(ctx context.Context, key string, longitude, latitude, radius float64, unit string, count int) ([]GeoMatch, error) {
+	return client.GeoSearch(ctx, key, &GeoQuery{Longitude: longitude, Latitude: latitude, Radius: radius, Unit: unit, Count: count})
+}
+
+// GeoDist returns ErrNotFound when either member is missing.
+func (client *Client) GeoDist(ctx context.Context, key string, member1, member2, unit string) (float64, error) {
+	key_str := client.fullKey(key)
+	if unit == "" {
+		unit = "km"
+	}
+	dist, e := client.client.GeoDist(ctx, key_str, member1, member2, unit).Result()
+	if e != nil {
+		if e == goredis.Nil {
+			return 0, ErrNotFound
+		}
+		return 0, client.wrap(e, "RedisGeoDist", "geodist", key)
+	}
+	return dist, nil
+}
+
+// GeoPos returns one entry per member, nil for the missing ones.
+func (client *Client) GeoPos(ctx context.Context, key string, members ...string) ([]*GeoPoint, error) {
+	key_str := client.fullKey(key)
+	positions, e := client.client.GeoPos(ctx, key_str, members...).Result()
+	if e != nil {
+		return nil, client.wrap(e, "RedisGeoPos", "geopos", key)
+	}
+	points := make([]*GeoPoint, len(positions))
+	for i, p := range positions {
+		if p != nil {
+			points[i] = &GeoPoint{Name: members[i], Longitude: p.Longitude, Latitude: p.Latitude}
+		}
+	}
+	return points, nil
+}
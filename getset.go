@@ -0,0 +1,140 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// GetSet stores v and decodes the previous value into old, ErrNotFound means
+// there was none but v was still stored.
+func (client *Client) GetSet(ctx context.Context, key string, v interface{}, ttl int, old interface{}) error {
+	return client.GetSetFor(ctx, key, v, seconds(ttl), old)
+}
+
+func (client *Client) GetSetFor(ctx context.Context, key string, v interface{}, ttl time.Duration, old interface{}) error {
+	key_str := client.fullKey(key)
+	data_str, e := client.codec.Marshal(v)
+	if e != nil {
+		return client.wrap(e, "RedisGetSet:JSONMarshal", "", key)
+	}
+	if e := client.checkSize("RedisGetSet", key, data_str); e != nil {
+		return e
+	}
+	expiration := client.expirationFor(ttl)
+	if client.jsonDocs(ctx) {
+		r, e := client.setDoc(ctx, key_str, string(data_str), "", expiration, true)
+		client.invalidateLocal(ctx, key_str)
+		if e != nil {
+			return client.wrap(e, "RedisGetSet", "json.set", key)
+		}
+		if !r.found {
+			return ErrNotFound
+		}
+		return client.decode("RedisGetSet", key, r.prev, old)
+	}
+	prev, e := client.client.SetArgs(ctx, key_str, data_str, goredis.SetArgs{Get: true, TTL: expiration, KeepTTL: expiration == goredis.KeepTTL}).Result()
+	client.invalidateLocal(ctx, key_str)
+	if e != nil {
+		if e == goredis.Nil {
+			return ErrNotFound
+		}
+		return client.wrap(e, "RedisGetSet", "set", key)
+	}
+	return client.decode("RedisGetSet", key, prev, old)
+}
+
+// GetDel decodes key into v and deletes it atomically, e.g. for one-time
+// tokens.
+func (client *Client) GetDel(ctx context.Context, key string, v interface{}) error {
+	key_str := client.fullKey(key)
+	var data_str string
+	var e error
+	if client.jsonDocs(ctx) {
+		data_str, e = client.txDoc(ctx, key_str, func(pipe goredis.Pipeliner) {
+			pipe.Del(ctx, key_str)
+		})
+	} else {
+		data_str, e = client.client.GetDel(ctx, key_str).Result()
+	}
+	if e != nil {
+		if e == goredis.Nil {
+			return ErrNotFound
+		}
+		return client.wrap(e, "RedisGetDel", "getdel", key)
+	}
+	client.invalidateLocal(ctx, key_str)
+	return client.decode("RedisGetDel", key, data_str, v)
+}
+
+// GetEx decodes key into v and resets its expiration to ttl seconds. KeepTTL
+// leaves the expiration as it is, Persist removes it.
+func (client *Client) GetEx(ctx context.Context, key string, v interface{}, ttl int) error {
+	return client.GetExFor(ctx, key, v, seconds(ttl))
+}
+
+func (client *Client) GetExFor(ctx context.Context, key string, v interface{}, ttl time.Duration) error {
+	key_str := client.fullKey(key)
+	docs := client.jsonDocs(ctx)
+	cmd := "getex"
+	var data_str string
+	var e error
+	switch {
+	case ttl == Persist && docs:
+		data_str, e = client.txDoc(ctx, key_str, func(pipe goredis.Pipeliner) {
+			pipe.Persist(ctx, key_str)
+		})
+	case ttl == Persist:
+		// GETEX without a positive expiration sends PERSIST
+		data_str, e = client.client.GetEx(ctx, key_str, 0).Result()
+	case ttl < 0 && docs:
+		cmd = "json.get"
+		data_str, e = readDoc(ctx, client.client, key_str)
+	case ttl < 0:
+		cmd = "get"
+		data_str, e = client.client.Get(ctx, key_str).Result()
+	default:
+		expiration := client.expirationFor(ttl)
+		if docs {
+			data_str, e = client.txDoc(ctx, key_str, func(pipe goredis.Pipeliner) {
+				if expiration > 0 {
+					pipe.PExpire(ctx, key_str, expiration)
+				} else {
+					pipe.Persist(ctx, key_str)
+				}
+			})
+		} else {
+			data_str, e = client.client.GetEx(ctx, key_str, expiration).Result()
+		}
+	}
+	if e != nil {
+		if e == goredis.Nil {
+			return ErrNotFound
+		}
+		return client.wrap(e, "RedisGetEx", cmd, key)
+	}
+	return client.decode("RedisGetEx", key, data_str, v)
+}
+
+func (client *Client) decode(op, key, data_str string, v interface{}) error {
+	data_str = stripMeta(data_str)
+	if data_str == "" {
+		return ErrNotFound
+	}
+	if e := client.codec.Unmarshal([]byte(data_str), v); e != nil {
+		return client.wrap(e, op+":JSONUnmarshal", "", key)
+	}
+	return nil
+}
+
+// txDoc reads key like getDoc and queues then in the same transaction.
+func (client *Client) txDoc(ctx context.Context, key_str string, then func(pipe goredis.Pipeliner)) (string, error) {
+	pipe := client.client.TxPipeline()
+	read := queueGetDoc(ctx, pipe, key_str)
+	then(pipe)
+	if _, e := pipe.Exec(ctx); e != nil && e != goredis.Nil {
+		return "", e
+	}
+	return read()
+}
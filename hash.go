@@ -0,0 +1,147 @@
+package redis
+
+import (
+	"context"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// HMGetMany reads the same fields from many hashes in one pipeline, in
+// cluster mode go-redis splits it per node. Missing keys and fields are left
+// out of the result.
+func (client *Client) HMGetMany(ctx context.Context, keys []string, fields []string) (map[string]map[string]string, error) {
+	result := make(map[string]map[string]string, len(keys))
+	if len(keys) == 0 || len(fields) == 0 {
+		return result, nil
+	}
+
+	pipe := client.client.Pipeline()
+	cmds := make([]*goredis.SliceCmd, len(keys))
+	for i, key := range keys {
+		cmds[i] = pipe.HMGet(ctx, client.fullKey(key), fields...)
+	}
+	if _, e := pipe.Exec(ctx); e != nil {
+		return nil, client.wrap(e, "RedisHMGetMany", "hmget", "")
+	}
+
+	for i, key := range keys {
+		values := make(map[string]string, len(fields))
+		for j, v := range cmds[i].Val() {
+			if s, ok := v.(string); ok {
+				values[fields[j]] = s
+			}
+		}
+		if len(values) > 0 {
+			result[key] = values
+		}
+	}
+	return result, nil
+}
+
+// writeHash runs fn and refreshes the expiry of the hash in one transaction. A
+// negative ttl leaves the expiry of an existing hash untouched.
+func (client *Client) writeHash(ctx context.Context, key_str string, ttl int, fn func(pipe goredis.Pipeliner)) error {
+	pipe := client.client.TxPipeline()
+	fn(pipe)
+	if expiration := client.expiration(ttl); expiration > 0 {
+		pipe.Expire(ctx, key_str, expiration)
+	}
+	_, e := pipe.Exec(ctx)
+	return e
+}
+
+// HSetStruct stores the fields of v tagged with `redis:"name"` as hash fields,
+// "omitempty" skips zero values. v is a struct or a pointer to one. Fields not
+// present in v keep their value, so partial structs update single fields.
+func (client *Client) HSetStruct(ctx context.Context, key string, v interface{}, ttl int) error {
+	key_str := client.fullKey(key)
+	e := client.writeHash(ctx, key_str, ttl, func(pipe goredis.Pipeliner) {
+		pipe.HSet(ctx, key_str, v)
+	})
+	if e != nil {
+		return client.wrap(e, "RedisHSetStruct", "hset", key)
+	}
+	return nil
+}
+
+// HGetAllStruct scans the hash into the `redis` tagged fields of the struct v
+// points to, ErrNotFound when the hash does not exist.
+func (client *Client) HGetAllStruct(ctx context.Context, key string, v interface{}) error {
+	cmd := client.reader(ctx).HGetAll(ctx, client.fullKey(key))
+	if e := cmd.Err(); e != nil {
+		return client.wrap(e, "RedisHGetAllStruct", "hgetall", key)
+	}
+	if len(cmd.Val()) == 0 {
+		return ErrNotFound
+	}
+	if e := cmd.Scan(v); e != nil {
+		return client.wrap(e, "RedisHGetAllStruct:Scan", "", key)
+	}
+	return nil
+}
+
+// HMGet returns the fields that exist, missing ones are left out of the map.
+func (client *Client) HMGet(ctx context.Context, key string, fields ...string) (map[string]string, error) {
+	values, e := client.reader(ctx).HMGet(ctx, client.fullKey(key), fields...).Result()
+	if e != nil {
+		return nil, client.wrap(e, "RedisHMGet", "hmget", key)
+	}
+	result := make(map[string]string, len(fields))
+	for i, v := range values {
+		if s, ok := v.(string); ok {
+			result[fields[i]] = s
+		}
+	}
+	return result, nil
+}
+
+// HMSet sets all fields in one HSET and refreshes the TTL like HSetStruct.
+func (client *Client) HMSet(ctx context.Context, key string, fields map[string]interface{}, ttl int) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	key_str := client.fullKey(key)
+	e := client.writeHash(ctx, key_str, ttl, func(pipe goredis.Pipeliner) {
+		pipe.HSet(ctx, key_str, fields)
+	})
+	if e != nil {
+		return client.wrap(e, "RedisHMSet", "hset", key)
+	}
+	return nil
+}
+
+// HSetJSON encodes v with the client codec and stores it in a hash field, see
+// HSetStruct for ttl.
+func (client *Client) HSetJSON(ctx context.Context, key, field string, v interface{}, ttl int) error {
+	data_str, e := client.codec.Marshal(v)
+	if e != nil {
+		return client.wrap(e, "RedisHSetJSON:JSONMarshal", "", key)
+	}
+	if e := client.checkSize("RedisHSetJSON", key, data_str); e != nil {
+		return e
+	}
+	key_str := client.fullKey(key)
+	e = client.writeHash(ctx, key_str, ttl, func(pipe goredis.Pipeliner) {
+		pipe.HSet(ctx, key_str, field, data_str)
+	})
+	if e != nil {
+		return client.wrap(e, "RedisHSetJSON", "hset", key)
+	}
+	return nil
+}
+
+// HGetJSON decodes a hash field stored by HSetJSON into v, ErrNotFound when
+// the field does not exist.
+func (client *Client) HGetJSON(ctx context.Context, key, field string, v interface{}) error {
+	data_str, e := client.reader(ctx).HGet(ctx, client.fullKey(key), field).Result()
+	if e != nil {
+		if e == goredis.Nil {
+			return ErrNotFound
+		}
+		return client.wrap(e, "RedisHGetJSON", "hget", key)
+	}
+	if e := client.codec.Unmarshal([]byte(data_str), v); e != nil {
+		return client.wrap(e, "RedisHGetJSON:JSONUnmarshal", "", key)
+	}
+	return nil
+}
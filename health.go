@@ -0,0 +1,43 @@
+package redis
+
+import (
+	"context"
+	"time"
+)
+
+type Health struct {
+	Ready   bool
+	Latency time.Duration // Round trip of a PING
+	Role    string        // master, slave or sentinel as reported by ROLE
+	Pool    ConnStats
+}
+
+func (client *Client) Ping(ctx context.Context) error {
+	if e := client.client.Ping(ctx).Err(); e != nil {
+		return client.wrap(e, "RedisPing", "ping", "")
+	}
+	return nil
+}
+
+// Health pings the server and collects what a readiness probe needs. The
+// returned Health is filled as far as possible even when an error is returned.
+func (client *Client) Health(ctx context.Context) (*Health, error) {
+	h := &Health{Ready: client.IsReady()}
+
+	start := time.Now()
+	e := client.Ping(ctx)
+	h.Latency = time.Since(start)
+	h.Pool = client.ConnStats()
+	if e != nil {
+		return h, e
+	}
+
+	role, e := client.client.Do(ctx, "role").Slice()
+	if e != nil {
+		return h, client.wrap(e, "RedisHealth", "role", "")
+	}
+	if len(role) > 0 {
+		h.Role, _ = role[0].(string)
+	}
+	return h, nil
+}
@@ -0,0 +1,78 @@
+package redis
+
+import (
+	"context"
+	"time"
+)
+
+// Hash field expiration needs Redis 7.4, go-redis does not wrap the commands
+// yet so they are sent with Do. The replies hold one code per field:
+//
+//	-2 the field does not exist
+//	 0 the condition was not met
+//	 1 the expiry was set, or removed by HPersist
+//	 2 the field was deleted right away because ttl was not positive
+
+func hashFieldArgs(cmd, key_str string, arg interface{}, fields []string) []interface{} {
+	args := make([]interface{}, 0, len(fields)+5)
+	args = append(args, cmd, key_str)
+	if arg != nil {
+		args = append(args, arg)
+	}
+	args = append(args, "FIELDS", len(fields))
+	for _, f := range fields {
+		args = append(args, f)
+	}
+	return args
+}
+
+// HExpire sets the ttl of the given fields, with millisecond precision.
+func (client *Client) HExpire(ctx context.Context, key string, ttl time.Duration, fields ...string) ([]int64, error) {
+	args := hashFieldArgs("HPEXPIRE", client.fullKey(key), ttl.Milliseconds(), fields)
+	codes, e := client.client.Do(ctx, args...).Int64Slice()
+	if e != nil {
+		return nil, client.wrap(e, "RedisHExpire", "hpexpire", key)
+	}
+	return codes, nil
+}
+
+// HPersist removes the ttl of the given fields, -1 is returned for fields
+// without one.
+func (client *Client) HPersist(ctx context.Context, key string, fields ...string) ([]int64, error) {
+	codes, e := client.client.Do(ctx, hashFieldArgs("HPERSIST", client.fullKey(key), nil, fields)...).Int64Slice()
+	if e != nil {
+		return nil, client.wrap(e, "RedisHPersist", "hpersist", key)
+	}
+	return codes, nil
+}
+
+// HTTL returns the remaining ttl of each field like TTL does for keys: -1 for
+// fields without expiry and -2 for missing fields.
+func (client *Client) HTTL(ctx context.Context, key string, fields ...string) ([]time.Duration, error) {
+	ms, e := client.client.Do(ctx, hashFieldArgs("HPTTL", client.fullKey(key), nil, fields)...).Int64Slice()
+	if e != nil {
+		return nil, client.wrap(e, "RedisHTTL", "hpttl", key)
+	}
+	ttls := make([]time.Duration, len(ms))
+	for i, v := range ms {
+		if v < 0 {
+			ttls[i] = time.Duration(v)
+		} else {
+			ttls[i] = time.Duration(v) * time.Millisecond
+		}
+	}
+	return ttls, nil
+}
+
+// HSetExField sets a single field that expires after ttl, the other fields
+// and the key itself keep their expiry.
+func (client *Client) HSetExField(ctx context.Context, key, field string, value interface{}, ttl time.Duration) error {
+	key_str := client.fullKey(key)
+	pipe := client.client.TxPipeline()
+	pipe.HSet(ctx, key_str, field, value)
+	pipe.Do(ctx, hashFieldArgs("HPEXPIRE", key_str, ttl.Milliseconds(), []string{field})...)
+	if _, e := pipe.Exec(ctx); e != nil {
+		return client.wrap(e, "RedisHSetExField", "hpexpire", key)
+	}
+	return nil
+}
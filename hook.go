@@ -0,0 +1,147 @@
+package redis
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+type CommandInfo struct {
+	Name     string        // Lower case command name, e.g. "get"
+	Key      string        // First key without the prefix, empty for keyless commands
+	Args     []interface{} // A copy, hooks may keep it
+	Pipeline bool          // Part of a pipeline, Duration is then the one of the whole pipeline
+	Start    time.Time
+	Duration time.Duration // Only set in AfterCommand
+	Err      error         // Only set in AfterCommand, goredis.Nil is reported as nil
+}
+
+// Hook observes every command sent through the client, including the ones
+// issued by Do, pipelines and the helper types. Hooks are attached to the
+// connection pool, so they are shared by all views created with With.
+type Hook interface {
+	BeforeCommand(ctx context.Context, info *CommandInfo) context.Context
+	AfterCommand(ctx context.Context, info *CommandInfo)
+}
+
+type hookList struct {
+	mu    sync.Mutex
+	hooks atomic.Pointer[[]Hook]
+}
+
+// AddHook adds h to the client and all its views, there are no per view
+// hooks: the go-redis hook chain only sees the command, not the view that
+// sent it. A hook that should only observe a view can match CommandInfo.Key
+// against the view's namespace.
+func (client *Client) AddHook(h Hook) {
+	l := client.hooks
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var hooks []Hook
+	if cur := l.hooks.Load(); cur != nil {
+		hooks = append(hooks, *cur...)
+	}
+	hooks = append(hooks, h)
+	l.hooks.Store(&hooks)
+}
+
+type commandHook struct {
+	client *Client
+}
+
+func (h *commandHook) DialHook(next goredis.DialHook) goredis.DialHook {
+	return next
+}
+
+func (h *commandHook) ProcessHook(next goredis.ProcessHook) goredis.ProcessHook {
+	return func(ctx context.Context, cmd goredis.Cmder) error {
+		hooks := h.client.hooks.hooks.Load()
+		if hooks == nil {
+			return next(ctx, cmd)
+		}
+
+		info := h.info(cmd, false)
+		for _, hook := range *hooks {
+			ctx = h.before(hook, ctx, info)
+		}
+		e := next(ctx, cmd)
+		info.Duration = time.Since(info.Start)
+		if e != goredis.Nil {
+			info.Err = e
+		}
+		for i := len(*hooks) - 1; i >= 0; i-- {
+			h.after((*hooks)[i], ctx, info)
+		}
+		return e
+	}
+}
+
+func (h *commandHook) ProcessPipelineHook(next goredis.ProcessPipelineHook) goredis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []goredis.Cmder) error {
+		hooks := h.client.hooks.hooks.Load()
+		if hooks == nil {
+			return next(ctx, cmds)
+		}
+
+		// Every command gets its own context so hook state does not leak between them
+		infos := make([]*CommandInfo, len(cmds))
+		ctxs := make([]context.Context, len(cmds))
+		for i, cmd := range cmds {
+			infos[i] = h.info(cmd, true)
+			ctxs[i] = ctx
+			for _, hook := range *hooks {
+				ctxs[i] = h.before(hook, ctxs[i], infos[i])
+			}
+		}
+		e := next(ctx, cmds)
+		for i, cmd := range cmds {
+			infos[i].Duration = time.Since(infos[i].Start)
+			if ce := cmd.Err(); ce != goredis.Nil {
+				infos[i].Err = ce
+			}
+			for j := len(*hooks) - 1; j >= 0; j-- {
+				h.after((*hooks)[j], ctxs[i], infos[i])
+			}
+		}
+		return e
+	}
+}
+
+// A panicking hook keeps the context it was given and the command still runs.
+func (h *commandHook) before(hook Hook, ctx context.Context, info *CommandInfo) (res context.Context) {
+	res = ctx
+	defer h.client.recoverPanic("hook", nil)
+	return hook.BeforeCommand(ctx, info)
+}
+
+func (h *commandHook) after(hook Hook, ctx context.Context, info *CommandInfo) {
+	defer h.client.recoverPanic("hook", nil)
+	hook.AfterCommand(ctx, info)
+}
+
+func (h *commandHook) info(cmd goredis.Cmder, pipeline bool) *CommandInfo {
+	// Values may be pooled encode buffers that are reused once the command
+	// was written, hooks get their own copy
+	args := append([]interface{}(nil), cmd.Args()...)
+	for i, arg := range args {
+		if b, ok := arg.([]byte); ok {
+			args[i] = append([]byte(nil), b...)
+		}
+	}
+	info := &CommandInfo{
+		Name:     cmd.Name(),
+		Args:     args,
+		Pipeline: pipeline,
+		Start:    time.Now(),
+	}
+	if len(info.Args) > 1 {
+		if s, ok := info.Args[1].(string); ok && strings.HasPrefix(s, h.client.config.Prefix+":") {
+			info.Key = s[len(h.client.config.Prefix)+1:]
+		}
+	}
+	return info
+}
@@ -0,0 +1,67 @@
+package redis
+
+import (
+	"context"
+)
+
+func (client *Client) PFAdd(ctx context.Context, key string, members ...interface{}) (bool, error) {
+	key_str := client.fullKey(key)
+	n, e := client.client.PFAdd(ctx, key_str, members...).Result()
+	if e != nil {
+		return false, client.wrap(e, "RedisPFAdd", "pfadd", key)
+	}
+	return n == 1, nil
+}
+
+// PFCount returns the approximate union cardinality of the keys, which must
+// share a hash slot on a cluster.
+func (client *Client) PFCount(ctx context.Context, keys ...string) (int64, error) {
+	if e := client.checkSlots(keys...); e != nil {
+		return 0, client.wrap(e, "RedisPFCount", "pfcount", firstKey(keys))
+	}
+	key_strs := make([]string, len(keys))
+	for i, key := range keys {
+		key_strs[i] = client.fullKey(key)
+	}
+	n, e := client.client.PFCount(ctx, key_strs...).Result()
+	if e != nil {
+		return 0, client.wrap(e, "RedisPFCount", "pfcount", firstKey(keys))
+	}
+	return n, nil
+}
+
+func (client *Client) PFMerge(ctx context.Context, dest string, keys ...string) error {
+	if e := client.checkSlots(append([]string{dest}, keys...)...); e != nil {
+		return client.wrap(e, "RedisPFMerge", "pfmerge", dest)
+	}
+	key_strs := make([]string, len(keys))
+	for i, key := range keys {
+		key_strs[i] = client.fullKey(key)
+	}
+	if e := client.client.PFMerge(ctx, client.fullKey(dest), key_strs...).Err(); e != nil {
+		return client.wrap(e, "RedisPFMerge", "pfmerge", dest)
+	}
+	return nil
+}
+
+// CountUniques adds the members and returns the new approximate count in a
+// single round trip.
+func (client *Client) CountUniques(ctx context.Context, key string, members ...interface{}) (int64, error) {
+	key_str := client.fullKey(key)
+	pipe := client.client.Pipeline()
+	if len(members) > 0 {
+		pipe.PFAdd(ctx, key_str, members...)
+	}
+	count := pipe.PFCount(ctx, key_str)
+	if _, e := pipe.Exec(ctx); e != nil {
+		return 0, client.wrap(e, "RedisCountUniques", "pfcount", key)
+	}
+	return count.Val(), nil
+}
+
+func firstKey(keys []string) string {
+	if len(keys) == 0 {
+		return ""
+	}
+	return keys[0]
+}
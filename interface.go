@@ -0,0 +1,257 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Store is the key value subset of Client that other backends, e.g. a
+// memcached shim or an in-memory map, can provide as well. Depend on it rather
+// than on *Client where the key value calls are enough. Ttls are in seconds, 0
+// means the default ttl if any, and a miss is reported as ErrNotFound.
+type Store interface {
+	Get(ctx context.Context, key string, v interface{}) error
+	GetStr(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, v interface{}, ttl int) error
+	SetStr(ctx context.Context, key string, v string, ttl int) error
+	SetNX(ctx context.Context, key string, v interface{}, ttl int) (bool, error)
+	Del(ctx context.Context, key string) error
+	Incr(ctx context.Context, key string) (int64, error)
+	Decr(ctx context.Context, key string) (int64, error)
+}
+
+// ClientInterface is the set of command wrappers of Client, for code that
+// wants to swap the client in tests, see the redistest package. Setup methods
+// and the components built on a client, such as Leaderboard or Lease, are left
+// out as they are bound to *Client.
+type ClientInterface interface {
+	Store
+
+	// Strings and values
+	SetFor(ctx context.Context, key string, v interface{}, ttl time.Duration) error
+	SetEx(ctx context.Context, key string, v interface{}, ttl int) (string, error)
+	SetExFor(ctx context.Context, key string, v interface{}, ttl time.Duration) (string, error)
+	SetNXFor(ctx context.Context, key string, v interface{}, ttl time.Duration) (bool, error)
+	SetNXEx(ctx context.Context, key string, v interface{}, ttl int) (bool, string, error)
+	SetNXExFor(ctx context.Context, key string, v interface{}, ttl time.Duration) (bool, string, error)
+	SetXX(ctx context.Context, key string, v interface{}, ttl int) (bool, error)
+	SetXXFor(ctx context.Context, key string, v interface{}, ttl time.Duration) (bool, error)
+	SetIfEquals(ctx context.Context, key string, expected, v interface{}, ttl int) (bool, error)
+	SetStrIfEquals(ctx context.Context, key, expected, v string, ttl int) (bool, error)
+	DelIfEquals(ctx context.Context, key string, expected interface{}) (bool, error)
+	DelStrIfEquals(ctx context.Context, key, expected string) (bool, error)
+	SetStrFor(ctx context.Context, key string, v string, ttl time.Duration) error
+	SetNXStr(ctx context.Context, key string, v string, ttl int) (bool, error)
+	SetNXStrFor(ctx context.Context, key string, v string, ttl time.Duration) (bool, error)
+	SetBytes(ctx context.Context, key string, data []byte, ttl int) error
+	SetBytesFor(ctx context.Context, key string, data []byte, ttl time.Duration) error
+	GetBytes(ctx context.Context, key string, dst []byte) ([]byte, error)
+	Append(ctx context.Context, key, value string) (int64, error)
+	StrLen(ctx context.Context, key string) (int64, error)
+	SetRange(ctx context.Context, key string, offset int64, value string) (int64, error)
+	GetRange(ctx context.Context, key string, start, end int64) (string, error)
+	TrySet(ctx context.Context, key string, v interface{}, ttl int) (*SetNXResult, error)
+	TrySetFor(ctx context.Context, key string, v interface{}, ttl time.Duration) (*SetNXResult, error)
+	TrySetGet(ctx context.Context, key string, v interface{}, ttl int) (*SetNXResult, error)
+	TrySetGetFor(ctx context.Context, key string, v interface{}, ttl time.Duration) (*SetNXResult, error)
+	TrySetStr(ctx context.Context, key string, v string, ttl int) (*SetNXResult, error)
+	TrySetStrFor(ctx context.Context, key string, v string, ttl time.Duration) (*SetNXResult, error)
+	TrySetStrGet(ctx context.Context, key string, v string, ttl int) (*SetNXResult, error)
+	TrySetStrGetFor(ctx context.Context, key string, v string, ttl time.Duration) (*SetNXResult, error)
+	GetSet(ctx context.Context, key string, v interface{}, ttl int, old interface{}) error
+	GetSetFor(ctx context.Context, key string, v interface{}, ttl time.Duration, old interface{}) error
+	GetDel(ctx context.Context, key string, v interface{}) error
+	GetEx(ctx context.Context, key string, v interface{}, ttl int) error
+	GetExFor(ctx context.Context, key string, v interface{}, ttl time.Duration) error
+	GetWithMeta(ctx context.Context, key string, v interface{}) (found bool, ttl time.Duration, size int, err error)
+	GetMulti(ctx context.Context, keys []string, dest interface{}) ([]string, error)
+	LoadMulti(ctx context.Context, keys []string, dest interface{}, ttl int, loader func(ctx context.Context, missing []string) (map[string]interface{}, error)) error
+	SetNotFound(ctx context.Context, key string, ttl int) error
+	GetOrLoad(ctx context.Context, key string, v interface{}, ttl int, load func(ctx context.Context) (interface{}, error)) error
+	SetSoft(ctx context.Context, key string, v interface{}, softTTL, ttl int) error
+	GetSoft(ctx context.Context, key string, v interface{}) (*SoftMeta, error)
+	SetTagged(ctx context.Context, key string, v interface{}, ttl int) error
+	GetTagged(ctx context.Context, key string, v interface{}) (*TaggedValue, error)
+	Conflicts(ctx context.Context, key string, window time.Duration) ([]TaggedValue, error)
+	ResolveConflict(ctx context.Context, key string, window time.Duration, ttl int, resolve ConflictResolver) (bool, error)
+	Update(ctx context.Context, key string, ttl int, fn func(current []byte) ([]byte, error)) error
+	UpdateValue(ctx context.Context, key string, ttl int, v interface{}, fn func(found bool) error) error
+	IncrEx(ctx context.Context, key string, ttl int) (int64, error)
+	DecrEx(ctx context.Context, key string, ttl int) (int64, error)
+
+	// Keys
+	Exists(ctx context.Context, keys ...string) (int64, error)
+	Type(ctx context.Context, key string) (string, error)
+	Rename(ctx context.Context, key, newKey string) error
+	RenameNX(ctx context.Context, key, newKey string) (bool, error)
+	TTL(ctx context.Context, key string) (time.Duration, error)
+	Persist(ctx context.Context, key string) (bool, error)
+	Expire(ctx context.Context, key string, ttl time.Duration) error
+	ExpireAt(ctx context.Context, key string, t time.Time) error
+	ExpireIf(ctx context.Context, key string, ttl time.Duration, cond ExpireCondition) (bool, error)
+	ExpireNX(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	ExpireXX(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	ExpireGT(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	ExpireLT(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	ScanKeys(ctx context.Context, pattern string, count int64, fn func(key string) error) error
+	LookupKey(ctx context.Context, key string) (*KeyLookup, error)
+	DependsOn(ctx context.Context, derived string, ttl int, sources ...string) error
+	RemoveDependency(ctx context.Context, derived string, sources ...string) error
+	Dependents(ctx context.Context, key string) ([]string, error)
+	InvalidateCascade(ctx context.Context, key string, maxDepth int) ([]string, error)
+
+	// Hashes
+	HGetAll(ctx context.Context, key string) (map[string]string, error)
+	HGetAllInto(ctx context.Context, key string, dst map[string]string) error
+	HGetAllStruct(ctx context.Context, key string, v interface{}) error
+	HSetStruct(ctx context.Context, key string, v interface{}, ttl int) error
+	HMGet(ctx context.Context, key string, fields ...string) (map[string]string, error)
+	HMGetMany(ctx context.Context, keys []string, fields []string) (map[string]map[string]string, error)
+	HMSet(ctx context.Context, key string, fields map[string]interface{}, ttl int) error
+	HSetJSON(ctx context.Context, key, field string, v interface{}, ttl int) error
+	HGetJSON(ctx context.Context, key, field string, v interface{}) error
+	HScan(ctx context.Context, key, pattern string, count int64, fn func(field, value string) error) error
+	HScanAll(ctx context.Context, key string, count int64) (map[string]string, error)
+	HScanChan(ctx context.Context, key, pattern string, count int64) (<-chan HashEntry, <-chan error)
+	HExpire(ctx context.Context, key string, ttl time.Duration, fields ...string) ([]int64, error)
+	HPersist(ctx context.Context, key string, fields ...string) ([]int64, error)
+	HTTL(ctx context.Context, key string, fields ...string) ([]time.Duration, error)
+	HSetExField(ctx context.Context, key, field string, value interface{}, ttl time.Duration) error
+
+	// Sets
+	SAdd(ctx context.Context, key string, members ...interface{}) error
+	SRem(ctx context.Context, key string, members ...interface{}) error
+	SCard(ctx context.Context, key string) int64
+	SIsMember(ctx context.Context, key string, member interface{}) (bool, error)
+	SMIsMember(ctx context.Context, key string, members ...interface{}) ([]bool, error)
+	SMembers(ctx context.Context, key string) []string
+	SMembersInto(ctx context.Context, key string, dst []string) ([]string, error)
+	SScan(ctx context.Context, key, pattern string, count int64, fn func(member string) error) error
+	SScanAll(ctx context.Context, key string, count int64) ([]string, error)
+	SScanChan(ctx context.Context, key, pattern string, count int64) (<-chan string, <-chan error)
+	SPop(ctx context.Context, key string) (string, error)
+	SPopN(ctx context.Context, key string, count int64) ([]string, error)
+	SRandMember(ctx context.Context, key string) (string, error)
+	SRandMembers(ctx context.Context, key string, count int64) ([]string, error)
+	SMove(ctx context.Context, src, dest string, member interface{}) (bool, error)
+	SDiff(ctx context.Context, keys ...string) ([]string, error)
+	SInter(ctx context.Context, keys ...string) ([]string, error)
+	SUnion(ctx context.Context, keys ...string) ([]string, error)
+	SDiffStore(ctx context.Context, dest string, ttl int, keys ...string) (int64, error)
+	SInterStore(ctx context.Context, dest string, ttl int, keys ...string) (int64, error)
+	SUnionStore(ctx context.Context, dest string, ttl int, keys ...string) (int64, error)
+	SAddJSON(ctx context.Context, key string, members ...interface{}) error
+	SRemJSON(ctx context.Context, key string, members ...interface{}) error
+	SIsMemberJSON(ctx context.Context, key string, member interface{}) (bool, error)
+	SMembersJSON(ctx context.Context, key string, dest interface{}) error
+	CachedSInter(ctx context.Context, ttl int, keys ...string) ([]string, error)
+
+	// Sorted sets
+	ZAddMember(ctx context.Context, key string, member interface{}, score float64) error
+	ZIncrBy(ctx context.Context, key string, member string, incr float64) (float64, error)
+	ZRem(ctx context.Context, key string, members ...interface{}) error
+	ZRemRangeByRank(ctx context.Context, key string, start, stop int64) (int64, error)
+	ZRemRangeByScore(ctx context.Context, key string, min, max string) (int64, error)
+	ZRangeWithScores(ctx context.Context, key string, start, stop int64) ([]goredis.Z, error)
+	ZRangeWithScoresInto(ctx context.Context, key string, start, stop int64, dst []goredis.Z) ([]goredis.Z, error)
+	ZRangeScoresInto(ctx context.Context, key string, start, stop int64, members []string, scores []float64) ([]string, []float64, error)
+	ZRevRangeWithScores(ctx context.Context, key string, start, stop int64) ([]goredis.Z, error)
+	ZRangeByScore(ctx context.Context, key string, opt *goredis.ZRangeBy) ([]string, error)
+	CachedZRangeByScore(ctx context.Context, key string, opt *goredis.ZRangeBy, ttl int) ([]string, error)
+	ZScore(ctx context.Context, key string, member string) (float64, error)
+	ZRank(ctx context.Context, key string, member string) (int64, error)
+	ZRevRank(ctx context.Context, key string, member string) (int64, error)
+	ZCard(ctx context.Context, key string) (int64, error)
+	ZCount(ctx context.Context, key string, min, max string) (int64, error)
+	ZPopMin(ctx context.Context, key string, count int64) ([]goredis.Z, error)
+	ZPopMax(ctx context.Context, key string, count int64) ([]goredis.Z, error)
+	Sort(ctx context.Context, key string, opts *SortOptions) ([]string, error)
+	SortStore(ctx context.Context, key, dest string, opts *SortOptions) (int64, error)
+
+	// Blocking pops
+	BLPop(ctx context.Context, timeout time.Duration, keys ...string) (string, string, error)
+	BRPop(ctx context.Context, timeout time.Duration, keys ...string) (string, string, error)
+	BZPopMin(ctx context.Context, timeout time.Duration, keys ...string) (string, goredis.Z, error)
+	BZPopMax(ctx context.Context, timeout time.Duration, keys ...string) (string, goredis.Z, error)
+
+	// Bitmaps, HyperLogLog and geo
+	SetBit(ctx context.Context, key string, offset int64, value bool) (bool, error)
+	GetBit(ctx context.Context, key string, offset int64) (bool, error)
+	BitCount(ctx context.Context, key string, byteRange ...int64) (int64, error)
+	BitPos(ctx context.Context, key string, value bool, byteRange ...int64) (int64, error)
+	BitOp(ctx context.Context, op BitOperation, dest string, keys ...string) (int64, error)
+	PFAdd(ctx context.Context, key string, members ...interface{}) (bool, error)
+	PFCount(ctx context.Context, keys ...string) (int64, error)
+	PFMerge(ctx context.Context, dest string, keys ...string) error
+	CountUniques(ctx context.Context, key string, members ...interface{}) (int64, error)
+	GeoAdd(ctx context.Context, key string, points ...GeoPoint) (int64, error)
+	GeoSearch(ctx context.Context, key string, q *GeoQuery) ([]GeoMatch, error)
+	GeoRadius(ctx context.Context, key string, longitude, latitude, radius float64, unit string, count int) ([]GeoMatch, error)
+	GeoDist(ctx context.Context, key string, member1, member2, unit string) (float64, error)
+	GeoPos(ctx context.Context, key string, members ...string) ([]*GeoPoint, error)
+
+	// Modules
+	JSONSet(ctx context.Context, key, path string, v interface{}) error
+	JSONGet(ctx context.Context, key, path string, v interface{}) error
+	JSONMGet(ctx context.Context, path string, keys ...string) ([]json.RawMessage, error)
+	JSONDel(ctx context.Context, key, path string) (int64, error)
+	CFReserve(ctx context.Context, key string, capacity int64) error
+	CFAdd(ctx context.Context, key string, item interface{}) error
+	CFAddNX(ctx context.Context, key string, item interface{}) (bool, error)
+	CFExists(ctx context.Context, key string, item interface{}) (bool, error)
+	CFDel(ctx context.Context, key string, item interface{}) (bool, error)
+	CMSInit(ctx context.Context, key string, errorRate, probability float64) error
+	CMSIncrBy(ctx context.Context, key string, increments map[string]int64) (map[string]int64, error)
+	CMSQuery(ctx context.Context, key string, items ...string) ([]int64, error)
+	TopKReserve(ctx context.Context, key string, k int64) error
+	TopKAdd(ctx context.Context, key string, items ...string) ([]string, error)
+	TopKList(ctx context.Context, key string) ([]string, error)
+	TopKListWithCount(ctx context.Context, key string) (map[string]int64, error)
+	TDigestCreate(ctx context.Context, key string) error
+	TDigestAdd(ctx context.Context, key string, values ...float64) error
+	TDigestQuantile(ctx context.Context, key string, quantiles ...float64) ([]float64, error)
+	TSCreate(ctx context.Context, key string, retention time.Duration, labels map[string]string) error
+	TSAdd(ctx context.Context, key string, at time.Time, value float64) error
+	TSRange(ctx context.Context, key string, from, to time.Time, agg *TSAggregation) ([]TSSample, error)
+	TSMRange(ctx context.Context, from, to time.Time, filters []string, agg *TSAggregation) (map[string]*TSSeries, error)
+
+	// Messaging and blobs
+	Publish(ctx context.Context, channel string, v interface{}) error
+	PublishEnvelope(ctx context.Context, channel, subject string, v interface{}) error
+	OpenEnvelope(ctx context.Context, data []byte, v interface{}) (*Envelope, error)
+	ProduceEnvelope(ctx context.Context, stream, subject string, v interface{}) (string, error)
+	DecodePayload(env *Envelope, v interface{}) error
+	SubscribeKeyEvents(ctx context.Context, pattern string, handler func(KeyEvent)) error
+	Produce(ctx context.Context, stream string, payload []byte) (string, error)
+	Enqueue(ctx context.Context, topic string, payload []byte, delay time.Duration) (string, error)
+	DelayedCount(ctx context.Context, topic string) (int64, error)
+	PutBlob(ctx context.Context, data []byte, ttl int) (string, error)
+	GetBlob(ctx context.Context, id string) ([]byte, error)
+	DelBlob(ctx context.Context, id string) error
+	OffloadPayload(ctx context.Context, payload []byte, ttl int) ([]byte, error)
+	ResolvePayload(ctx context.Context, payload []byte) ([]byte, error)
+	ReleasePayload(ctx context.Context, payload []byte) error
+
+	// Scripts and raw commands
+	Do(ctx context.Context, args ...interface{}) *goredis.Cmd
+	CachedEval(ctx context.Context, script string, keys []string, ttl int, v interface{}, args ...interface{}) error
+	FunctionLoad(ctx context.Context, code string) error
+	FunctionDelete(ctx context.Context, library string) error
+	FCall(ctx context.Context, function string, keys []string, args ...interface{}) *goredis.Cmd
+	FCallRO(ctx context.Context, function string, keys []string, args ...interface{}) *goredis.Cmd
+
+	// Status
+	Ping(ctx context.Context) error
+	Health(ctx context.Context) (*Health, error)
+	IsReady() bool
+	WaitReady(ctx context.Context) error
+	Close() error
+}
+
+var (
+	_ Store           = (*Client)(nil)
+	_ ClientInterface = (*Client)(nil)
+)
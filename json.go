@@ -0,0 +1,310 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// JSON.* commands need the RedisJSON module. Paths default to the root, a
+// JSONPath starting with $ matches any number of values.
+
+func jsonPath(path string) string {
+	if path == "" {
+		return "$"
+	}
+	return path
+}
+
+func (client *Client) JSONSet(ctx context.Context, key, path string, v interface{}) error {
+	key_str := client.fullKey(key)
+	data_str, e := json.Marshal(v)
+	if e != nil {
+		return client.wrap(e, "RedisJSONSet:JSONMarshal", "", key)
+	}
+	if e := client.client.JSONSet(ctx, key_str, jsonPath(path), string(data_str)).Err(); e != nil {
+		return client.wrap(e, "RedisJSONSet", "json.set", key)
+	}
+	client.invalidateLocal(ctx, key_str)
+	return nil
+}
+
+// JSONGet decodes the value at path into v, the first match for a JSONPath.
+func (client *Client) JSONGet(ctx context.Context, key, path string, v interface{}) error {
+	key_str := client.fullKey(key)
+	path = jsonPath(path)
+	data_str, e := client.client.JSONGet(ctx, key_str, path).Result()
+	if e != nil {
+		if e == goredis.Nil {
+			return ErrNotFound
+		}
+		return client.wrap(e, "RedisJSONGet", "json.get", key)
+	}
+	if data_str == "" {
+		return ErrNotFound
+	}
+	raw, ok := firstJSONMatch(path, data_str)
+	if !ok {
+		return ErrNotFound
+	}
+	if e := json.Unmarshal(raw, v); e != nil {
+		return client.wrap(e, "RedisJSONGet:JSONUnmarshal", "", key)
+	}
+	return nil
+}
+
+// JSONMGet returns the raw value at path of every key, nil for the keys that
+// are missing or have no match. The keys must share a hash slot on a cluster.
+func (client *Client) JSONMGet(ctx context.Context, path string, keys ...string) ([]json.RawMessage, error) {
+	if e := client.checkSlots(keys...); e != nil {
+		return nil, client.wrap(e, "RedisJSONMGet", "json.mget", firstKey(keys))
+	}
+	key_strs := make([]string, len(keys))
+	for i, key := range keys {
+		key_strs[i] = client.fullKey(key)
+	}
+	path = jsonPath(path)
+	vals, e := client.client.JSONMGet(ctx, path, key_strs...).Result()
+	if e != nil && e != goredis.Nil {
+		return nil, client.wrap(e, "RedisJSONMGet", "json.mget", firstKey(keys))
+	}
+	res := make([]json.RawMessage, len(keys))
+	for i := range res {
+		if i >= len(vals) {
+			break
+		}
+		if s, ok := vals[i].(string); ok && s != "" {
+			if raw, ok := firstJSONMatch(path, s); ok {
+				res[i] = raw
+			}
+		}
+	}
+	return res, nil
+}
+
+// JSONDel deletes the values at path and returns how many were deleted.
+func (client *Client) JSONDel(ctx context.Context, key, path string) (int64, error) {
+	key_str := client.fullKey(key)
+	n, e := client.client.JSONDel(ctx, key_str, jsonPath(path)).Result()
+	if e != nil {
+		return 0, client.wrap(e, "RedisJSONDel", "json.del", key)
+	}
+	client.invalidateLocal(ctx, key_str)
+	return n, nil
+}
+
+// JSONPath replies are arrays of matches, legacy paths reply the value itself.
+func firstJSONMatch(path, data_str string) (json.RawMessage, bool) {
+	if !strings.HasPrefix(path, "$") {
+		return json.RawMessage(data_str), true
+	}
+	var matches []json.RawMessage
+	if e := json.Unmarshal([]byte(data_str), &matches); e != nil || len(matches) == 0 {
+		return nil, false
+	}
+	return matches[0], true
+}
+
+type moduleInfo struct {
+	mu    sync.Mutex
+	names map[string]bool // nil until MODULE LIST was answered
+
+	missing sync.Map // Commands the server rejected as unknown
+}
+
+func (m *moduleInfo) unsupported(cmd string) bool {
+	_, ok := m.missing.Load(cmd)
+	return ok
+}
+
+func (m *moduleInfo) markUnsupported(cmd string) {
+	m.missing.Store(cmd, true)
+}
+
+func isUnknownCommand(e error) bool {
+	return e != nil && strings.HasPrefix(e.Error(), "ERR unknown command")
+}
+
+// hasModule reports whether the server has loaded the module. MODULE LIST is
+// read once per client, a failed read is retried by the next call. A server
+// without MODULE LIST has no modules.
+func (client *Client) hasModule(ctx context.Context, name string) bool {
+	m := client.modules
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.names == nil {
+		modules, e := client.moduleList(ctx)
+		if e != nil && !isUnknownCommand(e) {
+			return false
+		}
+		m.names = make(map[string]bool, len(modules))
+		for _, module := range modules {
+			m.names[strings.ToLower(module)] = true
+		}
+	}
+	return m.names[strings.ToLower(name)]
+}
+
+// The setters of codec values store RedisJSON documents instead of strings
+// when Config.RedisJSON is set and the module is loaded, the value reads
+// accept both. The codec must produce JSON then.
+func (client *Client) jsonDocs(ctx context.Context) bool {
+	return client.config.RedisJSON && client.hasModule(ctx, "ReJSON")
+}
+
+// Documents share the key space with plain strings such as SetStr values,
+// SetSoft values and cached misses, so reads pick the command by the type.
+// Missing keys are nil.
+var docGetScript = NewScript(`
+local res = {}
+for i, key in ipairs(KEYS) do
+	local t = redis.call('TYPE', key).ok
+	if t == 'ReJSON-RL' then
+		res[i] = redis.call('JSON.GET', key, '.')
+	elseif t == 'string' then
+		res[i] = redis.call('GET', key)
+	else
+		res[i] = false
+	end
+end
+return res
+`)
+
+// docSetScript stores a document like SET does, ARGV are the document, NX, XX
+// or empty, the ttl in milliseconds with -1 to keep it and 0 to persist the
+// key, and 1 to also return the previous value. A string in the way is
+// replaced unless NX is given.
+var docSetScript = NewScript(`
+local t = redis.call('TYPE', KEYS[1]).ok
+local prev = false
+if ARGV[4] == '1' then
+	if t == 'ReJSON-RL' then
+		prev = redis.call('JSON.GET', KEYS[1], '.')
+	elseif t == 'string' then
+		prev = redis.call('GET', KEYS[1])
+	end
+end
+local mode, ms = ARGV[2], tonumber(ARGV[3])
+if t ~= 'none' and t ~= 'ReJSON-RL' then
+	if mode == 'NX' then
+		return {0, prev}
+	end
+	if ms == -1 then
+		ms = redis.call('PTTL', KEYS[1])
+	end
+	redis.call('DEL', KEYS[1])
+	mode = ''
+end
+local ok
+if mode == '' then
+	ok = redis.call('JSON.SET', KEYS[1], '$', ARGV[1])
+else
+	ok = redis.call('JSON.SET', KEYS[1], '$', ARGV[1], mode)
+end
+if not ok then
+	return {0, prev}
+end
+if ms > 0 then
+	redis.call('PEXPIRE', KEYS[1], ms)
+elseif ms == 0 then
+	redis.call('PERSIST', KEYS[1])
+end
+return {1, prev}
+`)
+
+func docTTL(expiration time.Duration) int64 {
+	if expiration == goredis.KeepTTL {
+		return -1
+	}
+	if expiration <= 0 {
+		return 0
+	}
+	return max(expiration.Milliseconds(), 1)
+}
+
+// getDoc reads key like GET does, a document or a string.
+func (client *Client) getDoc(ctx context.Context, key_str string) *goredis.StringCmd {
+	cmd := goredis.NewStringCmd(ctx, "json.get", key_str, ".")
+	data_str, e := readDoc(ctx, client.client, key_str)
+	cmd.SetVal(data_str)
+	cmd.SetErr(e)
+	return cmd
+}
+
+func readDoc(ctx context.Context, c goredis.Scripter, key_str string) (string, error) {
+	vals, e := docGetScript.runFull(ctx, c, []string{key_str}).Slice()
+	if e != nil {
+		return "", e
+	}
+	data_str, ok := firstValue(vals)
+	if !ok {
+		return "", goredis.Nil
+	}
+	return data_str, nil
+}
+
+// queueGetDoc queues the read of getDoc on a pipeline.
+func queueGetDoc(ctx context.Context, pipe goredis.Pipeliner, key_str string) func() (string, error) {
+	cmd := pipe.Eval(ctx, docGetScript.src, []string{key_str})
+	return func() (string, error) {
+		vals, e := cmd.Slice()
+		if e != nil {
+			return "", e
+		}
+		data_str, ok := firstValue(vals)
+		if !ok {
+			return "", goredis.Nil
+		}
+		return data_str, nil
+	}
+}
+
+func firstValue(vals []interface{}) (string, bool) {
+	if len(vals) == 0 {
+		return "", false
+	}
+	data_str, ok := vals[0].(string)
+	return data_str, ok
+}
+
+type docReply struct {
+	set   bool
+	prev  string
+	found bool // A previous value was asked for and exists
+}
+
+// setDoc stores a document in place of whatever key holds, mode is NX, XX or
+// empty.
+func (client *Client) setDoc(ctx context.Context, key_str, data_str, mode string, expiration time.Duration, get bool) (docReply, error) {
+	vals, e := docSetScript.runFull(ctx, client.client, []string{key_str}, docSetArgs(data_str, mode, expiration, get)...).Slice()
+	if e != nil {
+		return docReply{}, e
+	}
+	var r docReply
+	if len(vals) > 0 {
+		n, _ := vals[0].(int64)
+		r.set = n == 1
+	}
+	if len(vals) > 1 {
+		r.prev, r.found = vals[1].(string)
+	}
+	return r, nil
+}
+
+// queueSetDoc queues an unconditional setDoc on a pipeline, scripts are sent
+// as source there as NOSCRIPT can not be recovered from inside a transaction.
+func queueSetDoc(ctx context.Context, pipe goredis.Pipeliner, key_str, data_str string, expiration time.Duration) {
+	pipe.Eval(ctx, docSetScript.src, []string{key_str}, docSetArgs(data_str, "", expiration, false)...)
+}
+
+func docSetArgs(data_str, mode string, expiration time.Duration, get bool) []interface{} {
+	get_str := "0"
+	if get {
+		get_str = "1"
+	}
+	return []interface{}{data_str, mode, docTTL(expiration), get_str}
+}
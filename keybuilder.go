@@ -0,0 +1,80 @@
+package redis
+
+import (
+	"fmt"
+	"strings"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// KeyBuilder builds keys sharing one hash tag, so that they land in the same
+// cluster slot and can be used together by multi-key commands, transactions
+// and scripts:
+//
+//	user := redis.KeyTag("user", "123")
+//	user.Key("profile")  // {user:123}:profile
+//	user.Key("sessions") // {user:123}:sessions
+//
+// Only the first {...} of a key counts, parts of Key must not contain braces.
+type KeyBuilder struct {
+	tag string
+}
+
+// KeyTag joins parts with ':' into the hash tag of the builder.
+func KeyTag(parts ...string) KeyBuilder {
+	return KeyBuilder{tag: strings.Join(parts, ":")}
+}
+
+func (b KeyBuilder) Tag() string {
+	return b.tag
+}
+
+// Key returns {tag}:part1:part2..., just {tag} without parts.
+func (b KeyBuilder) Key(parts ...string) string {
+	if len(parts) == 0 {
+		return "{" + b.tag + "}"
+	}
+	return "{" + b.tag + "}:" + strings.Join(parts, ":")
+}
+
+// Slot returns the cluster slot of a full key, hashing its tag if it has one.
+func Slot(key string) int {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+	return int(crc16(key) % 16384)
+}
+
+// crc16 is the CRC-16/XMODEM of the cluster specification.
+func crc16(s string) uint16 {
+	var crc uint16
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// checkSlots fails with ErrCrossSlot when the keys of a multi-key command do
+// not share a slot in cluster mode, instead of the bare CROSSSLOT reply.
+func (client *Client) checkSlots(keys ...string) error {
+	if _, ok := client.client.(*goredis.ClusterClient); !ok || len(keys) < 2 {
+		return nil
+	}
+	slot := Slot(client.fullKey(keys[0]))
+	for _, key := range keys[1:] {
+		if s := Slot(client.fullKey(key)); s != slot {
+			return fmt.Errorf("%w: %s is in slot %d, %s in slot %d, use a common hash tag, see KeyTag",
+				ErrCrossSlot, client.logKey(keys[0]), slot, client.logKey(key), s)
+		}
+	}
+	return nil
+}
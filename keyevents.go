@@ -0,0 +1,167 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+type KeyEvent struct {
+	Event string // e.g. set, del, expire, expired or evicted
+	Key   string // Without the prefix
+}
+
+var ErrKeyEventsDisabled = errors.New("redis: notify-keyspace-events lacks flags and can not be changed")
+
+// Flags SubscribeKeyEvents needs in notify-keyspace-events: keyevent channels,
+// generic commands, strings, expired and evicted keys.
+const keyEventFlags = "Eg$xe"
+
+// SubscribeKeyEvents calls handler for the key events of keys under the prefix
+// matching the glob pattern, until ctx is done or the client is closed. It
+// enables the missing notify-keyspace-events flags with CONFIG SET and fails
+// with ErrKeyEventsDisabled where CONFIG is not allowed. In cluster mode every
+// master is subscribed, notifications are local to a node.
+//
+// Notifications are fire and forget, events that happen while the connection
+// is down are lost.
+func (client *Client) SubscribeKeyEvents(ctx context.Context, pattern string, handler func(KeyEvent)) error {
+	if pattern == "" {
+		pattern = "*"
+	}
+	match, e := globRegexp(pattern)
+	if e != nil {
+		return e
+	}
+	nodes := []goredis.UniversalClient{client.client}
+	if cc, ok := client.client.(*goredis.ClusterClient); ok {
+		nodes = nodes[:0]
+		var mu sync.Mutex
+		e := cc.ForEachMaster(ctx, func(ctx context.Context, node *goredis.Client) error {
+			mu.Lock()
+			nodes = append(nodes, node)
+			mu.Unlock()
+			return nil
+		})
+		if e != nil {
+			return client.wrap(e, "RedisSubscribeKeyEvents", "", pattern)
+		}
+	}
+	for _, node := range nodes {
+		if e := client.enableKeyEvents(ctx, node); e != nil {
+			return e
+		}
+	}
+
+	lc_ctx, cancel := context.WithCancel(ctx)
+	context.AfterFunc(client.lifecycle.ctx, cancel)
+	channel := fmt.Sprintf("__keyevent@%d__:*", client.config.DB)
+	own := client.config.Prefix + ":"
+	for _, node := range nodes {
+		sub := node.PSubscribe(lc_ctx, channel)
+		if _, e := sub.Receive(lc_ctx); e != nil {
+			sub.Close()
+			cancel()
+			return client.wrap(e, "RedisSubscribeKeyEvents", "psubscribe", pattern)
+		}
+		go func() {
+			defer sub.Close()
+			ch := sub.Channel()
+			for {
+				select {
+				case <-lc_ctx.Done():
+					return
+				case msg, ok := <-ch:
+					if !ok {
+						return
+					}
+					if !strings.HasPrefix(msg.Payload, own) {
+						continue
+					}
+					key := msg.Payload[len(own):]
+					if match.MatchString(key) {
+						client.handleKeyEvent(handler, KeyEvent{Event: msg.Channel[strings.LastIndexByte(msg.Channel, ':')+1:], Key: key})
+					}
+				}
+			}
+		}()
+	}
+	return nil
+}
+
+func (client *Client) handleKeyEvent(handler func(KeyEvent), ev KeyEvent) {
+	defer client.recoverPanic("keyevents", nil)
+	handler(ev)
+}
+
+func (client *Client) enableKeyEvents(ctx context.Context, node goredis.UniversalClient) error {
+	if e := client.enableNotifyFlags(ctx, node, keyEventFlags); e != nil {
+		return client.wrap(e, "RedisSubscribeKeyEvents", "config", "")
+	}
+	return nil
+}
+
+// enableNotifyFlags adds the flags missing in notify-keyspace-events, A
+// covers every class flag but neither K nor E.
+func (client *Client) enableNotifyFlags(ctx context.Context, node goredis.UniversalClient, want string) error {
+	cfg, e := node.ConfigGet(ctx, "notify-keyspace-events").Result()
+	if e != nil {
+		return ErrKeyEventsDisabled
+	}
+	flags := cfg["notify-keyspace-events"]
+	missing := ""
+	for _, c := range want {
+		if !strings.ContainsRune(flags, c) && (c == 'E' || c == 'K' || !strings.ContainsRune(flags, 'A')) {
+			missing += string(c)
+		}
+	}
+	if missing == "" {
+		return nil
+	}
+	if e := node.ConfigSet(ctx, "notify-keyspace-events", flags+missing).Err(); e != nil {
+		return ErrKeyEventsDisabled
+	}
+	return nil
+}
+
+// globRegexp translates a Redis glob, *, ? and [...] classes, into a regexp.
+func globRegexp(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		case '[':
+			end := strings.IndexByte(pattern[i:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+end]
+			if strings.HasPrefix(class, "^") {
+				class = "^" + regexp.QuoteMeta(class[1:])
+			} else {
+				class = regexp.QuoteMeta(class)
+			}
+			b.WriteString("[" + class + "]")
+			i += end
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
@@ -0,0 +1,73 @@
+package redis
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// fullKey maps a key of the API to the key stored in Redis.
+func (client *Client) fullKey(key string) string {
+	if kp := client.config.KeyProvider; kp != nil {
+		key = obfuscateKey(kp.HMACKey(), key)
+	}
+	return client.config.Prefix + ":" + key
+}
+
+// KeyProvider enables the keyed HMAC transform of keys: everything after the
+// prefix is replaced by its HMAC so identifiers in keys can not be read by
+// anyone with access to Redis. Keys can not be listed back, ScanKeys and
+// TSMRange return the transformed keys.
+type KeyProvider interface {
+	HMACKey() []byte
+	// AuthorizeLookup decides whether LookupKey may reveal the Redis key of a
+	// plain key, e.g. by checking the operator identity in ctx.
+	AuthorizeLookup(ctx context.Context) error
+}
+
+// obfuscateKey keeps hash tags working: a key with a {tag} gets the HMAC of
+// the tag as its own tag, so keys sharing a slot still do.
+func obfuscateKey(secret []byte, key string) string {
+	sum := func(s string) string {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(s))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			return "{" + sum(key[start+1 : start+1+end])[:16] + "}" + sum(key)[:32]
+		}
+	}
+	return sum(key)[:32]
+}
+
+type KeyLookup struct {
+	Key      string // As stored in Redis
+	Type     string // none when the key does not exist
+	TTL      time.Duration
+	Obscured bool
+}
+
+// LookupKey returns the Redis key of a plain key for debugging, after the
+// KeyProvider authorized the caller.
+func (client *Client) LookupKey(ctx context.Context, key string) (*KeyLookup, error) {
+	kp := client.config.KeyProvider
+	if kp != nil {
+		if e := kp.AuthorizeLookup(ctx); e != nil {
+			return nil, errors.Wrap(e, "redis: key lookup not authorized")
+		}
+	}
+	key_str := client.fullKey(key)
+	pipe := client.client.Pipeline()
+	typ := pipe.Type(ctx, key_str)
+	ttl := pipe.PTTL(ctx, key_str)
+	if _, e := pipe.Exec(ctx); e != nil {
+		return nil, client.wrap(e, "RedisLookupKey", "type", key)
+	}
+	return &KeyLookup{Key: key_str, Type: typ.Val(), TTL: ttl.Val(), Obscured: kp != nil}, nil
+}
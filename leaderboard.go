@@ -0,0 +1,209 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+type LeaderboardPeriod int
+
+const (
+	PeriodAllTime LeaderboardPeriod = iota
+	PeriodDaily
+	PeriodWeekly
+	PeriodMonthly
+)
+
+type LeaderboardEntry struct {
+	Member string
+	Score  float64
+	Rank   int64 // 0 is the highest score
+}
+
+// Leaderboard ranks members by score, highest first. With a period other than
+// PeriodAllTime every period gets its own key, <prefix>:lb:<name>:<period>,
+// which expires Retention after the period ends.
+type Leaderboard struct {
+	client *Client
+	name   string
+	period LeaderboardPeriod
+	at     time.Time // Zero means the current period
+
+	Retention time.Duration // Defaults to 7 periods
+}
+
+func (client *Client) Leaderboard(name string, period LeaderboardPeriod) *Leaderboard {
+	return &Leaderboard{client: client, name: name, period: period}
+}
+
+// At returns the same leaderboard bound to the period containing t, e.g. to
+// read yesterday's results.
+func (lb *Leaderboard) At(t time.Time) *Leaderboard {
+	view := *lb
+	view.at = t
+	return &view
+}
+
+func (lb *Leaderboard) now() time.Time {
+	if lb.at.IsZero() {
+		return time.Now().UTC()
+	}
+	return lb.at.UTC()
+}
+
+// periodBounds returns the suffix and end of the period containing t.
+func (lb *Leaderboard) periodBounds(t time.Time) (string, time.Time) {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch lb.period {
+	case PeriodDaily:
+		return day.Format("2006-01-02"), day.AddDate(0, 0, 1)
+	case PeriodWeekly:
+		year, week := t.ISOWeek()
+		monday := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		return fmt.Sprintf("%d-W%02d", year, week), monday.AddDate(0, 0, 7)
+	case PeriodMonthly:
+		first := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return first.Format("2006-01"), first.AddDate(0, 1, 0)
+	}
+	return "", time.Time{}
+}
+
+func (lb *Leaderboard) keyName() string {
+	suffix, _ := lb.periodBounds(lb.now())
+	if suffix == "" {
+		return "lb:" + lb.name
+	}
+	return "lb:" + lb.name + ":" + suffix
+}
+
+func (lb *Leaderboard) key() string {
+	return lb.client.fullKey(lb.keyName())
+}
+
+func (lb *Leaderboard) retention() time.Duration {
+	if lb.Retention > 0 {
+		return lb.Retention
+	}
+	switch lb.period {
+	case PeriodDaily:
+		return 7 * 24 * time.Hour
+	case PeriodWeekly:
+		return 7 * 7 * 24 * time.Hour
+	case PeriodMonthly:
+		return 7 * 31 * 24 * time.Hour
+	}
+	return 0
+}
+
+func (lb *Leaderboard) write(ctx context.Context, fn func(pipe goredis.Pipeliner, key string)) error {
+	key_str := lb.key()
+	pipe := lb.client.client.TxPipeline()
+	fn(pipe, key_str)
+	if _, end := lb.periodBounds(lb.now()); !end.IsZero() {
+		pipe.ExpireAt(ctx, key_str, end.Add(lb.retention()))
+	}
+	_, e := pipe.Exec(ctx)
+	return e
+}
+
+// AddScore adds delta to the member's score and returns the new score.
+func (lb *Leaderboard) AddScore(ctx context.Context, member string, delta float64) (float64, error) {
+	var cmd *goredis.FloatCmd
+	e := lb.write(ctx, func(pipe goredis.Pipeliner, key string) {
+		cmd = pipe.ZIncrBy(ctx, key, delta, member)
+	})
+	if e != nil {
+		return 0, lb.client.wrap(e, "RedisLeaderboardAddScore", "zincrby", lb.keyName())
+	}
+	return cmd.Val(), nil
+}
+
+func (lb *Leaderboard) SetScore(ctx context.Context, member string, score float64) error {
+	e := lb.write(ctx, func(pipe goredis.Pipeliner, key string) {
+		pipe.ZAdd(ctx, key, goredis.Z{Score: score, Member: member})
+	})
+	if e != nil {
+		return lb.client.wrap(e, "RedisLeaderboardSetScore", "zadd", lb.keyName())
+	}
+	return nil
+}
+
+func (lb *Leaderboard) Remove(ctx context.Context, members ...string) error {
+	args := make([]interface{}, len(members))
+	for i, m := range members {
+		args[i] = m
+	}
+	if e := lb.client.client.ZRem(ctx, lb.key(), args...).Err(); e != nil {
+		return lb.client.wrap(e, "RedisLeaderboardRemove", "zrem", lb.keyName())
+	}
+	return nil
+}
+
+func (lb *Leaderboard) rangeEntries(ctx context.Context, start, stop int64) ([]LeaderboardEntry, error) {
+	zs, e := lb.client.client.ZRevRangeWithScores(ctx, lb.key(), start, stop).Result()
+	if e != nil {
+		return nil, lb.client.wrap(e, "RedisLeaderboardRange", "zrevrange", lb.keyName())
+	}
+	entries := make([]LeaderboardEntry, len(zs))
+	for i, z := range zs {
+		entries[i] = LeaderboardEntry{Member: z.Member.(string), Score: z.Score, Rank: start + int64(i)}
+	}
+	return entries, nil
+}
+
+func (lb *Leaderboard) Top(ctx context.Context, n int64) ([]LeaderboardEntry, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	return lb.rangeEntries(ctx, 0, n-1)
+}
+
+// Page returns the entries of a 0-based page of the given size.
+func (lb *Leaderboard) Page(ctx context.Context, page, size int64) ([]LeaderboardEntry, error) {
+	if page < 0 || size <= 0 {
+		return nil, nil
+	}
+	return lb.rangeEntries(ctx, page*size, (page+1)*size-1)
+}
+
+// Rank returns the member's entry, ErrNotFound when it has no score.
+func (lb *Leaderboard) Rank(ctx context.Context, member string) (LeaderboardEntry, error) {
+	pipe := lb.client.client.Pipeline()
+	rank := pipe.ZRevRank(ctx, lb.key(), member)
+	score := pipe.ZScore(ctx, lb.key(), member)
+	if _, e := pipe.Exec(ctx); e != nil {
+		if e == goredis.Nil {
+			return LeaderboardEntry{}, ErrNotFound
+		}
+		return LeaderboardEntry{}, lb.client.wrap(e, "RedisLeaderboardRank", "zrevrank", lb.keyName())
+	}
+	return LeaderboardEntry{Member: member, Score: score.Val(), Rank: rank.Val()}, nil
+}
+
+// Around returns up to n entries on each side of the member, the member
+// included.
+func (lb *Leaderboard) Around(ctx context.Context, member string, n int64) ([]LeaderboardEntry, error) {
+	rank, e := lb.client.client.ZRevRank(ctx, lb.key(), member).Result()
+	if e != nil {
+		if e == goredis.Nil {
+			return nil, ErrNotFound
+		}
+		return nil, lb.client.wrap(e, "RedisLeaderboardAround", "zrevrank", lb.keyName())
+	}
+	start := rank - n
+	if start < 0 {
+		start = 0
+	}
+	return lb.rangeEntries(ctx, start, rank+n)
+}
+
+func (lb *Leaderboard) Count(ctx context.Context) (int64, error) {
+	n, e := lb.client.client.ZCard(ctx, lb.key()).Result()
+	if e != nil {
+		return 0, lb.client.wrap(e, "RedisLeaderboardCount", "zcard", lb.keyName())
+	}
+	return n, nil
+}
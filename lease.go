@@ -0,0 +1,150 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+var (
+	ErrLeaseHeld    = errors.New("redis: lease is held by another owner")
+	ErrLeaseNotHeld = errors.New("redis: lease is not held by this owner")
+)
+
+// Lease records who owns a resource. Unlike a plain lock the owner is kept
+// as the value so it can be reported and taken over after a crash.
+type Lease struct {
+	client *Client
+	key    string
+}
+
+type LeaseInfo struct {
+	Owner string
+	TTL   time.Duration
+}
+
+func (client *Client) Lease(key string) *Lease {
+	return &Lease{client: client, key: key}
+}
+
+// KEYS[1] holds the live owner and expires with the lease, KEYS[2] remembers
+// the last owner so that a crashed holder can be reported on takeover.
+var leaseAcquireScript = NewScript(`
+if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	redis.call("SET", KEYS[2], ARGV[1])
+	return 1
+end
+return 0
+`)
+
+var leaseRenewScript = NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+var leaseReleaseScript = NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	redis.call("DEL", KEYS[2])
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+var leaseStealScript = NewScript(`
+local cur = redis.call("GET", KEYS[1])
+if cur then
+	return {0, cur}
+end
+local prev = redis.call("GET", KEYS[2])
+redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+redis.call("SET", KEYS[2], ARGV[1])
+return {1, prev or ""}
+`)
+
+// Both keys share a hash tag so the scripts also work in cluster mode
+func (l *Lease) keys() []string {
+	key := "lease:{" + l.key + "}"
+	return []string{key, key + ":last"}
+}
+
+// leaseTTL rounds a ttl below a millisecond up, PX 0 is rejected by Redis.
+func leaseTTL(ttl time.Duration) int64 {
+	return max(ttl.Milliseconds(), 1)
+}
+
+// Acquire, Renew and StealIfExpired fail with ErrInvalidTTL for a ttl of 0 or
+// less, a ttl below a millisecond is rounded up to one.
+func (l *Lease) Acquire(ctx context.Context, owner string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return l.client.wrap(ErrInvalidTTL, "RedisLeaseAcquire", "evalsha", l.key)
+	}
+	n, e := leaseAcquireScript.Run(ctx, l.client, l.keys(), owner, leaseTTL(ttl)).Int64()
+	if e != nil {
+		return l.client.wrap(e, "RedisLeaseAcquire", "evalsha", l.key)
+	}
+	if n == 0 {
+		return ErrLeaseHeld
+	}
+	return nil
+}
+
+func (l *Lease) Renew(ctx context.Context, owner string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return l.client.wrap(ErrInvalidTTL, "RedisLeaseRenew", "evalsha", l.key)
+	}
+	n, e := leaseRenewScript.Run(ctx, l.client, l.keys(), owner, leaseTTL(ttl)).Int64()
+	if e != nil {
+		return l.client.wrap(e, "RedisLeaseRenew", "evalsha", l.key)
+	}
+	if n == 0 {
+		return ErrLeaseNotHeld
+	}
+	return nil
+}
+
+func (l *Lease) Release(ctx context.Context, owner string) error {
+	n, e := leaseReleaseScript.Run(ctx, l.client, l.keys(), owner).Int64()
+	if e != nil {
+		return l.client.wrap(e, "RedisLeaseRelease", "evalsha", l.key)
+	}
+	if n == 0 {
+		return ErrLeaseNotHeld
+	}
+	return nil
+}
+
+// StealIfExpired takes the lease over when nobody holds it and returns the
+// previous owner if it let the lease expire instead of releasing it. When the
+// lease is still alive the current owner is returned with ErrLeaseHeld.
+func (l *Lease) StealIfExpired(ctx context.Context, owner string, ttl time.Duration) (string, error) {
+	if ttl <= 0 {
+		return "", l.client.wrap(ErrInvalidTTL, "RedisLeaseSteal", "evalsha", l.key)
+	}
+	res, e := leaseStealScript.Run(ctx, l.client, l.keys(), owner, leaseTTL(ttl)).Slice()
+	if e != nil {
+		return "", l.client.wrap(e, "RedisLeaseSteal", "evalsha", l.key)
+	}
+	prev, _ := res[1].(string)
+	if n, _ := res[0].(int64); n == 0 {
+		return prev, ErrLeaseHeld
+	}
+	return prev, nil
+}
+
+func (l *Lease) Info(ctx context.Context) (*LeaseInfo, error) {
+	key_str := l.client.fullKey(l.keys()[0])
+	pipe := l.client.client.Pipeline()
+	get := pipe.Get(ctx, key_str)
+	ttl := pipe.PTTL(ctx, key_str)
+	if _, e := pipe.Exec(ctx); e != nil {
+		if e == goredis.Nil {
+			return nil, ErrNotFound
+		}
+		return nil, l.client.wrap(e, "RedisLeaseInfo", "get", l.key)
+	}
+	return &LeaseInfo{Owner: get.Val(), TTL: ttl.Val()}, nil
+}
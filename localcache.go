@@ -0,0 +1,290 @@
+package redis
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+type LocalCacheConfig struct {
+	Size    int    `mapstructure:"size"`    // Max number of entries kept in process, 0 disables the local cache
+	TTL     int    `mapstructure:"ttl"`     // Seconds an entry may be served locally, 0 means until evicted, invalidated or expired in Redis
+	Channel string `mapstructure:"channel"` // Pub/Sub invalidation channel, keyspace notifications are used when empty
+
+	// Tracking invalidates through CLIENT TRACKING (Redis 6) instead of
+	// keyspace notifications, it needs no server configuration.
+	Tracking bool `mapstructure:"tracking"`
+
+	// TinyLFU only admits a new entry into a full cache when it is read more
+	// often than the entry it would evict, so one-off reads keep hot keys in.
+	TinyLFU bool `mapstructure:"tiny_lfu"`
+}
+
+type LocalCacheStats struct {
+	Hits     uint64
+	Misses   uint64
+	Admitted uint64 // New entries stored
+	Rejected uint64 // New entries refused by TinyLFU
+	Entries  int
+}
+
+func (s LocalCacheStats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+type localEntry struct {
+	key     string
+	value   string
+	expires time.Time
+}
+
+type localCache struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	items map[string]*list.Element
+	order *list.List
+
+	sketch *frequencySketch // nil without TinyLFU
+	stats  LocalCacheStats
+
+	// Reads in flight per key, an invalidation during the read marks it
+	// stale so the value read before the write is not stored.
+	fetches map[string]*localFetch
+}
+
+type localFetch struct {
+	n     int
+	stale bool
+}
+
+func newLocalCache(size int, ttl time.Duration, tinyLFU bool) *localCache {
+	lc := &localCache{
+		size:    size,
+		ttl:     ttl,
+		items:   make(map[string]*list.Element, size),
+		order:   list.New(),
+		fetches: make(map[string]*localFetch),
+	}
+	if tinyLFU {
+		lc.sketch = newFrequencySketch(size)
+	}
+	return lc
+}
+
+func (lc *localCache) get(key string) (string, bool) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	if lc.sketch != nil {
+		lc.sketch.increment(key)
+	}
+	el, ok := lc.items[key]
+	if !ok {
+		lc.stats.Misses++
+		return "", false
+	}
+	entry := el.Value.(*localEntry)
+	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
+		lc.order.Remove(el)
+		delete(lc.items, key)
+		lc.stats.Misses++
+		return "", false
+	}
+	lc.order.MoveToFront(el)
+	lc.stats.Hits++
+	return entry.value, true
+}
+
+// fetch registers a read of key from Redis, its result is stored with
+// setFetched.
+func (lc *localCache) fetch(key string) *localFetch {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	f, ok := lc.fetches[key]
+	if !ok {
+		f = &localFetch{}
+		lc.fetches[key] = f
+	}
+	f.n++
+	return f
+}
+
+// fetched ends the read registered by fetch and reports whether its value
+// may be stored, false when the key was invalidated in the meantime.
+func (lc *localCache) fetched(key string, f *localFetch) bool {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	if f.n--; f.n == 0 {
+		delete(lc.fetches, key)
+	}
+	return !f.stale
+}
+
+// set stores value for at most ttl, the remaining time to live in Redis. A
+// ttl of 0 or less, for a key without expiry, only applies the TTL of the
+// local cache.
+func (lc *localCache) set(key, value string, ttl time.Duration) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	if ttl <= 0 || (lc.ttl > 0 && lc.ttl < ttl) {
+		ttl = lc.ttl
+	}
+	var expires time.Time
+	if ttl > 0 {
+		expires = time.Now().Add(ttl)
+	}
+	if el, ok := lc.items[key]; ok {
+		entry := el.Value.(*localEntry)
+		entry.value = value
+		entry.expires = expires
+		lc.order.MoveToFront(el)
+		return
+	}
+	if lc.sketch != nil && lc.order.Len() >= lc.size {
+		victim := lc.order.Back().Value.(*localEntry).key
+		if lc.sketch.frequency(key) <= lc.sketch.frequency(victim) {
+			lc.stats.Rejected++
+			return
+		}
+	}
+	lc.stats.Admitted++
+	lc.items[key] = lc.order.PushFront(&localEntry{key: key, value: value, expires: expires})
+	for lc.order.Len() > lc.size {
+		el := lc.order.Back()
+		lc.order.Remove(el)
+		delete(lc.items, el.Value.(*localEntry).key)
+	}
+}
+
+func (lc *localCache) remove(key string) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	if f, ok := lc.fetches[key]; ok {
+		f.stale = true
+	}
+	if el, ok := lc.items[key]; ok {
+		lc.order.Remove(el)
+		delete(lc.items, key)
+	}
+}
+
+func (lc *localCache) purge() {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	lc.items = make(map[string]*list.Element, lc.size)
+	lc.order.Init()
+	for _, f := range lc.fetches {
+		f.stale = true
+	}
+}
+
+// Keeps the local tier coherent with writes made by other processes.
+func (client *Client) watchInvalidations(ctx context.Context, opts goredis.UniversalOptions) {
+	cfg := client.config.LocalCache
+	if cfg.Tracking {
+		client.trackInvalidations(ctx, opts)
+		return
+	}
+	if cfg.Channel != "" {
+		sub := client.client.Subscribe(ctx, cfg.Channel)
+		defer sub.Close()
+		client.consumeInvalidations(ctx, sub.Channel(), func(msg *goredis.Message) string {
+			return msg.Payload
+		})
+		return
+	}
+
+	// Notifications are local to a node, in cluster mode every master is
+	// subscribed
+	nodes := []goredis.UniversalClient{client.client}
+	if cc, ok := client.client.(*goredis.ClusterClient); ok {
+		nodes = nodes[:0]
+		var mu sync.Mutex
+		e := cc.ForEachMaster(ctx, func(ctx context.Context, node *goredis.Client) error {
+			mu.Lock()
+			nodes = append(nodes, node)
+			mu.Unlock()
+			return nil
+		})
+		if e != nil {
+			client.log().Warn("RedisLocalCache:Keyspace", "error", e)
+		}
+	}
+
+	keyspace := fmt.Sprintf("__keyspace@%d__:", client.config.DB)
+	var wg sync.WaitGroup
+	for _, node := range nodes {
+		node := node
+		// Without the flags cached entries are never invalidated
+		if e := client.enableNotifyFlags(ctx, node, localCacheEventFlags); e != nil {
+			client.log().Warn("RedisLocalCache:Keyspace", "error", e)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sub := node.PSubscribe(ctx, keyspace+client.config.Prefix+":*")
+			defer sub.Close()
+			client.consumeInvalidations(ctx, sub.Channel(), func(msg *goredis.Message) string {
+				return strings.TrimPrefix(msg.Channel, keyspace)
+			})
+		}()
+	}
+	wg.Wait()
+}
+
+// Flags the local cache needs in notify-keyspace-events: keyspace channels,
+// generic commands, strings, expired and evicted keys.
+const localCacheEventFlags = "Kg$xe"
+
+func (client *Client) consumeInvalidations(ctx context.Context, ch <-chan *goredis.Message, key func(*goredis.Message) string) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			client.local.remove(key(msg))
+		}
+	}
+}
+
+func (client *Client) invalidateLocal(ctx context.Context, key_str string) {
+	if client.local == nil {
+		return
+	}
+	client.local.remove(key_str)
+	if client.config.LocalCache.Channel != "" {
+		if e := client.client.Publish(ctx, client.config.LocalCache.Channel, key_str).Err(); e != nil {
+			client.log().Warn("RedisLocalCache:Publish", "error", e)
+		}
+	}
+}
+
+// LocalCacheStats returns the counters of the local tier, the zero value when
+// it is disabled.
+func (client *Client) LocalCacheStats() LocalCacheStats {
+	if client.local == nil {
+		return LocalCacheStats{}
+	}
+	client.local.mu.Lock()
+	defer client.local.mu.Unlock()
+	stats := client.local.stats
+	stats.Entries = len(client.local.items)
+	return stats
+}
+
+func (client *Client) PurgeLocal() {
+	if client.local != nil {
+		client.local.purge()
+	}
+}
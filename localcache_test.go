@@ -0,0 +1,88 @@
+package redis_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/acsl-go/redis"
+	"github.com/acsl-go/redis/redistest"
+)
+
+var testLocalCache = redis.LocalCacheConfig{Size: 100, Channel: "invalidate"}
+
+func TestLocalCacheHitsAndOwnWrites(t *testing.T) {
+	client, _ := redistest.NewTestClient(t, &redis.Config{Prefix: "test", LocalCache: testLocalCache})
+	ctx := context.Background()
+	if e := client.SetStr(ctx, "k", "v1", 0); e != nil {
+		t.Fatal(e)
+	}
+	for i := 0; i < 3; i++ {
+		if v, e := client.GetStr(ctx, "k"); e != nil || v != "v1" {
+			t.Fatalf("GetStr = %q, %v", v, e)
+		}
+	}
+	if s := client.LocalCacheStats(); s.Hits != 2 || s.Misses != 1 {
+		t.Errorf("stats %+v, want 2 hits and 1 miss", s)
+	}
+
+	if e := client.SetStr(ctx, "k", "v2", 0); e != nil {
+		t.Fatal(e)
+	}
+	if v, e := client.GetStr(ctx, "k"); e != nil || v != "v2" {
+		t.Errorf("GetStr after write = %q, %v, want v2", v, e)
+	}
+}
+
+func TestLocalCacheCappedByKeyTTL(t *testing.T) {
+	client, _ := redistest.NewTestClient(t, &redis.Config{Prefix: "test", LocalCache: testLocalCache})
+	ctx := context.Background()
+	if e := client.SetStrFor(ctx, "k", "v", 50*time.Millisecond); e != nil {
+		t.Fatal(e)
+	}
+	client.GetStr(ctx, "k")
+	client.GetStr(ctx, "k")
+	if s := client.LocalCacheStats(); s.Hits != 1 {
+		t.Fatalf("stats %+v, want a hit", s)
+	}
+
+	// miniredis only expires keys on FastForward, the local entry must
+	// expire on its own
+	time.Sleep(80 * time.Millisecond)
+	client.GetStr(ctx, "k")
+	if s := client.LocalCacheStats(); s.Hits != 1 || s.Misses != 2 {
+		t.Errorf("stats %+v, want the entry expired with the key", s)
+	}
+}
+
+func TestLocalCacheInvalidatedByOtherClients(t *testing.T) {
+	client, mr := redistest.NewTestClient(t, &redis.Config{Prefix: "test", LocalCache: testLocalCache})
+	other, e := redis.NewClient(&redis.Config{Addresses: []string{mr.Addr()}, Prefix: "test", LocalCache: testLocalCache})
+	if e != nil {
+		t.Fatal(e)
+	}
+	defer other.Close()
+	ctx := context.Background()
+
+	if e := client.SetStr(ctx, "k", "v1", 0); e != nil {
+		t.Fatal(e)
+	}
+	if v, _ := client.GetStr(ctx, "k"); v != "v1" {
+		t.Fatalf("GetStr = %q, want v1", v)
+	}
+
+	// Write until the subscription of client is up and the entry is dropped
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if e := other.SetStr(ctx, "k", "v2", 0); e != nil {
+			t.Fatal(e)
+		}
+		time.Sleep(10 * time.Millisecond)
+		if v, _ := client.GetStr(ctx, "k"); v == "v2" {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("local entry was not invalidated by the write of another client")
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package redis
+
+import "log/slog"
+
+// Logger receives the warnings of background work and best effort steps that
+// do not fail the call. *slog.Logger satisfies it.
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+func (client *Client) log() Logger {
+	if client.config.Logger != nil {
+		return client.config.Logger
+	}
+	return slog.Default()
+}
+
+// logKey is the key as it may appear in logs
+func (client *Client) logKey(key string) string {
+	if client.config.RedactKeys {
+		return client.redact(key)
+	}
+	return key
+}
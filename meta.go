@@ -0,0 +1,48 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// GetWithMeta decodes key into v and returns its remaining TTL and encoded
+// size in one round trip. A missing key is not an error, found is false then.
+// The ttl is -1 for a key without expiration. The local cache is bypassed.
+func (client *Client) GetWithMeta(ctx context.Context, key string, v interface{}) (found bool, ttl time.Duration, size int, err error) {
+	key_str := client.fullKey(key)
+	cmd := "get"
+	var data func() (string, error)
+
+	pipe := client.client.Pipeline()
+	if client.jsonDocs(ctx) {
+		cmd, data = "json.get", queueGetDoc(ctx, pipe, key_str)
+	} else {
+		data = pipe.Get(ctx, key_str).Result
+	}
+	pttl := pipe.PTTL(ctx, key_str)
+	if _, e := pipe.Exec(ctx); e != nil && e != goredis.Nil {
+		return false, 0, 0, client.wrap(e, "RedisGetWithMeta", cmd, key)
+	}
+
+	data_str, e := data()
+	if e == goredis.Nil || (e == nil && data_str == "") {
+		return false, 0, 0, nil
+	}
+	if e != nil {
+		return false, 0, 0, client.wrap(e, "RedisGetWithMeta", cmd, key)
+	}
+	if data_str = stripMeta(data_str); data_str == "" {
+		return false, 0, 0, nil
+	}
+	if e := client.codec.Unmarshal([]byte(data_str), v); e != nil {
+		return true, 0, 0, client.wrap(e, "RedisGetWithMeta:JSONUnmarshal", "", key)
+	}
+
+	ttl = pttl.Val()
+	if ttl < 0 {
+		ttl = -1
+	}
+	return true, ttl, len(data_str), nil
+}
@@ -0,0 +1,147 @@
+package redis
+
+import (
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Metrics receives connection level events from the client. Implementations
+// should embed NopMetrics so that new events can be added without breaking them.
+type Metrics interface {
+	ObserveDial(addr string, latency time.Duration, err error)
+	ObservePoolTimeout(cmd string)
+	ObserveSlowCommand(cmd string, duration time.Duration)
+	ObserveCacheLookup(layer string, hit bool) // layer is "local" or "redis"
+	ObserveProbe(latency time.Duration, err error)
+	ObservePanic(callback string)
+	ObserveOversizedValue(op string, size int, rejected bool)
+}
+
+type NopMetrics struct{}
+
+func (NopMetrics) ObserveDial(addr string, latency time.Duration, err error) {}
+func (NopMetrics) ObservePoolTimeout(cmd string)                             {}
+func (NopMetrics) ObserveSlowCommand(cmd string, duration time.Duration)     {}
+func (NopMetrics) ObserveCacheLookup(layer string, hit bool)                 {}
+func (NopMetrics) ObserveProbe(latency time.Duration, err error)             {}
+func (NopMetrics) ObservePanic(callback string)                              {}
+func (NopMetrics) ObserveOversizedValue(op string, size int, rejected bool)  {}
+
+type metricsList struct {
+	mu   sync.Mutex
+	list atomic.Pointer[[]Metrics]
+}
+
+func (l *metricsList) each(fn func(m Metrics)) {
+	if list := l.list.Load(); list != nil {
+		for _, m := range *list {
+			fn(m)
+		}
+	}
+}
+
+// AddMetrics registers another receiver of connection and cache events, it is
+// shared by all views of the client.
+func (client *Client) AddMetrics(m Metrics) {
+	l := client.metrics
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var list []Metrics
+	if cur := l.list.Load(); cur != nil {
+		list = append(list, *cur...)
+	}
+	list = append(list, m)
+	l.list.Store(&list)
+}
+
+type ConnStats struct {
+	Dials        uint64
+	DialErrors   uint64
+	DialLatency  time.Duration // Accumulated over all dials
+	PoolTimeouts uint64
+
+	Hits       uint32 // Number of times a free connection was found in the pool
+	Misses     uint32 // Number of times a free connection was NOT found in the pool
+	Timeouts   uint32 // Number of times a wait timeout occurred
+	TotalConns uint32
+	IdleConns  uint32
+	StaleConns uint32 // Number of stale connections removed from the pool
+}
+
+type connCounters struct {
+	dials        atomic.Uint64
+	dialErrors   atomic.Uint64
+	dialLatency  atomic.Int64
+	poolTimeouts atomic.Uint64
+}
+
+func (client *Client) ConnStats() ConnStats {
+	ps := client.client.PoolStats()
+	return ConnStats{
+		Dials:        client.counters.dials.Load(),
+		DialErrors:   client.counters.dialErrors.Load(),
+		DialLatency:  time.Duration(client.counters.dialLatency.Load()),
+		PoolTimeouts: client.counters.poolTimeouts.Load(),
+		Hits:         ps.Hits,
+		Misses:       ps.Misses,
+		Timeouts:     ps.Timeouts,
+		TotalConns:   ps.TotalConns,
+		IdleConns:    ps.IdleConns,
+		StaleConns:   ps.StaleConns,
+	}
+}
+
+type metricsHook struct {
+	client *Client
+}
+
+func (h *metricsHook) DialHook(next goredis.DialHook) goredis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		start := time.Now()
+		conn, e := next(ctx, network, addr)
+		latency := time.Since(start)
+
+		h.client.counters.dials.Add(1)
+		h.client.counters.dialLatency.Add(int64(latency))
+		if e != nil {
+			h.client.counters.dialErrors.Add(1)
+		}
+		h.client.metrics.each(func(m Metrics) { m.ObserveDial(addr, latency, e) })
+		return conn, e
+	}
+}
+
+func (h *metricsHook) ProcessHook(next goredis.ProcessHook) goredis.ProcessHook {
+	return func(ctx context.Context, cmd goredis.Cmder) error {
+		e := next(ctx, cmd)
+		if isPoolTimeout(e) {
+			h.observePoolTimeout(cmd.Name())
+		}
+		return e
+	}
+}
+
+func (h *metricsHook) ProcessPipelineHook(next goredis.ProcessPipelineHook) goredis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []goredis.Cmder) error {
+		e := next(ctx, cmds)
+		if isPoolTimeout(e) {
+			h.observePoolTimeout("pipeline")
+		}
+		return e
+	}
+}
+
+func (h *metricsHook) observePoolTimeout(cmd string) {
+	h.client.counters.poolTimeouts.Add(1)
+	h.client.metrics.each(func(m Metrics) { m.ObservePoolTimeout(cmd) })
+}
+
+// go-redis keeps its pool errors internal, so match on the message
+func isPoolTimeout(e error) bool {
+	return e != nil && e.Error() == "redis: connection pool timeout"
+}
@@ -0,0 +1,191 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type MigrationFunc func(ctx context.Context, client *Client, progress func(format string, args ...interface{})) error
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      MigrationFunc
+}
+
+// Migrator applies versioned data migrations exactly once across all
+// instances. Applied versions are kept in the <prefix>:migrations hash and a
+// Lease makes sure only one instance runs them at a time.
+type Migrator struct {
+	client     *Client
+	migrations []Migration
+	LockTTL    time.Duration
+	PollDelay  time.Duration // How often instances waiting for the lock check again
+}
+
+type appliedMigration struct {
+	Name      string    `json:"name"`
+	AppliedAt time.Time `json:"applied_at"`
+}
+
+func (client *Client) Migrator() *Migrator {
+	return &Migrator{
+		client:    client,
+		LockTTL:   30 * time.Second,
+		PollDelay: time.Second,
+	}
+}
+
+func (m *Migrator) Register(version int, name string, up MigrationFunc) *Migrator {
+	m.migrations = append(m.migrations, Migration{Version: version, Name: name, Up: up})
+	return m
+}
+
+func (m *Migrator) key() string {
+	return m.client.fullKey("migrations")
+}
+
+// Applied returns the applied versions.
+func (m *Migrator) Applied(ctx context.Context) (map[int]bool, error) {
+	fields, e := m.client.client.HKeys(ctx, m.key()).Result()
+	if e != nil {
+		return nil, m.client.wrap(e, "RedisMigrate", "hkeys", "migrations")
+	}
+	applied := make(map[int]bool, len(fields))
+	for _, f := range fields {
+		if v, e := strconv.Atoi(f); e == nil {
+			applied[v] = true
+		}
+	}
+	return applied, nil
+}
+
+// Run applies the pending migrations in version order. When another instance
+// holds the lock Run waits for it and returns once everything is applied.
+func (m *Migrator) Run(ctx context.Context) error {
+	sort.Slice(m.migrations, func(i, j int) bool { return m.migrations[i].Version < m.migrations[j].Version })
+	for i := 1; i < len(m.migrations); i++ {
+		if m.migrations[i].Version == m.migrations[i-1].Version {
+			return errors.Errorf("redis: duplicate migration version %d", m.migrations[i].Version)
+		}
+	}
+
+	host, _ := os.Hostname()
+	owner := fmt.Sprintf("%s:%d:%d", host, os.Getpid(), time.Now().UnixNano())
+	lease := m.client.Lease("migrations:lock")
+
+	for {
+		applied, e := m.Applied(ctx)
+		if e != nil {
+			return e
+		}
+		if m.pending(applied) == 0 {
+			return nil
+		}
+
+		e = lease.Acquire(ctx, owner, m.LockTTL)
+		if e == nil {
+			defer lease.Release(context.Background(), owner)
+			return m.apply(ctx, lease, owner)
+		}
+		if e != ErrLeaseHeld {
+			return e
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(m.PollDelay):
+		}
+	}
+}
+
+func (m *Migrator) pending(applied map[int]bool) int {
+	n := 0
+	for _, mig := range m.migrations {
+		if !applied[mig.Version] {
+			n++
+		}
+	}
+	return n
+}
+
+func (m *Migrator) apply(ctx context.Context, lease *Lease, owner string) error {
+	// Keep the lease alive while long migrations run, once it is lost the
+	// running migration is cancelled
+	work_ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	go func() {
+		ticker := time.NewTicker(m.LockTTL / 3)
+		defer ticker.Stop()
+		renewed := time.Now()
+		for {
+			select {
+			case <-work_ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			start := time.Now()
+			e := lease.Renew(work_ctx, owner, m.LockTTL)
+			if e == nil {
+				renewed = start
+				continue
+			}
+			if work_ctx.Err() != nil {
+				return
+			}
+			m.client.log().Warn("RedisMigrate:Renew", "error", e)
+			if e == ErrLeaseNotHeld || time.Since(renewed) >= m.LockTTL {
+				cancel(ErrLeaseNotHeld)
+				return
+			}
+		}
+	}()
+
+	// Re-read under the lock, the previous holder may have finished in between
+	applied, e := m.Applied(ctx)
+	if e != nil {
+		return e
+	}
+
+	for _, mig := range m.migrations {
+		if applied[mig.Version] {
+			continue
+		}
+		progress := func(format string, args ...interface{}) {
+			m.client.log().Info("RedisMigrate: "+fmt.Sprintf(format, args...), "version", mig.Version, "name", mig.Name)
+		}
+		progress("started")
+		start := time.Now()
+		if e := m.up(work_ctx, mig, progress); e != nil {
+			if cause := context.Cause(work_ctx); cause == ErrLeaseNotHeld {
+				e = cause
+			}
+			return errors.Wrapf(e, "RedisMigrate: migration %d (%s) failed", mig.Version, mig.Name)
+		}
+
+		// Only the owner of the lease may record the version, another
+		// instance may have taken over while Up ran
+		if e := lease.Renew(ctx, owner, m.LockTTL); e != nil {
+			return errors.Wrapf(e, "RedisMigrate: migration %d (%s) not recorded", mig.Version, mig.Name)
+		}
+		data_str, _ := json.Marshal(&appliedMigration{Name: mig.Name, AppliedAt: time.Now()})
+		if e := m.client.client.HSet(ctx, m.key(), strconv.Itoa(mig.Version), data_str).Err(); e != nil {
+			return m.client.wrap(e, "RedisMigrate", "hset", "migrations")
+		}
+		progress("done in %v", time.Since(start))
+	}
+	return nil
+}
+
+func (m *Migrator) up(ctx context.Context, mig Migration, progress func(format string, args ...interface{})) (e error) {
+	defer m.client.recoverPanic("migration", &e)
+	return mig.Up(ctx, m.client, progress)
+}
@@ -0,0 +1,169 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// mapDest checks that dest is a map with string keys, or a pointer to one,
+// and allocates a nil map.
+func mapDest(dest interface{}) (reflect.Value, error) {
+	m := reflect.ValueOf(dest)
+	if m.Kind() == reflect.Pointer && m.Elem().Kind() == reflect.Map {
+		if m.Elem().IsNil() {
+			m.Elem().Set(reflect.MakeMap(m.Elem().Type()))
+		}
+		m = m.Elem()
+	}
+	if m.Kind() != reflect.Map || m.Type().Key().Kind() != reflect.String || m.IsNil() {
+		return reflect.Value{}, fmt.Errorf("redis: dest must be a non-nil map[string]T, got %T", dest)
+	}
+	return m, nil
+}
+
+// getMulti returns the raw values of the keys found and the keys missing,
+// keys cached as not found by SetNotFound are in neither. Without cluster
+// mode a single MGET is sent, a cluster gets pipelined GETs as the keys
+// usually span slots.
+func (client *Client) getMulti(ctx context.Context, keys []string) (map[string]string, []string, error) {
+	key_strs := make([]string, len(keys))
+	for i, key := range keys {
+		key_strs[i] = client.fullKey(key)
+	}
+
+	docs := client.jsonDocs(ctx)
+	values := make([]interface{}, len(keys))
+	if _, ok := client.client.(*goredis.ClusterClient); ok {
+		pipe := client.client.Pipeline()
+		reads := make([]func() (string, error), len(keys))
+		for i, key_str := range key_strs {
+			if docs {
+				reads[i] = queueGetDoc(ctx, pipe, key_str)
+			} else {
+				reads[i] = pipe.Get(ctx, key_str).Result
+			}
+		}
+		if _, e := pipe.Exec(ctx); e != nil && e != goredis.Nil {
+			return nil, nil, client.wrap(e, "RedisGetMulti", "get", "")
+		}
+		for i, read := range reads {
+			if data_str, e := read(); e == nil {
+				values[i] = data_str
+			}
+		}
+	} else if docs {
+		var e error
+		if values, e = docGetScript.runFull(ctx, client.client, key_strs).Slice(); e != nil {
+			return nil, nil, client.wrap(e, "RedisGetMulti", "json.get", "")
+		}
+	} else {
+		var e error
+		if values, e = client.reader(ctx).MGet(ctx, key_strs...).Result(); e != nil {
+			return nil, nil, client.wrap(e, "RedisGetMulti", "mget", "")
+		}
+	}
+
+	found := make(map[string]string, len(keys))
+	var missing []string
+	for i, v := range values {
+		data_str, ok := v.(string)
+		switch {
+		case !ok:
+			missing = append(missing, keys[i])
+		case data_str != notFoundMarker:
+			found[keys[i]] = data_str
+		}
+	}
+	return found, missing, nil
+}
+
+func (client *Client) decodeInto(m reflect.Value, key, data_str string) error {
+	elem := reflect.New(m.Type().Elem())
+	if e := client.decode("RedisGetMulti", key, data_str, elem.Interface()); e != nil {
+		return e
+	}
+	m.SetMapIndex(reflect.ValueOf(key), elem.Elem())
+	return nil
+}
+
+// GetMulti decodes the cached keys into dest, a map[string]T, and returns the
+// keys that are not cached.
+func (client *Client) GetMulti(ctx context.Context, keys []string, dest interface{}) ([]string, error) {
+	m, e := mapDest(dest)
+	if e != nil {
+		return nil, e
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	found, missing, e := client.getMulti(ctx, keys)
+	if e != nil {
+		return nil, e
+	}
+	for key, data_str := range found {
+		if e := client.decodeInto(m, key, data_str); e != nil && e != ErrNotFound {
+			return nil, e
+		}
+	}
+	return missing, nil
+}
+
+// LoadMulti is GetMulti with a loader for the missing keys, the loaded values
+// are stored for ttl seconds in one pipeline. Keys the loader does not return
+// are cached as not found when Config.NegativeTTL is set.
+func (client *Client) LoadMulti(ctx context.Context, keys []string, dest interface{}, ttl int, loader func(ctx context.Context, missing []string) (map[string]interface{}, error)) error {
+	m, e := mapDest(dest)
+	if e != nil {
+		return e
+	}
+	missing, e := client.GetMulti(ctx, keys, dest)
+	if e != nil || len(missing) == 0 {
+		return e
+	}
+
+	loaded, e := client.callLoadMulti(ctx, loader, missing)
+	if e != nil {
+		return e
+	}
+
+	docs := client.jsonDocs(ctx)
+	pipe := client.client.Pipeline()
+	for _, key := range missing {
+		v, ok := loaded[key]
+		if !ok {
+			if client.config.NegativeTTL > 0 {
+				pipe.Set(ctx, client.fullKey(key), notFoundMarker, client.expiration(client.config.NegativeTTL))
+			}
+			continue
+		}
+		data, e := client.codec.Marshal(v)
+		if e != nil {
+			return client.wrap(e, "RedisLoadMulti:JSONMarshal", "", key)
+		}
+		if e := client.decodeInto(m, key, string(data)); e != nil {
+			return e
+		}
+		if docs {
+			queueSetDoc(ctx, pipe, client.fullKey(key), string(data), client.expiration(ttl))
+		} else {
+			pipe.Set(ctx, client.fullKey(key), data, client.expiration(ttl))
+		}
+	}
+	if pipe.Len() > 0 {
+		if _, e := pipe.Exec(ctx); e != nil {
+			client.log().Warn("RedisLoadMulti:Set", "error", e)
+		}
+		for _, key := range missing {
+			client.invalidateLocal(ctx, client.fullKey(key))
+		}
+	}
+	return nil
+}
+
+func (client *Client) callLoadMulti(ctx context.Context, loader func(ctx context.Context, missing []string) (map[string]interface{}, error), missing []string) (v map[string]interface{}, e error) {
+	defer client.recoverPanic("loader", &e)
+	return loader(ctx, missing)
+}
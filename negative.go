@@ -0,0 +1,86 @@
+package redis
+
+import (
+	"context"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// A missing entity is cached as this marker, Get and the other value getters
+// report it as ErrNotFound.
+const notFoundMarker = "\x00notfound"
+
+// stripMeta returns the encoded value without the soft TTL header, empty for
+// a cached miss.
+func stripMeta(data_str string) string {
+	data_str, _, _ = splitSoft(data_str)
+	if data_str == notFoundMarker {
+		return ""
+	}
+	return data_str
+}
+
+// SetNotFound caches that key does not exist for ttl seconds, Config.NegativeTTL
+// when 0.
+func (client *Client) SetNotFound(ctx context.Context, key string, ttl int) error {
+	key_str := client.fullKey(key)
+	if ttl == 0 {
+		ttl = client.config.NegativeTTL
+	}
+	if e := client.client.Set(ctx, key_str, notFoundMarker, client.expiration(ttl)).Err(); e != nil {
+		return client.wrap(e, "RedisSetNotFound", "set", key)
+	}
+	client.invalidateLocal(ctx, key_str)
+	return nil
+}
+
+// GetOrLoad decodes key into v, calling load on a miss and storing its result
+// for ttl seconds. When load returns ErrNotFound and Config.NegativeTTL is set
+// the miss is cached too, and later calls return ErrNotFound without load.
+func (client *Client) GetOrLoad(ctx context.Context, key string, v interface{}, ttl int, load func(ctx context.Context) (interface{}, error)) error {
+	key_str := client.fullKey(key)
+	data_str, e := client.getRaw(ctx, key_str)
+	if e == nil {
+		return client.decode("RedisGetOrLoad", key, data_str, v)
+	}
+	if e != goredis.Nil {
+		return client.wrap(e, "RedisGetOrLoad", "get", key)
+	}
+
+	loaded, e := client.callLoad(ctx, load)
+	if e == ErrNotFound {
+		if client.config.NegativeTTL > 0 {
+			if e := client.SetNotFound(ctx, key, 0); e != nil {
+				client.log().Warn("RedisGetOrLoad:SetNotFound", "key", client.logKey(key), "error", e)
+			}
+		}
+		return ErrNotFound
+	}
+	if e != nil {
+		return e
+	}
+
+	data, e := client.codec.Marshal(loaded)
+	if e != nil {
+		return client.wrap(e, "RedisGetOrLoad:JSONMarshal", "", key)
+	}
+	if client.jsonDocs(ctx) {
+		_, e = client.setDoc(ctx, key_str, string(data), "", client.expiration(ttl), false)
+	} else {
+		e = client.client.Set(ctx, key_str, data, client.expiration(ttl)).Err()
+	}
+	if e != nil {
+		client.log().Warn("RedisGetOrLoad:Set", "key", client.logKey(key), "error", e)
+	} else {
+		client.invalidateLocal(ctx, key_str)
+	}
+	if e := client.codec.Unmarshal(data, v); e != nil {
+		return client.wrap(e, "RedisGetOrLoad:JSONUnmarshal", "", key)
+	}
+	return nil
+}
+
+func (client *Client) callLoad(ctx context.Context, load func(ctx context.Context) (interface{}, error)) (v interface{}, e error) {
+	defer client.recoverPanic("loader", &e)
+	return load(ctx)
+}
@@ -0,0 +1,102 @@
+package redis
+
+import (
+	"encoding/json"
+	"math/rand"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Codec encodes the values of Get/Set and the other value helpers.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+type JSONCodec struct{}
+
+func (JSONCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+type Option func(*Client)
+
+func WithCodec(codec Codec) Option {
+	return func(client *Client) {
+		client.codec = codec
+	}
+}
+
+// WithDefaultTTL sets the expiration in seconds used by the setters when they
+// are called with a ttl of 0.
+func WithDefaultTTL(ttl int) Option {
+	return func(client *Client) {
+		client.defaultTTL = ttl
+	}
+}
+
+// With returns a view of the client with opts applied. The view shares the
+// connection pool, metrics, hooks and local cache with the original client,
+// options only change how the view encodes values and picks TTLs.
+func (client *Client) With(opts ...Option) *Client {
+	c := *client
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return &c
+}
+
+// WithPrefix returns a view of the client whose keys live under
+// <prefix>:<namespace>, so the modules of a service can keep their key spaces
+// apart. Like With it shares the connection pool, closing the view closes the
+// client.
+func (client *Client) WithPrefix(namespace string) *Client {
+	c := *client
+	cfg := *client.config
+	cfg.Prefix = client.config.Prefix + ":" + namespace
+	c.config = &cfg
+	return &c
+}
+
+// WithTTLJitter spreads expirations by a random ±fraction of the TTL, e.g. 0.1
+// for ±10%, so entries written together do not expire together.
+func WithTTLJitter(fraction float64) Option {
+	return func(client *Client) {
+		client.ttlJitter = fraction
+	}
+}
+
+func (client *Client) expiration(ttl int) time.Duration {
+	return client.expirationFor(seconds(ttl))
+}
+
+// expirationFor applies the default ttl and jitter to a ttl given as duration.
+func (client *Client) expirationFor(d time.Duration) time.Duration {
+	if d == 0 {
+		d = seconds(client.defaultTTL)
+	}
+	if client.ttlJitter > 0 && d > 0 {
+		d += time.Duration((rand.Float64()*2 - 1) * client.ttlJitter * float64(d))
+		d = max(d.Truncate(time.Millisecond), time.Millisecond)
+	}
+	return d
+}
+
+// KeepTTL as the ttl of the setters keeps the current expiry of an existing
+// key instead of replacing it, e.g. to refresh a cached value on write. It is
+// accepted as seconds and as time.Duration.
+const KeepTTL = -1
+
+// Persist as the ttl of GetEx removes the expiry of the key. Like KeepTTL it
+// is accepted as seconds and as time.Duration.
+const Persist = -2
+
+func seconds(ttl int) time.Duration {
+	switch ttl {
+	case KeepTTL:
+		return goredis.KeepTTL
+	case Persist:
+		return Persist
+	}
+	return time.Duration(ttl) * time.Second
+}
@@ -0,0 +1,47 @@
+package redis
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is returned in place of a user callback that panicked, so that a
+// bad hook or handler does not take a background loop or the process down.
+type PanicError struct {
+	Callback string // e.g. "hook", "consumer", "migration"
+	Value    interface{}
+	Stack    []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("redis: panic in %s callback: %v", e.Callback, e.Value)
+}
+
+// PanicHandler is called for every recovered panic, instead of the default
+// error log.
+type PanicHandler func(p *PanicError)
+
+func WithPanicHandler(handler PanicHandler) Option {
+	return func(client *Client) {
+		client.panicHandler = handler
+	}
+}
+
+// recoverPanic must be deferred directly. A recovered panic is reported and
+// stored in e when e is not nil.
+func (client *Client) recoverPanic(callback string, e *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	p := &PanicError{Callback: callback, Value: r, Stack: debug.Stack()}
+	client.metrics.each(func(m Metrics) { m.ObservePanic(callback) })
+	if client.panicHandler != nil {
+		client.panicHandler(p)
+	} else {
+		client.log().Error("RedisPanic", "callback", callback, "panic", r, "stack", string(p.Stack))
+	}
+	if e != nil {
+		*e = p
+	}
+}
@@ -0,0 +1,155 @@
+package redis
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// The Into variants let hot endpoints keep their result containers between
+// calls, so the slices and maps they return are not grown anew every time.
+// They do not avoid the reply go-redis allocates: commands can only be
+// implemented inside go-redis, the reply is decoded there and copied into dst
+// afterwards. SetBytes and GetBytes skip the codec for values that are already
+// encoded.
+
+// Set encodes into pooled buffers with codecs that support it, the buffer is
+// only needed until the command has been written. Big buffers are dropped so
+// that a single large value does not stay pinned.
+var bufferPool = sync.Pool{New: func() interface{} { return new(encodeBuffer) }}
+
+// encodeBuffer keeps the JSON encoder writing into it, so a pooled buffer
+// also saves the encoder.
+type encodeBuffer struct {
+	bytes.Buffer
+	json *json.Encoder
+}
+
+const maxPooledBuffer = 64 << 10
+
+// bufferMarshaler is implemented by codecs that can encode into a buffer.
+type bufferMarshaler interface {
+	marshalTo(buf *encodeBuffer, v interface{}) error
+}
+
+func (JSONCodec) marshalTo(buf *encodeBuffer, v interface{}) error {
+	if buf.json == nil {
+		buf.json = json.NewEncoder(&buf.Buffer)
+	}
+	if e := buf.json.Encode(v); e != nil {
+		return e
+	}
+	buf.Truncate(buf.Len() - 1) // Encode ends with a newline, Marshal does not
+	return nil
+}
+
+// encode marshals v with the codec, the returned buffer goes back with
+// putBuffer once data is no longer used and is nil when the codec allocated.
+func (client *Client) encode(v interface{}) ([]byte, *encodeBuffer, error) {
+	bm, ok := client.codec.(bufferMarshaler)
+	if !ok {
+		data, e := client.codec.Marshal(v)
+		return data, nil, e
+	}
+	buf := bufferPool.Get().(*encodeBuffer)
+	buf.Reset()
+	if e := bm.marshalTo(buf, v); e != nil {
+		return nil, buf, e
+	}
+	return buf.Bytes(), buf, nil
+}
+
+func putBuffer(buf *encodeBuffer) {
+	if buf != nil && buf.Cap() <= maxPooledBuffer {
+		bufferPool.Put(buf)
+	}
+}
+
+// SetBytes stores data as is, without the codec.
+func (client *Client) SetBytes(ctx context.Context, key string, data []byte, ttl int) error {
+	return client.SetBytesFor(ctx, key, data, seconds(ttl))
+}
+
+func (client *Client) SetBytesFor(ctx context.Context, key string, data []byte, ttl time.Duration) error {
+	key_str := client.fullKey(key)
+	if e := client.checkSize("RedisSetBytes", key, data); e != nil {
+		return e
+	}
+	if e := client.client.Set(ctx, key_str, data, client.expirationFor(ttl)).Err(); e != nil {
+		return client.wrap(e, "RedisSetBytes", "set", key)
+	}
+	client.invalidateLocal(ctx, key_str)
+	return nil
+}
+
+// GetBytes appends the raw value to dst[:0] and returns it, so a caller
+// keeping dst between calls only copies the reply once.
+func (client *Client) GetBytes(ctx context.Context, key string, dst []byte) ([]byte, error) {
+	data_str, e := client.getRaw(ctx, client.fullKey(key))
+	if e != nil {
+		if e == goredis.Nil {
+			return dst[:0], ErrNotFound
+		}
+		return dst[:0], client.wrap(e, "RedisGetBytes", "get", key)
+	}
+	return append(dst[:0], data_str...), nil
+}
+
+// ZRangeWithScoresInto appends the range to dst[:0] and returns it.
+func (client *Client) ZRangeWithScoresInto(ctx context.Context, key string, start, stop int64, dst []goredis.Z) ([]goredis.Z, error) {
+	zs, e := client.reader(ctx).ZRangeWithScores(ctx, client.fullKey(key), start, stop).Result()
+	if e != nil {
+		return dst[:0], client.wrap(e, "RedisZRangeWithScores", "zrange", key)
+	}
+	return append(dst[:0], zs...), nil
+}
+
+// ZRangeScoresInto splits the range into members and scores, reusing both
+// slices.
+func (client *Client) ZRangeScoresInto(ctx context.Context, key string, start, stop int64, members []string, scores []float64) ([]string, []float64, error) {
+	members, scores = members[:0], scores[:0]
+	zs, e := client.reader(ctx).ZRangeWithScores(ctx, client.fullKey(key), start, stop).Result()
+	if e != nil {
+		return members, scores, client.wrap(e, "RedisZRangeWithScores", "zrange", key)
+	}
+	for _, z := range zs {
+		m, _ := z.Member.(string)
+		members = append(members, m)
+		scores = append(scores, z.Score)
+	}
+	return members, scores, nil
+}
+
+// SMembersInto appends the members to dst[:0] and returns it.
+func (client *Client) SMembersInto(ctx context.Context, key string, dst []string) ([]string, error) {
+	members, e := client.reader(ctx).SMembers(ctx, client.fullKey(key)).Result()
+	if e != nil {
+		return dst[:0], client.wrap(e, "RedisSMembers", "smembers", key)
+	}
+	return append(dst[:0], members...), nil
+}
+
+func (client *Client) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	fields, e := client.reader(ctx).HGetAll(ctx, client.fullKey(key)).Result()
+	if e != nil {
+		return nil, client.wrap(e, "RedisHGetAll", "hgetall", key)
+	}
+	return fields, nil
+}
+
+// HGetAllInto clears dst and fills it with the fields of the hash.
+func (client *Client) HGetAllInto(ctx context.Context, key string, dst map[string]string) error {
+	clear(dst)
+	fields, e := client.reader(ctx).HGetAll(ctx, client.fullKey(key)).Result()
+	if e != nil {
+		return client.wrap(e, "RedisHGetAll", "hgetall", key)
+	}
+	for k, v := range fields {
+		dst[k] = v
+	}
+	return nil
+}
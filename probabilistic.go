@@ -0,0 +1,176 @@
+package redis
+
+import (
+	"context"
+)
+
+// Cuckoo filters, Count-Min sketches, Top-K and t-digest need the RedisBloom
+// module.
+
+func (client *Client) CFReserve(ctx context.Context, key string, capacity int64) error {
+	key_str := client.fullKey(key)
+	if e := client.client.CFReserve(ctx, key_str, capacity).Err(); e != nil {
+		return client.wrap(e, "RedisCFReserve", "cf.reserve", key)
+	}
+	return nil
+}
+
+// CFAdd adds the item to the filter, it may be added more than once.
+func (client *Client) CFAdd(ctx context.Context, key string, item interface{}) error {
+	key_str := client.fullKey(key)
+	if e := client.client.CFAdd(ctx, key_str, item).Err(); e != nil {
+		return client.wrap(e, "RedisCFAdd", "cf.add", key)
+	}
+	return nil
+}
+
+// CFAddNX adds the item unless it probably exists and reports whether it did.
+func (client *Client) CFAddNX(ctx context.Context, key string, item interface{}) (bool, error) {
+	key_str := client.fullKey(key)
+	added, e := client.client.CFAddNX(ctx, key_str, item).Result()
+	if e != nil {
+		return false, client.wrap(e, "RedisCFAddNX", "cf.addnx", key)
+	}
+	return added, nil
+}
+
+func (client *Client) CFExists(ctx context.Context, key string, item interface{}) (bool, error) {
+	key_str := client.fullKey(key)
+	exists, e := client.client.CFExists(ctx, key_str, item).Result()
+	if e != nil {
+		return false, client.wrap(e, "RedisCFExists", "cf.exists", key)
+	}
+	return exists, nil
+}
+
+// CFDel removes one occurrence of the item, deleting an item that was never
+// added may remove another one with the same fingerprint.
+func (client *Client) CFDel(ctx context.Context, key string, item interface{}) (bool, error) {
+	key_str := client.fullKey(key)
+	deleted, e := client.client.CFDel(ctx, key_str, item).Result()
+	if e != nil {
+		return false, client.wrap(e, "RedisCFDel", "cf.del", key)
+	}
+	return deleted, nil
+}
+
+// CMSInit creates a sketch for the given error rate and probability of
+// exceeding it.
+func (client *Client) CMSInit(ctx context.Context, key string, errorRate, probability float64) error {
+	key_str := client.fullKey(key)
+	if e := client.client.CMSInitByProb(ctx, key_str, errorRate, probability).Err(); e != nil {
+		return client.wrap(e, "RedisCMSInit", "cms.initbyprob", key)
+	}
+	return nil
+}
+
+// CMSIncrBy adds the increments and returns the new estimated count of each
+// item.
+func (client *Client) CMSIncrBy(ctx context.Context, key string, increments map[string]int64) (map[string]int64, error) {
+	key_str := client.fullKey(key)
+	items := make([]string, 0, len(increments))
+	args := make([]interface{}, 0, 2*len(increments))
+	for item, n := range increments {
+		items = append(items, item)
+		args = append(args, item, n)
+	}
+	counts, e := client.client.CMSIncrBy(ctx, key_str, args...).Result()
+	if e != nil {
+		return nil, client.wrap(e, "RedisCMSIncrBy", "cms.incrby", key)
+	}
+	res := make(map[string]int64, len(items))
+	for i, item := range items {
+		if i < len(counts) {
+			res[item] = counts[i]
+		}
+	}
+	return res, nil
+}
+
+// CMSQuery returns the estimated counts of the items, in order.
+func (client *Client) CMSQuery(ctx context.Context, key string, items ...string) ([]int64, error) {
+	key_str := client.fullKey(key)
+	args := make([]interface{}, len(items))
+	for i, item := range items {
+		args[i] = item
+	}
+	counts, e := client.client.CMSQuery(ctx, key_str, args...).Result()
+	if e != nil {
+		return nil, client.wrap(e, "RedisCMSQuery", "cms.query", key)
+	}
+	return counts, nil
+}
+
+// TopKReserve creates a heavy hitter sketch keeping the k most frequent items.
+func (client *Client) TopKReserve(ctx context.Context, key string, k int64) error {
+	key_str := client.fullKey(key)
+	if e := client.client.TopKReserve(ctx, key_str, k).Err(); e != nil {
+		return client.wrap(e, "RedisTopKReserve", "topk.reserve", key)
+	}
+	return nil
+}
+
+// TopKAdd adds the items and returns the items they pushed out of the top k.
+func (client *Client) TopKAdd(ctx context.Context, key string, items ...string) ([]string, error) {
+	key_str := client.fullKey(key)
+	args := make([]interface{}, len(items))
+	for i, item := range items {
+		args[i] = item
+	}
+	dropped, e := client.client.TopKAdd(ctx, key_str, args...).Result()
+	if e != nil {
+		return nil, client.wrap(e, "RedisTopKAdd", "topk.add", key)
+	}
+	res := dropped[:0]
+	for _, d := range dropped {
+		if d != "" {
+			res = append(res, d)
+		}
+	}
+	return res, nil
+}
+
+func (client *Client) TopKList(ctx context.Context, key string) ([]string, error) {
+	key_str := client.fullKey(key)
+	items, e := client.client.TopKList(ctx, key_str).Result()
+	if e != nil {
+		return nil, client.wrap(e, "RedisTopKList", "topk.list", key)
+	}
+	return items, nil
+}
+
+// TopKListWithCount returns the top items with their estimated counts.
+func (client *Client) TopKListWithCount(ctx context.Context, key string) (map[string]int64, error) {
+	key_str := client.fullKey(key)
+	items, e := client.client.TopKListWithCount(ctx, key_str).Result()
+	if e != nil {
+		return nil, client.wrap(e, "RedisTopKList", "topk.list", key)
+	}
+	return items, nil
+}
+
+func (client *Client) TDigestCreate(ctx context.Context, key string) error {
+	key_str := client.fullKey(key)
+	if e := client.client.TDigestCreate(ctx, key_str).Err(); e != nil {
+		return client.wrap(e, "RedisTDigestCreate", "tdigest.create", key)
+	}
+	return nil
+}
+
+func (client *Client) TDigestAdd(ctx context.Context, key string, values ...float64) error {
+	key_str := client.fullKey(key)
+	if e := client.client.TDigestAdd(ctx, key_str, values...).Err(); e != nil {
+		return client.wrap(e, "RedisTDigestAdd", "tdigest.add", key)
+	}
+	return nil
+}
+
+// TDigestQuantile returns the estimated values at the quantiles, e.g. 0.99.
+func (client *Client) TDigestQuantile(ctx context.Context, key string, quantiles ...float64) ([]float64, error) {
+	key_str := client.fullKey(key)
+	values, e := client.client.TDigestQuantile(ctx, key_str, quantiles...).Result()
+	if e != nil {
+		return nil, client.wrap(e, "RedisTDigestQuantile", "tdigest.quantile", key)
+	}
+	return values, nil
+}
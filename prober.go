@@ -0,0 +1,160 @@
+package redis
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var errProbeMismatch = errors.New("redis: canary read returned another value")
+
+type ProberConfig struct {
+	Interval time.Duration // Defaults to 5 seconds
+	Timeout  time.Duration // A probe slower than this fails, defaults to one second
+	Target   time.Duration // Latency objective, probes within it count as good
+	Window   int           // Probes kept for the SLIs, defaults to 720
+	Canary   bool          // Also SET and GET <prefix>:probe:<client name>
+}
+
+type ProbeResult struct {
+	At      time.Time
+	Latency time.Duration
+	Err     error
+}
+
+type SLI struct {
+	Probes       int
+	Availability float64 // Share of probes that succeeded
+	WithinTarget float64 // Share of probes that succeeded within Target
+	P50          time.Duration
+	P95          time.Duration
+	P99          time.Duration
+	Max          time.Duration
+	Last         ProbeResult
+}
+
+// Prober measures Redis from the client side with lightweight commands on a
+// fixed interval. As the probes do not depend on application load, a slow
+// probe next to slow application calls points at Redis or the network.
+type Prober struct {
+	worker
+	client *Client
+	cfg    ProberConfig
+
+	mu      sync.Mutex
+	results []ProbeResult
+	next    int
+}
+
+// NewProber returns a stopped prober, Start runs it under ctx.
+func (client *Client) NewProber(ctx context.Context, cfg ProberConfig) *Prober {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 5 * time.Second
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = time.Second
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = 720
+	}
+	p := &Prober{client: client, cfg: cfg}
+	p.init(client, "RedisProbe", ctx, p.loop)
+	return p
+}
+
+func (p *Prober) Probe(ctx context.Context) ProbeResult {
+	ctx, cancel := context.WithTimeout(ctx, p.cfg.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	e := p.client.client.Ping(ctx).Err()
+	if e == nil && p.cfg.Canary {
+		key_str := p.client.fullKey("probe:" + p.client.config.ClientName)
+		value := strconv.FormatInt(start.UnixNano(), 10)
+		if e = p.client.client.Set(ctx, key_str, value, time.Minute).Err(); e == nil {
+			var got string
+			if got, e = p.client.client.Get(ctx, key_str).Result(); e == nil && got != value {
+				e = errProbeMismatch
+			}
+		}
+	}
+	res := ProbeResult{At: start, Latency: time.Since(start)}
+	if e != nil {
+		res.Err = p.client.wrap(e, "RedisProbe", "ping", "")
+	}
+
+	p.mu.Lock()
+	if len(p.results) < p.cfg.Window {
+		p.results = append(p.results, res)
+	} else {
+		p.results[p.next] = res
+	}
+	p.next = (p.next + 1) % p.cfg.Window
+	p.mu.Unlock()
+
+	p.client.metrics.each(func(m Metrics) { m.ObserveProbe(res.Latency, res.Err) })
+	return res
+}
+
+// SLI summarizes the probes of the window, latency percentiles are over the
+// successful probes only.
+func (p *Prober) SLI() SLI {
+	p.mu.Lock()
+	results := append([]ProbeResult(nil), p.results...)
+	last := (p.next + len(results) - 1) % max(len(results), 1)
+	p.mu.Unlock()
+
+	sli := SLI{Probes: len(results)}
+	if len(results) == 0 {
+		return sli
+	}
+	sli.Last = results[last]
+
+	var ok, good int
+	latencies := make([]time.Duration, 0, len(results))
+	for _, r := range results {
+		if r.Err != nil {
+			continue
+		}
+		ok++
+		if p.cfg.Target <= 0 || r.Latency <= p.cfg.Target {
+			good++
+		}
+		latencies = append(latencies, r.Latency)
+	}
+	sli.Availability = float64(ok) / float64(len(results))
+	sli.WithinTarget = float64(good) / float64(len(results))
+	if len(latencies) > 0 {
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		at := func(q float64) time.Duration { return latencies[int(q*float64(len(latencies)-1))] }
+		sli.P50, sli.P95, sli.P99 = at(.5), at(.95), at(.99)
+		sli.Max = latencies[len(latencies)-1]
+	}
+	return sli
+}
+
+// Run probes every Interval until ctx is done.
+func (p *Prober) Run(ctx context.Context) {
+	p.loop(ctx, func(e error) {
+		p.client.log().Warn("RedisProbe", "error", e)
+	})
+}
+
+func (p *Prober) loop(ctx context.Context, report func(error)) error {
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if res := p.Probe(ctx); res.Err != nil && ctx.Err() == nil {
+				report(res.Err)
+			}
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package redis
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type promCollector struct {
+	NopMetrics
+	client *Client
+
+	latency     *prometheus.HistogramVec
+	errors      *prometheus.CounterVec
+	cache       *prometheus.CounterVec
+	dials       *prometheus.CounterVec
+	dialLatency prometheus.Histogram
+	slow        *prometheus.CounterVec
+	probes      *prometheus.HistogramVec
+	panics      *prometheus.CounterVec
+	oversized   *prometheus.CounterVec
+
+	poolHits     *prometheus.Desc
+	poolMisses   *prometheus.Desc
+	poolTimeouts *prometheus.Desc
+	poolStale    *prometheus.Desc
+	poolTotal    *prometheus.Desc
+	poolIdle     *prometheus.Desc
+}
+
+// Collector returns the Prometheus collector of the client, the first call
+// starts recording. All views of a client share the same collector.
+func (client *Client) Collector() prometheus.Collector {
+	client.collector.once.Do(func() {
+		c := newPromCollector(client)
+		client.AddHook(c)
+		client.AddMetrics(c)
+		client.collector.c = c
+	})
+	return client.collector.c
+}
+
+type collectorOnce struct {
+	once sync.Once
+	c    *promCollector
+}
+
+func newPromCollector(client *Client) *promCollector {
+	labels := prometheus.Labels{"prefix": client.config.Prefix}
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc("redis_pool_"+name, help, nil, labels)
+	}
+	return &promCollector{
+		client: client,
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:        "redis_command_duration_seconds",
+			Help:        "Latency of Redis commands by operation.",
+			Buckets:     []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+			ConstLabels: labels,
+		}, []string{"command", "pipeline"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:        "redis_command_errors_total",
+			Help:        "Failed Redis commands by operation.",
+			ConstLabels: labels,
+		}, []string{"command"}),
+		cache: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:        "redis_cache_lookups_total",
+			Help:        "Get lookups by cache layer and result.",
+			ConstLabels: labels,
+		}, []string{"layer", "result"}),
+		dials: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:        "redis_dials_total",
+			Help:        "New connections by result.",
+			ConstLabels: labels,
+		}, []string{"result"}),
+		dialLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:        "redis_dial_duration_seconds",
+			Help:        "Time spent establishing connections.",
+			ConstLabels: labels,
+		}),
+		slow: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:        "redis_slowlog_entries_total",
+			Help:        "Commands reported by the SLOWLOG poller.",
+			ConstLabels: labels,
+		}, []string{"command"}),
+		probes: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:        "redis_probe_duration_seconds",
+			Help:        "Latency of the Prober probes by result.",
+			Buckets:     []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+			ConstLabels: labels,
+		}, []string{"result"}),
+		panics: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:        "redis_callback_panics_total",
+			Help:        "Panics recovered from user callbacks.",
+			ConstLabels: labels,
+		}, []string{"callback"}),
+		oversized: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:        "redis_oversized_values_total",
+			Help:        "Values larger than Config.MaxValueSize by operation and action.",
+			ConstLabels: labels,
+		}, []string{"op", "action"}),
+		poolHits:     desc("hits_total", "Times a free connection was found in the pool."),
+		poolMisses:   desc("misses_total", "Times a free connection was not found in the pool."),
+		poolTimeouts: desc("timeouts_total", "Times a wait for a pool connection timed out."),
+		poolStale:    desc("stale_conns_total", "Stale connections removed from the pool."),
+		poolTotal:    desc("conns", "Connections in the pool."),
+		poolIdle:     desc("idle_conns", "Idle connections in the pool."),
+	}
+}
+
+func (c *promCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.latency.Describe(ch)
+	c.errors.Describe(ch)
+	c.cache.Describe(ch)
+	c.dials.Describe(ch)
+	c.dialLatency.Describe(ch)
+	c.slow.Describe(ch)
+	c.probes.Describe(ch)
+	c.panics.Describe(ch)
+	c.oversized.Describe(ch)
+	ch <- c.poolHits
+	ch <- c.poolMisses
+	ch <- c.poolTimeouts
+	ch <- c.poolStale
+	ch <- c.poolTotal
+	ch <- c.poolIdle
+}
+
+func (c *promCollector) Collect(ch chan<- prometheus.Metric) {
+	c.latency.Collect(ch)
+	c.errors.Collect(ch)
+	c.cache.Collect(ch)
+	c.dials.Collect(ch)
+	c.dialLatency.Collect(ch)
+	c.slow.Collect(ch)
+	c.probes.Collect(ch)
+	c.panics.Collect(ch)
+	c.oversized.Collect(ch)
+
+	stats := c.client.ConnStats()
+	ch <- prometheus.MustNewConstMetric(c.poolHits, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(c.poolMisses, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(c.poolTimeouts, prometheus.CounterValue, float64(stats.Timeouts))
+	ch <- prometheus.MustNewConstMetric(c.poolStale, prometheus.CounterValue, float64(stats.StaleConns))
+	ch <- prometheus.MustNewConstMetric(c.poolTotal, prometheus.GaugeValue, float64(stats.TotalConns))
+	ch <- prometheus.MustNewConstMetric(c.poolIdle, prometheus.GaugeValue, float64(stats.IdleConns))
+}
+
+func (c *promCollector) BeforeCommand(ctx context.Context, info *CommandInfo) context.Context {
+	return ctx
+}
+
+func (c *promCollector) AfterCommand(ctx context.Context, info *CommandInfo) {
+	pipeline := "false"
+	if info.Pipeline {
+		pipeline = "true"
+	}
+	c.latency.WithLabelValues(info.Name, pipeline).Observe(info.Duration.Seconds())
+	if info.Err != nil {
+		c.errors.WithLabelValues(info.Name).Inc()
+	}
+}
+
+func (c *promCollector) ObserveDial(addr string, latency time.Duration, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	c.dials.WithLabelValues(result).Inc()
+	c.dialLatency.Observe(latency.Seconds())
+}
+
+func (c *promCollector) ObserveSlowCommand(cmd string, duration time.Duration) {
+	c.slow.WithLabelValues(cmd).Inc()
+}
+
+func (c *promCollector) ObserveCacheLookup(layer string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	c.cache.WithLabelValues(layer, result).Inc()
+}
+
+func (c *promCollector) ObserveProbe(latency time.Duration, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	c.probes.WithLabelValues(result).Observe(latency.Seconds())
+}
+
+func (c *promCollector) ObservePanic(callback string) {
+	c.panics.WithLabelValues(callback).Inc()
+}
+
+func (c *promCollector) ObserveOversizedValue(op string, size int, rejected bool) {
+	action := "warned"
+	if rejected {
+		action = "rejected"
+	}
+	c.oversized.WithLabelValues(op, action).Inc()
+}
@@ -0,0 +1,166 @@
+package redis
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// QueryCache is a two level search cache. Result id lists are stored under
+// <prefix>:qc:<name>:q:<hash of the normalized query>, the items themselves
+// under <prefix>:qc:<name>:item:<id>, so an item shared by many results is
+// stored once. Every tag of a cached result indexes the queries that contain
+// it in <prefix>:qc:<name>:tag:<tag>.
+type QueryCache struct {
+	client *Client
+	name   string
+	ttl    int
+}
+
+func (client *Client) QueryCache(name string, ttl int) *QueryCache {
+	return &QueryCache{client: client, name: name, ttl: ttl}
+}
+
+// QueryKey normalizes params: keys are sorted, empty values dropped, strings
+// trimmed and lower-cased, then the result is hashed.
+func (q *QueryCache) QueryKey(params map[string]interface{}) string {
+	names := make([]string, 0, len(params))
+	for k, v := range params {
+		if v == nil || v == "" {
+			continue
+		}
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	h := sha1.New()
+	for _, k := range names {
+		v := params[k]
+		if s, ok := v.(string); ok {
+			v = strings.ToLower(strings.TrimSpace(s))
+		}
+		data, _ := json.Marshal(v)
+		h.Write([]byte(k))
+		h.Write([]byte{0})
+		h.Write(data)
+		h.Write([]byte{0})
+	}
+	return "qc:" + q.name + ":q:" + hex.EncodeToString(h.Sum(nil))
+}
+
+func (q *QueryCache) itemKey(id string) string {
+	return "qc:" + q.name + ":item:" + id
+}
+
+func (q *QueryCache) tagKey(tag string) string {
+	return q.client.fullKey("qc:" + q.name + ":tag:" + tag)
+}
+
+// GetIDs returns the cached result ids, ErrNotFound on a miss.
+func (q *QueryCache) GetIDs(ctx context.Context, params map[string]interface{}) ([]string, error) {
+	key := q.QueryKey(params)
+	data_str, e := q.client.client.Get(ctx, q.client.fullKey(key)).Result()
+	if e != nil {
+		if e == goredis.Nil {
+			return nil, ErrNotFound
+		}
+		return nil, q.client.wrap(e, "RedisQueryCacheGet", "get", key)
+	}
+	var ids []string
+	if e := json.Unmarshal([]byte(data_str), &ids); e != nil {
+		return nil, q.client.wrap(e, "RedisQueryCacheGet:JSONUnmarshal", "", key)
+	}
+	return ids, nil
+}
+
+// SetIDs caches the result ids of a query, tags are the tags of the items in
+// the result and are used by InvalidateTags.
+func (q *QueryCache) SetIDs(ctx context.Context, params map[string]interface{}, ids []string, tags ...string) error {
+	key := q.QueryKey(params)
+	key_str := q.client.fullKey(key)
+	if ids == nil {
+		ids = []string{}
+	}
+	data_str, e := json.Marshal(ids)
+	if e != nil {
+		return q.client.wrap(e, "RedisQueryCacheSet:JSONMarshal", "", key)
+	}
+
+	// Without a ttl, or with KeepTTL, the tag index is kept until invalidated
+	expiration := q.client.expiration(q.ttl)
+	pipe := q.client.client.Pipeline()
+	pipe.Set(ctx, key_str, data_str, expiration)
+	for _, tag := range tags {
+		pipe.SAdd(ctx, q.tagKey(tag), key_str)
+		if expiration > 0 {
+			pipe.Expire(ctx, q.tagKey(tag), expiration)
+		}
+	}
+	if _, e := pipe.Exec(ctx); e != nil {
+		return q.client.wrap(e, "RedisQueryCacheSet", "set", key)
+	}
+	return nil
+}
+
+func (q *QueryCache) SetItem(ctx context.Context, id string, v interface{}) error {
+	return q.client.Set(ctx, q.itemKey(id), v, q.ttl)
+}
+
+// LoadItems decodes the cached items into the values returned by into and
+// returns the ids that were not cached, in order.
+func (q *QueryCache) LoadItems(ctx context.Context, ids []string, into func(id string) interface{}) ([]string, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	// One GET per id, items are spread over the cluster slots
+	pipe := q.client.client.Pipeline()
+	cmds := make([]*goredis.StringCmd, len(ids))
+	for i, id := range ids {
+		cmds[i] = pipe.Get(ctx, q.client.fullKey(q.itemKey(id)))
+	}
+	if _, e := pipe.Exec(ctx); e != nil && e != goredis.Nil {
+		return nil, q.client.wrap(e, "RedisQueryCacheLoadItems", "get", q.itemKey(ids[0]))
+	}
+
+	var missing []string
+	for i, cmd := range cmds {
+		data_str, e := cmd.Result()
+		if e != nil {
+			missing = append(missing, ids[i])
+			continue
+		}
+		if e := q.client.codec.Unmarshal([]byte(data_str), into(ids[i])); e != nil {
+			missing = append(missing, ids[i])
+		}
+	}
+	return missing, nil
+}
+
+func (q *QueryCache) DelItem(ctx context.Context, id string) error {
+	return q.client.Del(ctx, q.itemKey(id))
+}
+
+// InvalidateTags drops every cached result that contains an item with one of
+// the tags. Items are kept, they are invalidated with DelItem.
+func (q *QueryCache) InvalidateTags(ctx context.Context, tags ...string) error {
+	for _, tag := range tags {
+		tag_key := q.tagKey(tag)
+		queries, e := q.client.client.SMembers(ctx, tag_key).Result()
+		if e != nil {
+			return q.client.wrap(e, "RedisQueryCacheInvalidate", "smembers", "qc:"+q.name+":tag:"+tag)
+		}
+		pipe := q.client.client.Pipeline()
+		for _, k := range append(queries, tag_key) {
+			pipe.Del(ctx, k)
+		}
+		if _, e := pipe.Exec(ctx); e != nil {
+			return q.client.wrap(e, "RedisQueryCacheInvalidate", "del", "qc:"+q.name+":tag:"+tag)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/acsl-go/redis"
+	"github.com/acsl-go/redis/redistest"
+)
+
+func TestQueryCacheInvalidateTags(t *testing.T) {
+	ctx := context.Background()
+	for _, ttl := range []int{60, 0, redis.KeepTTL} {
+		client, _ := redistest.NewTestClient(t, nil)
+		q := client.QueryCache("posts", ttl)
+		params := map[string]interface{}{"author": "Ada"}
+
+		if e := q.SetIDs(ctx, params, []string{"1", "2"}, "author:ada"); e != nil {
+			t.Fatalf("ttl %d: %v", ttl, e)
+		}
+		if ids, e := q.GetIDs(ctx, params); e != nil || len(ids) != 2 {
+			t.Fatalf("ttl %d: got %v, %v", ttl, ids, e)
+		}
+		if e := q.InvalidateTags(ctx, "author:ada"); e != nil {
+			t.Fatalf("ttl %d: %v", ttl, e)
+		}
+		if _, e := q.GetIDs(ctx, params); !errors.Is(e, redis.ErrNotFound) {
+			t.Errorf("ttl %d: got %v after invalidation, want ErrNotFound", ttl, e)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+type MessageLimiterConfig struct {
+	Limit        int           // Messages allowed per id within Window, shared by all processes
+	Window       time.Duration // Length of the sliding window, defaults to one second
+	Burst        int           // Local bucket capacity, defaults to Limit
+	SyncInterval time.Duration // How often local usage is pushed to Redis, defaults to one second
+}
+
+// MessageLimiter limits high rate traffic such as websocket messages without
+// a round trip per call. Decisions are taken against a local token bucket and
+// the last known cluster wide usage, which is refreshed by Sync using a
+// sliding window counter in Redis.
+type MessageLimiter struct {
+	worker
+	client *Client
+	name   string
+	cfg    MessageLimiterConfig
+	rate   float64 // Tokens per second
+
+	mu      sync.Mutex
+	buckets map[string]*limiterBucket
+}
+
+type limiterBucket struct {
+	tokens  float64
+	last    time.Time
+	pending int64   // Allowed locally but not yet pushed to Redis
+	remote  float64 // Usage in the sliding window as of the last sync
+}
+
+// NewMessageLimiter returns a limiter that does not sync yet, Start syncs it
+// under ctx.
+func (client *Client) NewMessageLimiter(ctx context.Context, name string, cfg MessageLimiterConfig) *MessageLimiter {
+	if cfg.Window <= 0 {
+		cfg.Window = time.Second
+	}
+	if cfg.Burst <= 0 {
+		cfg.Burst = cfg.Limit
+	}
+	if cfg.SyncInterval <= 0 {
+		cfg.SyncInterval = time.Second
+	}
+	l := &MessageLimiter{
+		client:  client,
+		name:    name,
+		cfg:     cfg,
+		rate:    float64(cfg.Limit) / cfg.Window.Seconds(),
+		buckets: make(map[string]*limiterBucket),
+	}
+	l.init(client, "RedisMessageLimiter:Sync", ctx, l.loop)
+	return l
+}
+
+func (l *MessageLimiter) Allow(id string) bool {
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[id]
+	if !ok {
+		b = &limiterBucket{tokens: float64(l.cfg.Burst), last: now}
+		l.buckets[id] = b
+	}
+
+	b.tokens += now.Sub(b.last).Seconds() * l.rate
+	if b.tokens > float64(l.cfg.Burst) {
+		b.tokens = float64(l.cfg.Burst)
+	}
+	b.last = now
+
+	if b.tokens < 1 || b.remote+float64(b.pending) >= float64(l.cfg.Limit) {
+		return false
+	}
+	b.tokens--
+	b.pending++
+	return true
+}
+
+// Forget drops the local state of id, e.g. when its connection is closed.
+// Usage which has not been synced yet is lost.
+func (l *MessageLimiter) Forget(id string) {
+	l.mu.Lock()
+	delete(l.buckets, id)
+	l.mu.Unlock()
+}
+
+func (l *MessageLimiter) windowKey(id string, idx int64) string {
+	return l.client.fullKey("ratelimit:" + l.name + ":" + id + ":" + strconv.FormatInt(idx, 10))
+}
+
+// Sync pushes local usage to Redis and refreshes the shared usage estimates.
+func (l *MessageLimiter) Sync(ctx context.Context) error {
+	now := time.Now()
+	idx := now.UnixNano() / int64(l.cfg.Window)
+	elapsed := float64(now.UnixNano()%int64(l.cfg.Window)) / float64(l.cfg.Window)
+
+	l.mu.Lock()
+	ids := make([]string, 0, len(l.buckets))
+	counts := make([]int64, 0, len(l.buckets))
+	for id, b := range l.buckets {
+		// Idle ids with a full bucket carry no information worth keeping
+		if b.pending == 0 && now.Sub(b.last) > 2*l.cfg.Window {
+			delete(l.buckets, id)
+			continue
+		}
+		ids = append(ids, id)
+		counts = append(counts, b.pending)
+		b.pending = 0
+	}
+	l.mu.Unlock()
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	pipe := l.client.client.Pipeline()
+	curs := make([]*goredis.IntCmd, len(ids))
+	prevs := make([]*goredis.StringCmd, len(ids))
+	for i, id := range ids {
+		cur_key := l.windowKey(id, idx)
+		curs[i] = pipe.IncrBy(ctx, cur_key, counts[i])
+		pipe.Expire(ctx, cur_key, 2*l.cfg.Window)
+		prevs[i] = pipe.Get(ctx, l.windowKey(id, idx-1))
+	}
+	_, e := pipe.Exec(ctx)
+
+	l.mu.Lock()
+	for i, id := range ids {
+		b, ok := l.buckets[id]
+		if !ok {
+			continue
+		}
+		if curs[i].Err() != nil {
+			// Give the usage back so that it is pushed with the next sync
+			b.pending += counts[i]
+			continue
+		}
+		if e := prevs[i].Err(); e != nil && e != goredis.Nil {
+			continue
+		}
+		prev, _ := prevs[i].Int64()
+		b.remote = float64(prev)*(1-elapsed) + float64(curs[i].Val())
+	}
+	l.mu.Unlock()
+	if e != nil && e != goredis.Nil {
+		return l.client.wrap(e, "RedisMessageLimiterSync", "incrby", l.name)
+	}
+	return nil
+}
+
+// Run syncs every SyncInterval until ctx is done.
+func (l *MessageLimiter) Run(ctx context.Context) {
+	l.loop(ctx, func(e error) {
+		l.client.log().Warn("RedisMessageLimiter:Sync", "limiter", l.name, "error", e)
+	})
+}
+
+func (l *MessageLimiter) loop(ctx context.Context, report func(error)) error {
+	ticker := time.NewTicker(l.cfg.SyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if e := l.Sync(ctx); e != nil && ctx.Err() == nil {
+				report(e)
+			}
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package redis
+
+import (
+	"context"
+	"time"
+)
+
+const maxConnectRetryInterval = 30 * time.Second
+
+func (client *Client) markReady() {
+	client.readyOnce.Do(func() { close(client.ready) })
+}
+
+// IsReady reports whether the server answered a PING since the client was created.
+func (client *Client) IsReady() bool {
+	select {
+	case <-client.ready:
+		return true
+	default:
+		return false
+	}
+}
+
+// WaitReady blocks until the client is ready or ctx is done.
+func (client *Client) WaitReady(ctx context.Context) error {
+	select {
+	case <-client.ready:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Used with Config.LazyConnect, pings with exponential backoff until the server
+// is reachable.
+func (client *Client) connectLoop(ctx context.Context) {
+	interval := client.config.ConnectRetryInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	for {
+		e := client.client.Ping(ctx).Err()
+		if e == nil {
+			if e := client.loadFunctions(ctx); e != nil {
+				client.log().Warn("RedisConnect:FunctionLoad", "error", e)
+			}
+			client.markReady()
+			return
+		}
+		client.log().Warn("RedisConnect", "retry_in", interval, "error", e)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+		if interval *= 2; interval > maxConnectRetryInterval {
+			interval = maxConnectRetryInterval
+		}
+	}
+}
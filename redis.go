@@ -2,8 +2,8 @@ package redis
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -11,93 +11,332 @@ import (
 )
 
 type Client struct {
-	client goredis.UniversalClient
-	config *Config
+	client   goredis.UniversalClient
+	config   *Config
+	metrics  *metricsList
+	counters *connCounters
+	local    *localCache
+
+	ready     chan struct{}
+	readyOnce *sync.Once
+
+	lifecycle *lifecycle
+	hooks     *hookList
+	collector *collectorOnce
+	admin     *adminOnce
+	modules   *moduleInfo
+	breaker   *breaker
+
+	codec        Codec
+	defaultTTL   int
+	ttlJitter    float64
+	schemas      SchemaRegistry
+	panicHandler PanicHandler
+	replicas     *replicaSet
+	readPref     ReadPreference
+	tenant       *tenantScope
+	rootPrefix   string // Prefix of NewClient, views keep it
 }
 
+// ErrNotFound is returned for missing keys, members and fields by the single
+// value reads. Collection reads such as HGetAll, SMembers or ZRange return
+// empty results instead, as Redis answers an empty collection and a missing
+// key alike. Any wrapped goredis.Nil also matches it with errors.Is.
 var (
 	ErrNotFound = errors.New("redis: key not found")
 )
 
-func NewClient(cfg *Config) (*Client, error) {
-	client := goredis.NewUniversalClient(&goredis.UniversalOptions{
-		Addrs:    cfg.Addresses,
-		Password: cfg.Password,
-		DB:       cfg.DB,
-	})
+func NewClient(cfg *Config, opts ...Option) (*Client, error) {
+	cfg, err := cfg.withURL()
+	if err != nil {
+		return nil, err
+	}
 
-	_, err := client.Ping(context.Background()).Result()
+	tlsCfg, err := cfg.TLS.build()
 	if err != nil {
-		return nil, errors.Wrap(err, "redis: failed to ping")
+		return nil, err
+	}
+
+	uopts := goredis.UniversalOptions{
+		Addrs:            cfg.Addresses,
+		ClientName:       cfg.ClientName,
+		Username:         cfg.Username,
+		Password:         cfg.Password,
+		DB:               cfg.DB,
+		TLSConfig:        tlsCfg,
+		MasterName:       cfg.MasterName,
+		SentinelUsername: cfg.SentinelUsername,
+		SentinelPassword: cfg.SentinelPassword,
+		MaxRedirects:     cfg.MaxRedirects,
+		ReadOnly:         cfg.ReadOnly,
+		RouteByLatency:   cfg.RouteByLatency,
+		RouteRandomly:    cfg.RouteRandomly,
+		PoolSize:         cfg.PoolSize,
+		MinIdleConns:     cfg.MinIdleConns,
+		MaxIdleConns:     cfg.MaxIdleConns,
+		MaxActiveConns:   cfg.MaxActiveConns,
+		ConnMaxIdleTime:  cfg.ConnMaxIdleTime,
+		ConnMaxLifetime:  cfg.ConnMaxLifetime,
+		PoolTimeout:      cfg.PoolTimeout,
+		DialTimeout:      cfg.DialTimeout,
+		ReadTimeout:      cfg.ReadTimeout,
+		WriteTimeout:     cfg.WriteTimeout,
+		MaxRetries:       maxRetries(cfg),
+	}
+	client := newUniversalClient(cfg, &uopts)
+
+	c := &Client{
+		client:    client,
+		config:    cfg,
+		metrics:   &metricsList{},
+		counters:  &connCounters{},
+		ready:     make(chan struct{}),
+		readyOnce: &sync.Once{},
+		lifecycle: newLifecycle(),
+		hooks:     &hookList{},
+		collector: &collectorOnce{},
+		admin:     &adminOnce{},
+		modules:   &moduleInfo{},
+		codec:     JSONCodec{},
+		ttlJitter: cfg.TTLJitter,
+	}
+	c.rootPrefix = cfg.Prefix
+	for _, opt := range opts {
+		opt(c)
+	}
+	if cfg.Compression.Algorithm != "" {
+		if cfg.RedisJSON {
+			return nil, errors.New("redis: compression can not be combined with RedisJSON documents")
+		}
+		codec, err := NewCompressedCodec(c.codec, cfg.Compression)
+		if err != nil {
+			return nil, err
+		}
+		c.codec = codec
+	}
+	if cfg.Metrics != nil {
+		c.AddMetrics(cfg.Metrics)
+	}
+	client.AddHook(&lifecycleHook{lc: c.lifecycle})
+	// Always installed, WithOperationTimeout works without configured timeouts
+	client.AddHook(&timeoutHook{client: c})
+	if cfg.Breaker.Enabled {
+		c.breaker = newBreaker(cfg.Breaker)
+		client.AddHook(&breakerHook{b: c.breaker})
+	}
+	client.AddHook(&metricsHook{client: c})
+	client.AddHook(&commandHook{client: c})
+	for _, h := range cfg.Hooks {
+		c.AddHook(h)
+	}
+	if cfg.Tracing {
+		c.EnableTracing(nil)
+	}
+	if cfg.Retry.MaxRetries > 0 {
+		client.AddHook(&retryHook{cfg: &cfg.Retry})
+	}
+	if cfg.InvalidateResultCache {
+		h := newResultCacheHook(c)
+		client.AddHook(h)
+		go h.run(c.lifecycle.ctx)
+	}
+	if cfg.TouchTracking.SampleRate > 0 {
+		t := newTouchTracker(c, cfg.TouchTracking)
+		client.AddHook(&touchHook{t: t})
+		go t.run(c.lifecycle.ctx)
 	}
 
-	return &Client{
-		client: client,
-		config: cfg,
-	}, nil
+	if len(cfg.Replicas) > 0 {
+		c.replicas = c.newReplicaSet(uopts)
+	}
+
+	if cfg.LazyConnect {
+		go c.connectLoop(c.lifecycle.ctx)
+	} else {
+		_, err = client.Ping(context.Background()).Result()
+		if err != nil {
+			c.Close()
+			return nil, errors.Wrap(err, "redis: failed to ping")
+		}
+		if err = c.loadFunctions(context.Background()); err != nil {
+			c.Close()
+			return nil, err
+		}
+		c.markReady()
+	}
+
+	if cfg.LocalCache.Size > 0 {
+		c.local = newLocalCache(cfg.LocalCache.Size, time.Duration(cfg.LocalCache.TTL)*time.Second, cfg.LocalCache.TinyLFU)
+		go c.watchInvalidations(c.lifecycle.ctx, uopts)
+	}
+
+	return c, nil
+}
+
+// Our own retry policy replaces the one of go-redis, -1 disables the latter.
+func maxRetries(cfg *Config) int {
+	if cfg.Retry.MaxRetries > 0 {
+		return -1
+	}
+	return 0
+}
+
+// UniversalOptions drops the replica routing flags for sentinel setups, so
+// those go through a failover cluster client which is able to honor them.
+// Cluster mode is normally picked by the number of addresses, Config.Cluster
+// forces it for a single seed address such as a cluster endpoint.
+func newUniversalClient(cfg *Config, opts *goredis.UniversalOptions) goredis.UniversalClient {
+	if cfg.MasterName == "" {
+		if cfg.Cluster {
+			return goredis.NewClusterClient(opts.Cluster())
+		}
+		return goredis.NewUniversalClient(opts)
+	}
+
+	failover := opts.Failover()
+	failover.RouteByLatency = cfg.RouteByLatency
+	failover.RouteRandomly = cfg.RouteRandomly
+	failover.ReplicaOnly = cfg.ReplicaOnly
+	failover.UseDisconnectedReplicas = cfg.UseDisconnectedReplicas
+	if cfg.RouteByLatency || cfg.RouteRandomly {
+		return goredis.NewFailoverClusterClient(failover)
+	}
+	return goredis.NewFailoverClient(failover)
 }
 
 func (client *Client) Get(ctx context.Context, key string, v interface{}) error {
-	key_str := client.config.Prefix + ":" + key
-	data_str, e := client.client.Get(ctx, key_str).Result()
+	key_str := client.fullKey(key)
+	cmd, fetch := client.fetcher(ctx)
+	data_str, e := client.getCached(ctx, key_str, fetch)
 	if e != nil {
 		if e == goredis.Nil {
 			return ErrNotFound
 		}
-		return errors.Wrap(e, "RedisGet")
+		return client.wrap(e, "RedisGet", cmd, key)
 	}
 
+	data_str = stripMeta(data_str)
 	if data_str == "" {
 		return ErrNotFound
 	}
 
-	if e := json.Unmarshal([]byte(data_str), v); e != nil {
-		return errors.Wrap(e, "RedisGet:JSONUnmarshal")
+	if e := client.codec.Unmarshal([]byte(data_str), v); e != nil {
+		return client.wrap(e, "RedisGet:JSONUnmarshal", "", key)
 	}
 
 	return nil
 }
 
-func (client *Client) Expire(ctx context.Context, key string, ttl int) error {
-	if e := client.client.Expire(ctx, key, time.Duration(ttl)*time.Second).Err(); e != nil {
-		return errors.Wrap(e, "RedisExpire")
+func (client *Client) getRaw(ctx context.Context, key_str string) (string, error) {
+	_, fetch := client.fetcher(ctx)
+	return client.getCached(ctx, key_str, fetch)
+}
+
+// fetcher returns the read of a single value, the command named in errors
+// and the function.
+func (client *Client) fetcher(ctx context.Context) (string, func(ctx context.Context, key string) *goredis.StringCmd) {
+	if client.jsonDocs(ctx) {
+		return "json.get", client.getDoc
 	}
-	return nil
+	return "get", client.reader(ctx).Get
+}
+
+func (client *Client) getCached(ctx context.Context, key_str string, fetch func(ctx context.Context, key string) *goredis.StringCmd) (string, error) {
+	var f *localFetch
+	if client.local != nil {
+		data_str, ok := client.local.get(key_str)
+		client.metrics.each(func(m Metrics) { m.ObserveCacheLookup("local", ok) })
+		if ok {
+			return data_str, nil
+		}
+		f = client.local.fetch(key_str)
+	}
+	data_str, e := fetch(ctx, key_str).Result()
+	if e == nil || e == goredis.Nil {
+		client.metrics.each(func(m Metrics) { m.ObserveCacheLookup("redis", e == nil) })
+	}
+	if f == nil {
+		return data_str, e
+	}
+	if e != nil {
+		client.local.fetched(key_str, f)
+		return "", e
+	}
+	// Entries must not outlive the key, PTTL is only paid on a local miss.
+	// -2 means it is already gone.
+	ttl, ttl_err := client.reader(ctx).PTTL(ctx, key_str).Result()
+	if client.local.fetched(key_str, f) && ttl_err == nil && ttl != -2 {
+		client.local.set(key_str, data_str, ttl)
+	}
+	return data_str, nil
 }
 
 func (client *Client) SetEx(ctx context.Context, key string, v interface{}, ttl int) (string, error) {
-	key_str := client.config.Prefix + ":" + key
-	data_str, e := json.Marshal(v)
+	return client.SetExFor(ctx, key, v, seconds(ttl))
+}
+
+// SetExFor is SetEx with a ttl of millisecond precision, 0 still means the
+// default TTL.
+func (client *Client) SetExFor(ctx context.Context, key string, v interface{}, ttl time.Duration) (string, error) {
+	return client.setEx(ctx, key, v, ttl, true)
+}
+
+// setEx returns the encoded value only with keep, Set and SetFor skip the
+// copy and let the encode buffer go back to the pool.
+func (client *Client) setEx(ctx context.Context, key string, v interface{}, ttl time.Duration, keep bool) (string, error) {
+	key_str := client.fullKey(key)
+	data_str, buf, e := client.encode(v)
+	defer putBuffer(buf)
 	if e != nil {
-		return "", errors.Wrap(e, "RedisSetEx:JSONMarshal")
+		return "", client.wrap(e, "RedisSetEx:JSONMarshal", "", key)
+	}
+	if e := client.checkSize("RedisSetEx", key, data_str); e != nil {
+		return "", e
 	}
 
-	if e := client.client.Set(ctx, key_str, data_str, time.Duration(ttl)*time.Second).Err(); e != nil {
-		return "", errors.Wrap(e, "RedisSetEx")
+	if client.jsonDocs(ctx) {
+		if _, e := client.setDoc(ctx, key_str, string(data_str), "", client.expirationFor(ttl), false); e != nil {
+			return "", client.wrap(e, "RedisSetEx", "json.set", key)
+		}
+	} else if e := client.client.Set(ctx, key_str, data_str, client.expirationFor(ttl)).Err(); e != nil {
+		return "", client.wrap(e, "RedisSetEx", "set", key)
 	}
+	client.invalidateLocal(ctx, key_str)
 
+	if !keep {
+		return "", nil
+	}
 	return string(data_str), nil
 }
 
 func (client *Client) SetNXEx(ctx context.Context, key string, v interface{}, ttl int) (bool, string, error) {
-	key_str := client.config.Prefix + ":" + key
-	data_str, e := json.Marshal(v)
+	return client.SetNXExFor(ctx, key, v, seconds(ttl))
+}
+
+func (client *Client) SetNXExFor(ctx context.Context, key string, v interface{}, ttl time.Duration) (bool, string, error) {
+	data_str, e := client.codec.Marshal(v)
 	if e != nil {
-		return false, "", errors.Wrap(e, "RedisSetNXEx:JSONMarshal")
+		return false, "", client.wrap(e, "RedisSetNXEx:JSONMarshal", "", key)
+	}
+	if e := client.checkSize("RedisSetNXEx", key, data_str); e != nil {
+		return false, "", e
 	}
 
-	if e := client.client.SetNX(ctx, key_str, data_str, time.Duration(ttl)*time.Second).Err(); e != nil {
-		if e == goredis.Nil {
-			return false, "", nil
-		}
-		return false, "", errors.Wrap(e, "RedisSetNXEx")
+	r, e := client.trySet(ctx, "RedisSetNXEx", key, string(data_str), ttl, false, client.jsonDocs(ctx))
+	if e != nil {
+		return false, "", e
 	}
 
-	return true, string(data_str), nil
+	return r.IsSet(), r.Value, nil
 }
 
 func (client *Client) Set(ctx context.Context, key string, v interface{}, ttl int) error {
-	_, e := client.SetEx(ctx, key, v, ttl)
+	_, e := client.setEx(ctx, key, v, seconds(ttl), false)
+	return e
+}
+
+func (client *Client) SetFor(ctx context.Context, key string, v interface{}, ttl time.Duration) error {
+	_, e := client.setEx(ctx, key, v, ttl, false)
 	return e
 }
 
@@ -106,133 +345,297 @@ func (client *Client) SetNX(ctx context.Context, key string, v interface{}, ttl
 	return b, e
 }
 
-func (client *Client) SetNXStr(ctx context.Context, key string, v string, ttl int) (bool, error) {
-	key_str := client.config.Prefix + ":" + key
-	if e := client.client.SetNX(ctx, key_str, v, time.Duration(ttl)*time.Second).Err(); e != nil {
-		if e == goredis.Nil {
-			return false, nil
+func (client *Client) SetNXFor(ctx context.Context, key string, v interface{}, ttl time.Duration) (bool, error) {
+	b, _, e := client.SetNXExFor(ctx, key, v, ttl)
+	return b, e
+}
+
+// SetXX stores v only when key already exists, false when it does not. With
+// KeepTTL as ttl the current expiry is kept.
+func (client *Client) SetXX(ctx context.Context, key string, v interface{}, ttl int) (bool, error) {
+	return client.SetXXFor(ctx, key, v, seconds(ttl))
+}
+
+func (client *Client) SetXXFor(ctx context.Context, key string, v interface{}, ttl time.Duration) (bool, error) {
+	key_str := client.fullKey(key)
+	data_str, buf, e := client.encode(v)
+	defer putBuffer(buf)
+	if e != nil {
+		return false, client.wrap(e, "RedisSetXX:JSONMarshal", "", key)
+	}
+	if e := client.checkSize("RedisSetXX", key, data_str); e != nil {
+		return false, e
+	}
+
+	var ok bool
+	if client.jsonDocs(ctx) {
+		r, e := client.setDoc(ctx, key_str, string(data_str), "XX", client.expirationFor(ttl), false)
+		if e != nil {
+			return false, client.wrap(e, "RedisSetXX", "json.set", key)
 		}
-		return false, errors.Wrap(e, "RedisSetNX")
+		ok = r.set
+	} else if ok, e = client.client.SetXX(ctx, key_str, data_str, client.expirationFor(ttl)).Result(); e != nil {
+		return false, client.wrap(e, "RedisSetXX", "set", key)
 	}
-	return true, nil
+	if ok {
+		client.invalidateLocal(ctx, key_str)
+	}
+	return ok, nil
+}
+
+func (client *Client) SetNXStr(ctx context.Context, key string, v string, ttl int) (bool, error) {
+	return client.SetNXStrFor(ctx, key, v, seconds(ttl))
+}
+
+func (client *Client) SetNXStrFor(ctx context.Context, key string, v string, ttl time.Duration) (bool, error) {
+	r, e := client.trySet(ctx, "RedisSetNX", key, v, ttl, false, false)
+	if e != nil {
+		return false, e
+	}
+	return r.IsSet(), nil
 }
 
 func (client *Client) SetStr(ctx context.Context, key string, v string, ttl int) error {
-	key_str := client.config.Prefix + ":" + key
-	if e := client.client.Set(ctx, key_str, v, time.Duration(ttl)*time.Second).Err(); e != nil {
-		return errors.Wrap(e, "RedisSetStr")
+	return client.SetStrFor(ctx, key, v, seconds(ttl))
+}
+
+func (client *Client) SetStrFor(ctx context.Context, key string, v string, ttl time.Duration) error {
+	key_str := client.fullKey(key)
+	if e := client.client.Set(ctx, key_str, v, client.expirationFor(ttl)).Err(); e != nil {
+		return client.wrap(e, "RedisSetStr", "set", key)
 	}
+	client.invalidateLocal(ctx, key_str)
 	return nil
 }
 
 func (client *Client) GetStr(ctx context.Context, key string) (string, error) {
-	key_str := client.config.Prefix + ":" + key
-	data_str, e := client.client.Get(ctx, key_str).Result()
+	key_str := client.fullKey(key)
+	data_str, e := client.getRaw(ctx, key_str)
 	if e != nil {
 		if e == goredis.Nil {
 			return "", ErrNotFound
 		}
-		return "", errors.Wrap(e, "RedisGetStr")
+		return "", client.wrap(e, "RedisGetStr", "get", key)
 	}
 	return data_str, nil
 }
 
 func (client *Client) Del(ctx context.Context, key string) error {
-	key_str := client.config.Prefix + ":" + key
+	key_str := client.fullKey(key)
 	if e := client.client.Del(ctx, key_str).Err(); e != nil {
-		return errors.Wrap(e, "RedisDel")
+		return client.wrap(e, "RedisDel", "del", key)
 	}
+	client.invalidateLocal(ctx, key_str)
 	return nil
 }
 
 func (client *Client) TTL(ctx context.Context, key string) (time.Duration, error) {
-	key_str := client.config.Prefix + ":" + key
-	ttl, e := client.client.TTL(ctx, key_str).Result()
+	key_str := client.fullKey(key)
+	ttl, e := client.reader(ctx).TTL(ctx, key_str).Result()
 	if e != nil {
 		if e == goredis.Nil {
 			return 0, ErrNotFound
 		}
-		return 0, errors.Wrap(e, "RedisTTL")
+		return 0, client.wrap(e, "RedisTTL", "ttl", key)
 	}
 	return ttl, nil
 }
 
+// Exists returns how many of the keys exist, a key named twice counts twice.
+func (client *Client) Exists(ctx context.Context, keys ...string) (int64, error) {
+	if e := client.checkSlots(keys...); e != nil {
+		return 0, client.wrap(e, "RedisExists", "exists", firstKey(keys))
+	}
+	key_strs := make([]string, len(keys))
+	for i, key := range keys {
+		key_strs[i] = client.fullKey(key)
+	}
+	n, e := client.reader(ctx).Exists(ctx, key_strs...).Result()
+	if e != nil {
+		return 0, client.wrap(e, "RedisExists", "exists", firstKey(keys))
+	}
+	return n, nil
+}
+
+// Persist removes the expiry of key, false when the key does not exist or has
+// none.
+func (client *Client) Persist(ctx context.Context, key string) (bool, error) {
+	ok, e := client.client.Persist(ctx, client.fullKey(key)).Result()
+	if e != nil {
+		return false, client.wrap(e, "RedisPersist", "persist", key)
+	}
+	return ok, nil
+}
+
+// Type returns string, list, set, zset, hash or stream, ErrNotFound when the
+// key does not exist.
+func (client *Client) Type(ctx context.Context, key string) (string, error) {
+	t, e := client.reader(ctx).Type(ctx, client.fullKey(key)).Result()
+	if e != nil {
+		return "", client.wrap(e, "RedisType", "type", key)
+	}
+	if t == "none" {
+		return "", ErrNotFound
+	}
+	return t, nil
+}
+
+// Rename moves key to newKey, overwriting newKey. ErrNotFound when key does
+// not exist. In cluster mode both keys must hash to the same slot.
+func (client *Client) Rename(ctx context.Context, key, newKey string) error {
+	if e := client.checkSlots(key, newKey); e != nil {
+		return client.wrap(e, "RedisRename", "rename", key)
+	}
+	key_str, new_str := client.fullKey(key), client.fullKey(newKey)
+	if e := client.client.Rename(ctx, key_str, new_str).Err(); e != nil {
+		if isNoSuchKey(e) {
+			return ErrNotFound
+		}
+		return client.wrap(e, "RedisRename", "rename", key)
+	}
+	client.invalidateLocal(ctx, key_str)
+	client.invalidateLocal(ctx, new_str)
+	return nil
+}
+
+// RenameNX is Rename that returns false instead of overwriting an existing
+// newKey.
+func (client *Client) RenameNX(ctx context.Context, key, newKey string) (bool, error) {
+	if e := client.checkSlots(key, newKey); e != nil {
+		return false, client.wrap(e, "RedisRenameNX", "renamenx", key)
+	}
+	key_str, new_str := client.fullKey(key), client.fullKey(newKey)
+	ok, e := client.client.RenameNX(ctx, key_str, new_str).Result()
+	if e != nil {
+		if isNoSuchKey(e) {
+			return false, ErrNotFound
+		}
+		return false, client.wrap(e, "RedisRenameNX", "renamenx", key)
+	}
+	if ok {
+		client.invalidateLocal(ctx, key_str)
+		client.invalidateLocal(ctx, new_str)
+	}
+	return ok, nil
+}
+
+func isNoSuchKey(e error) bool {
+	return e != nil && strings.HasPrefix(e.Error(), "ERR no such key")
+}
+
 func (client *Client) SAdd(ctx context.Context, key string, members ...interface{}) error {
-	key_str := client.config.Prefix + ":" + key
+	key_str := client.fullKey(key)
 	if e := client.client.SAdd(ctx, key_str, members...).Err(); e != nil {
-		return errors.Wrap(e, "RedisSAdd")
+		return client.wrap(e, "RedisSAdd", "sadd", key)
 	}
 	return nil
 }
 
 func (client *Client) SCard(ctx context.Context, key string) int64 {
-	key_str := client.config.Prefix + ":" + key
-	return client.client.SCard(ctx, key_str).Val()
+	key_str := client.fullKey(key)
+	return client.reader(ctx).SCard(ctx, key_str).Val()
 }
 
 func (client *Client) SRem(ctx context.Context, key string, members ...interface{}) error {
-	key_str := client.config.Prefix + ":" + key
+	key_str := client.fullKey(key)
 	if e := client.client.SRem(ctx, key_str, members...).Err(); e != nil {
-		return errors.Wrap(e, "RedisSRemove")
+		return client.wrap(e, "RedisSRemove", "srem", key)
 	}
 	return nil
 }
 
 func (client *Client) SIsMember(ctx context.Context, key string, member interface{}) (bool, error) {
-	key_str := client.config.Prefix + ":" + key
-	has, e := client.client.SIsMember(ctx, key_str, member).Result()
+	key_str := client.fullKey(key)
+	has, e := client.reader(ctx).SIsMember(ctx, key_str, member).Result()
 	if e != nil {
-		return false, errors.Wrap(e, "RedisSHas")
+		return false, client.wrap(e, "RedisSHas", "sismember", key)
+	}
+	return has, nil
+}
+
+// SMIsMember checks the members in one round trip, with pipelined SISMEMBER
+// on servers older than 6.2.
+func (client *Client) SMIsMember(ctx context.Context, key string, members ...interface{}) ([]bool, error) {
+	key_str := client.fullKey(key)
+	if len(members) == 0 {
+		return nil, nil
+	}
+	if !client.modules.unsupported("smismember") {
+		has, e := client.client.SMIsMember(ctx, key_str, members...).Result()
+		if e == nil {
+			return has, nil
+		}
+		if !isUnknownCommand(e) {
+			return nil, client.wrap(e, "RedisSMIsMember", "smismember", key)
+		}
+		client.modules.markUnsupported("smismember")
+	}
+
+	pipe := client.client.Pipeline()
+	cmds := make([]*goredis.BoolCmd, len(members))
+	for i, member := range members {
+		cmds[i] = pipe.SIsMember(ctx, key_str, member)
+	}
+	if _, e := pipe.Exec(ctx); e != nil {
+		return nil, client.wrap(e, "RedisSMIsMember", "sismember", key)
+	}
+	has := make([]bool, len(cmds))
+	for i, cmd := range cmds {
+		has[i] = cmd.Val()
 	}
 	return has, nil
 }
 
 func (client *Client) SMembers(ctx context.Context, key string) []string {
-	key_str := client.config.Prefix + ":" + key
-	return client.client.SMembers(ctx, key_str).Val()
+	key_str := client.fullKey(key)
+	return client.reader(ctx).SMembers(ctx, key_str).Val()
 }
 
 func (client *Client) Incr(ctx context.Context, key string) (int64, error) {
-	key_str := client.config.Prefix + ":" + key
+	key_str := client.fullKey(key)
 	val, e := client.client.Incr(ctx, key_str).Result()
 	if e != nil {
-		return 0, errors.Wrap(e, "RedisIncr")
+		return 0, client.wrap(e, "RedisIncr", "incr", key)
 	}
+	client.invalidateLocal(ctx, key_str)
 	return val, nil
 }
 
 func (client *Client) IncrEx(ctx context.Context, key string, ttl int) (int64, error) {
-	key_str := client.config.Prefix + ":" + key
+	key_str := client.fullKey(key)
 	val, e := client.client.Incr(ctx, key_str).Result()
 	if e != nil {
-		return 0, errors.Wrap(e, "RedisIncr")
+		return 0, client.wrap(e, "RedisIncr", "incr", key)
 	}
+	client.invalidateLocal(ctx, key_str)
 	if ttl > 0 {
-		if e := client.Expire(ctx, key, ttl); e != nil {
-			fmt.Printf("RedisIncr:Expire: %v\n", e) // Only Output Error
+		if e := client.Expire(ctx, key, time.Duration(ttl)*time.Second); e != nil {
+			client.log().Warn("RedisIncr:Expire", "key", client.logKey(key), "error", e)
 		}
 	}
 	return val, nil
 }
 
 func (client *Client) Decr(ctx context.Context, key string) (int64, error) {
-	key_str := client.config.Prefix + ":" + key
+	key_str := client.fullKey(key)
 	val, e := client.client.Decr(ctx, key_str).Result()
 	if e != nil {
-		return 0, errors.Wrap(e, "RedisDecr")
+		return 0, client.wrap(e, "RedisDecr", "decr", key)
 	}
+	client.invalidateLocal(ctx, key_str)
 	return val, nil
 }
 
 func (client *Client) DecrEx(ctx context.Context, key string, ttl int) (int64, error) {
-	key_str := client.config.Prefix + ":" + key
+	key_str := client.fullKey(key)
 	val, e := client.client.Decr(ctx, key_str).Result()
 	if e != nil {
-		return 0, errors.Wrap(e, "RedisDecr")
+		return 0, client.wrap(e, "RedisDecr", "decr", key)
 	}
+	client.invalidateLocal(ctx, key_str)
 	if ttl > 0 {
-		if e := client.Expire(ctx, key, ttl); e != nil {
-			fmt.Printf("RedisDecr:Expire: %v\n", e) // Only Output Error
+		if e := client.Expire(ctx, key, time.Duration(ttl)*time.Second); e != nil {
+			client.log().Warn("RedisDecr:Expire", "key", client.logKey(key), "error", e)
 		}
 	}
 	return val, nil
@@ -0,0 +1,45 @@
+// Package redistest runs a redis.Client against an in-memory miniredis server,
+// so cache logic can be unit tested without a running Redis.
+//
+//	client, mr := redistest.NewTestClient(t, nil)
+//	client.SetStr(ctx, "k", "v", 60)
+//	mr.FastForward(time.Minute) // expire it
+//
+// miniredis covers the core commands and Lua, modules such as RedisJSON,
+// client side tracking and functions are not available.
+package redistest
+
+import (
+	"testing"
+
+	"github.com/acsl-go/redis"
+	"github.com/alicebob/miniredis/v2"
+)
+
+// NewTestClient starts a miniredis server and connects a client to it, both
+// are closed when the test ends. A nil cfg uses the prefix "test", the
+// addresses of cfg are replaced by the server's.
+func NewTestClient(tb testing.TB, cfg *redis.Config, opts ...redis.Option) (*redis.Client, *miniredis.Miniredis) {
+	tb.Helper()
+
+	mr := miniredis.RunT(tb)
+
+	c := redis.Config{Prefix: "test"}
+	if cfg != nil {
+		c = *cfg
+	}
+	c.URL = ""
+	c.Addresses = []string{mr.Addr()}
+	c.Cluster = false
+	c.MasterName = ""
+	c.Replicas = nil
+
+	client, e := redis.NewClient(&c, opts...)
+	if e != nil {
+		tb.Fatalf("redistest: %v", e)
+	}
+	tb.Cleanup(func() {
+		client.Close()
+	})
+	return client, mr
+}
@@ -0,0 +1,175 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// TaggedValue is a value stored together with the region that wrote it.
+type TaggedValue struct {
+	Region string          `json:"region"`
+	Time   time.Time       `json:"time"`
+	Value  json.RawMessage `json:"value"`
+}
+
+func (tv *TaggedValue) Decode(v interface{}) error {
+	return json.Unmarshal(tv.Value, v)
+}
+
+// ConflictResolver picks or merges the value to keep from concurrent writes of
+// several regions.
+type ConflictResolver func(writes []TaggedValue) (interface{}, error)
+
+// LastWriteWins keeps the newest write, ties are broken by region name so that
+// every region resolves to the same value.
+func LastWriteWins(writes []TaggedValue) (interface{}, error) {
+	sort.Slice(writes, func(i, j int) bool {
+		if !writes[i].Time.Equal(writes[j].Time) {
+			return writes[i].Time.After(writes[j].Time)
+		}
+		return writes[i].Region < writes[j].Region
+	})
+	return writes[0].Value, nil
+}
+
+// Besides the value itself every region records its latest write in the
+// companion hash <prefix>:origin:<key>, field = region. With active-active
+// replication the hash merges per field, so concurrent writes stay visible.
+func (client *Client) originKey(key string) string {
+	return client.fullKey("origin:" + key)
+}
+
+// SetTagged stores v wrapped in a TaggedValue of Config.Region.
+func (client *Client) SetTagged(ctx context.Context, key string, v interface{}, ttl int) error {
+	data, e := json.Marshal(v)
+	if e != nil {
+		return client.wrap(e, "RedisSetTagged:JSONMarshal", "", key)
+	}
+	return client.setTagged(ctx, "RedisSetTagged", key, data, ttl)
+}
+
+func (client *Client) setTagged(ctx context.Context, op, key string, data []byte, ttl int) error {
+	tv := &TaggedValue{Region: client.config.Region, Time: time.Now().UTC(), Value: data}
+	data_str, e := json.Marshal(tv)
+	if e != nil {
+		return client.wrap(e, op+":JSONMarshal", "", key)
+	}
+
+	key_str := client.fullKey(key)
+	origin_key := client.originKey(key)
+	expiration := client.expiration(ttl)
+	pipe := client.client.Pipeline()
+	pipe.Set(ctx, key_str, data_str, expiration)
+	pipe.HSet(ctx, origin_key, tv.Region, data_str)
+	if expiration > 0 {
+		pipe.Expire(ctx, origin_key, expiration)
+	}
+	if _, e := pipe.Exec(ctx); e != nil {
+		return client.wrap(e, op, "set", key)
+	}
+	client.invalidateLocal(ctx, key_str)
+	return nil
+}
+
+// GetTagged decodes a value written by SetTagged into v and returns its tag.
+func (client *Client) GetTagged(ctx context.Context, key string, v interface{}) (*TaggedValue, error) {
+	data_str, e := client.client.Get(ctx, client.fullKey(key)).Result()
+	if e != nil {
+		if e == goredis.Nil {
+			return nil, ErrNotFound
+		}
+		return nil, client.wrap(e, "RedisGetTagged", "get", key)
+	}
+	tv := &TaggedValue{}
+	if e := json.Unmarshal([]byte(data_str), tv); e != nil {
+		return nil, client.wrap(e, "RedisGetTagged:JSONUnmarshal", "", key)
+	}
+	if v != nil {
+		if e := tv.Decode(v); e != nil {
+			return tv, client.wrap(e, "RedisGetTagged:JSONUnmarshal", "", key)
+		}
+	}
+	return tv, nil
+}
+
+// Conflicts returns the latest writes of all regions when more than one region
+// wrote key within window of the newest write, nil otherwise.
+func (client *Client) Conflicts(ctx context.Context, key string, window time.Duration) ([]TaggedValue, error) {
+	fields, e := client.client.HGetAll(ctx, client.originKey(key)).Result()
+	if e != nil {
+		return nil, client.wrap(e, "RedisConflicts", "hgetall", key)
+	}
+
+	writes := make([]TaggedValue, 0, len(fields))
+	var newest time.Time
+	for _, data_str := range fields {
+		var tv TaggedValue
+		if json.Unmarshal([]byte(data_str), &tv) != nil {
+			continue
+		}
+		writes = append(writes, tv)
+		if tv.Time.After(newest) {
+			newest = tv.Time
+		}
+	}
+
+	concurrent := writes[:0]
+	for _, tv := range writes {
+		if newest.Sub(tv.Time) <= window {
+			concurrent = append(concurrent, tv)
+		}
+	}
+	if len(concurrent) < 2 {
+		return nil, nil
+	}
+	return concurrent, nil
+}
+
+// ResolveConflict writes the value picked by resolve (LastWriteWins when nil)
+// if key has concurrent writes, and reports whether it did.
+func (client *Client) ResolveConflict(ctx context.Context, key string, window time.Duration, ttl int, resolve ConflictResolver) (bool, error) {
+	writes, e := client.Conflicts(ctx, key, window)
+	if e != nil || writes == nil {
+		return false, e
+	}
+	if resolve == nil {
+		resolve = LastWriteWins
+	}
+	v, e := client.callResolver(resolve, writes)
+	if e != nil {
+		return false, client.wrap(e, "RedisResolveConflict", "", key)
+	}
+
+	data, ok := v.(json.RawMessage)
+	if !ok {
+		if data, e = json.Marshal(v); e != nil {
+			return false, client.wrap(e, "RedisResolveConflict:JSONMarshal", "", key)
+		}
+	}
+	if e := client.setTagged(ctx, "RedisResolveConflict", key, data, ttl); e != nil {
+		return false, e
+	}
+
+	// The resolved value now carries our region, forget the competing writes
+	others := make([]string, 0, len(writes))
+	for _, tv := range writes {
+		if tv.Region != client.config.Region {
+			others = append(others, tv.Region)
+		}
+	}
+	if len(others) > 0 {
+		if e := client.client.HDel(ctx, client.originKey(key), others...).Err(); e != nil {
+			return true, client.wrap(e, "RedisResolveConflict", "hdel", key)
+		}
+	}
+	return true, nil
+}
+
+func (client *Client) callResolver(resolve ConflictResolver, writes []TaggedValue) (v interface{}, e error) {
+	defer client.recoverPanic("resolver", &e)
+	return resolve(writes)
+}
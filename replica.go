@@ -0,0 +1,83 @@
+package redis
+
+import (
+	"context"
+	"sync/atomic"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+type ReadPreference int
+
+const (
+	ReadReplica ReadPreference = iota // Reads go to Config.Replicas when configured
+	ReadPrimary
+)
+
+// replicaSet holds a client per address of Config.Replicas, reads are spread
+// over them round robin.
+type replicaSet struct {
+	clients []goredis.UniversalClient
+	next    atomic.Uint32
+}
+
+func (rs *replicaSet) pick() goredis.UniversalClient {
+	return rs.clients[int(rs.next.Add(1))%len(rs.clients)]
+}
+
+func (rs *replicaSet) close() {
+	for _, c := range rs.clients {
+		c.Close()
+	}
+}
+
+// newReplicaSet connects to the replicas with the options of the primary,
+// they get the same hooks except the breaker and the write side ones. The
+// options disable the go-redis retries when Config.Retry is set, so the
+// replicas retry with the retry hook too.
+func (client *Client) newReplicaSet(opts goredis.UniversalOptions) *replicaSet {
+	rs := &replicaSet{}
+	for _, addr := range client.config.Replicas {
+		opts.Addrs = []string{addr}
+		opts.MasterName = ""
+		c := goredis.NewUniversalClient(&opts)
+		c.AddHook(&lifecycleHook{lc: client.lifecycle})
+		c.AddHook(&timeoutHook{client: client})
+		c.AddHook(&metricsHook{client: client})
+		c.AddHook(&commandHook{client: client})
+		if client.config.Retry.MaxRetries > 0 {
+			c.AddHook(&retryHook{cfg: &client.config.Retry})
+		}
+		rs.clients = append(rs.clients, c)
+	}
+	return rs
+}
+
+type consistentReadKey struct{}
+
+// WithConsistentRead sends the reads made with the returned context to the
+// primary, e.g. to read a value just written.
+func WithConsistentRead(ctx context.Context) context.Context {
+	return context.WithValue(ctx, consistentReadKey{}, true)
+}
+
+// WithReadPreference returns an option for With, ReadPrimary makes a view
+// that never reads from the replicas.
+func WithReadPreference(pref ReadPreference) Option {
+	return func(client *Client) {
+		client.readPref = pref
+	}
+}
+
+// reader returns where a read only wrapper sends its command. Replicas lag
+// behind the primary, there is no fallback to the primary when a replica
+// fails.
+func (client *Client) reader(ctx context.Context) goredis.Cmdable {
+	if client.replicas == nil || client.readPref == ReadPrimary {
+		return client.client
+	}
+	if consistent, _ := ctx.Value(consistentReadKey{}).(bool); consistent {
+		return client.client
+	}
+	return client.replicas.pick()
+}
@@ -0,0 +1,365 @@
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Results of expensive reads are stored under <prefix>:rc:<hash>, every source
+// key remembers its derived results in <root prefix>:rcdeps:<full source key>
+// so that a write to the source drops them, whichever view of the client wrote
+// it. Invalidation is done by resultCacheHook and needs
+// Config.InvalidateResultCache. It runs in the background off the write path,
+// so a result may still be served for a moment after its source was written.
+//
+// A result is registered as pending before it is fetched. Storing it swaps
+// the pending member for the result, a result whose pending member was taken
+// by an invalidation in between is dropped again as it may be stale.
+
+func (client *Client) CachedZRangeByScore(ctx context.Context, key string, opt *goredis.ZRangeBy, ttl int) ([]string, error) {
+	var res []string
+	e := client.cachedResult(ctx, "RedisCachedZRangeByScore", []string{key}, ttl, &res, func() (interface{}, error) {
+		return client.client.ZRangeByScore(ctx, client.fullKey(key), opt).Result()
+	}, "zrangebyscore", key, opt.Min, opt.Max, opt.Offset, opt.Count)
+	return res, e
+}
+
+func (client *Client) CachedSInter(ctx context.Context, ttl int, keys ...string) ([]string, error) {
+	if e := client.checkSlots(keys...); e != nil {
+		return nil, client.wrap(e, "RedisCachedSInter", "sinter", firstKey(keys))
+	}
+	key_strs := make([]string, len(keys))
+	args := make([]interface{}, 0, len(keys)+1)
+	args = append(args, "sinter")
+	for i, key := range keys {
+		key_strs[i] = client.fullKey(key)
+		args = append(args, key)
+	}
+
+	var res []string
+	e := client.cachedResult(ctx, "RedisCachedSInter", keys, ttl, &res, func() (interface{}, error) {
+		return client.client.SInter(ctx, key_strs...).Result()
+	}, args...)
+	return res, e
+}
+
+// CachedEval runs script and decodes its JSON cached reply into v, KEYS are prefixed and
+// are treated as the sources of the result. The script must be read only, its
+// own eval does not invalidate.
+func (client *Client) CachedEval(ctx context.Context, script string, keys []string, ttl int, v interface{}, args ...interface{}) error {
+	if e := client.checkSlots(keys...); e != nil {
+		return client.wrap(e, "RedisCachedEval", "eval", firstKey(keys))
+	}
+	key_strs := make([]string, len(keys))
+	for i, key := range keys {
+		key_strs[i] = client.fullKey(key)
+	}
+
+	hash_args := make([]interface{}, 0, len(keys)+len(args)+2)
+	hash_args = append(hash_args, "eval", script)
+	for _, key := range keys {
+		hash_args = append(hash_args, key)
+	}
+	hash_args = append(hash_args, args...)
+
+	return client.cachedResult(ctx, "RedisCachedEval", keys, ttl, v, func() (interface{}, error) {
+		return client.client.Eval(context.WithValue(ctx, resultCacheOwn{}, true), script, key_strs, args...).Result()
+	}, hash_args...)
+}
+
+func (client *Client) cachedResult(ctx context.Context, op string, sources []string, ttl int, v interface{}, fetch func() (interface{}, error), hash_args ...interface{}) error {
+	if len(sources) == 0 {
+		return client.wrap(errors.New("no source keys"), op, "", "")
+	}
+
+	h := sha1.New()
+	for _, arg := range hash_args {
+		fmt.Fprintf(h, "%v\x00", arg)
+	}
+	cache_key := client.config.Prefix + ":rc:" + hex.EncodeToString(h.Sum(nil))
+
+	if data_str, e := client.client.Get(ctx, cache_key).Result(); e == nil {
+		if e := json.Unmarshal([]byte(data_str), v); e == nil {
+			return nil
+		}
+	} else if e != goredis.Nil {
+		return client.wrap(e, op, "get", sources[0])
+	}
+
+	expiration := time.Duration(ttl) * time.Second
+	own_ctx := context.WithValue(ctx, resultCacheOwn{}, true)
+	pending, e := pendingResult(cache_key)
+	if e != nil {
+		return errors.Wrap(e, op)
+	}
+	if e := client.register(own_ctx, sources, pending, expiration); e != nil {
+		return client.wrap(e, op, "sadd", sources[0])
+	}
+
+	res, e := fetch()
+	if e != nil && e != goredis.Nil {
+		return client.wrap(e, op, "", sources[0])
+	}
+
+	data_str, e := json.Marshal(res)
+	if e != nil {
+		return client.wrap(e, op+":JSONMarshal", "", sources[0])
+	}
+	if e := json.Unmarshal(data_str, v); e != nil {
+		return client.wrap(e, op+":JSONUnmarshal", "", sources[0])
+	}
+
+	pipe := client.client.TxPipeline()
+	pipe.Set(own_ctx, cache_key, data_str, expiration)
+	swaps := make([]*goredis.IntCmd, len(sources))
+	for i, src := range sources {
+		deps_key := client.rcDepsKey(client.fullKey(src))
+		swaps[i] = pipe.SRem(own_ctx, deps_key, pending)
+		pipe.SAdd(own_ctx, deps_key, cache_key)
+		if expiration > 0 {
+			pipe.Expire(own_ctx, deps_key, expiration)
+		}
+	}
+	if _, e := pipe.Exec(own_ctx); e != nil {
+		client.log().Warn(op+":Store", "error", e)
+		return nil
+	}
+	for _, cmd := range swaps {
+		if cmd.Val() == 0 {
+			if e := client.client.Del(own_ctx, cache_key).Err(); e != nil {
+				client.log().Warn(op+":Store", "error", e)
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// register adds the pending member of a result about to be fetched to the
+// deps of its sources.
+func (client *Client) register(ctx context.Context, sources []string, pending string, expiration time.Duration) error {
+	pipe := client.client.Pipeline()
+	for _, src := range sources {
+		deps_key := client.rcDepsKey(client.fullKey(src))
+		pipe.SAdd(ctx, deps_key, pending)
+		if expiration > 0 {
+			pipe.Expire(ctx, deps_key, expiration)
+		}
+	}
+	_, e := pipe.Exec(ctx)
+	return e
+}
+
+// rcDepsKey is below the prefix of the root client and only depends on the
+// full key, so writes through any view of the client find the results derived
+// from it. key_str is already transformed by the KeyProvider, if any.
+func (client *Client) rcDepsKey(key_str string) string {
+	return client.rootPrefix + ":rcdeps:" + key_str
+}
+
+// pendingResult names a result being fetched in the deps, the part after the
+// separator tells concurrent fetches of the same result apart.
+const pendingSeparator = "#"
+
+func pendingResult(cache_key string) (string, error) {
+	raw := make([]byte, 8)
+	if _, e := rand.Read(raw); e != nil {
+		return "", e
+	}
+	return cache_key + pendingSeparator + hex.EncodeToString(raw), nil
+}
+
+// resultCacheOwn marks the writes of the result cache itself, which must not
+// trigger invalidations.
+type resultCacheOwn struct{}
+
+var resultCacheWrites = map[string]bool{
+	"set": true, "setex": true, "psetex": true, "setnx": true, "getset": true, "getdel": true,
+	"append": true, "setrange": true, "incr": true, "incrby": true, "incrbyfloat": true,
+	"decr": true, "decrby": true, "mset": true, "msetnx": true, "del": true, "unlink": true,
+	"rename": true, "renamenx": true, "copy": true, "restore": true,
+	"sadd": true, "srem": true, "spop": true, "smove": true,
+	"sinterstore": true, "sunionstore": true, "sdiffstore": true,
+	"zadd": true, "zrem": true, "zincrby": true, "zpopmin": true, "zpopmax": true, "bzpopmin": true, "bzpopmax": true,
+	"zremrangebyrank": true, "zremrangebyscore": true, "zremrangebylex": true,
+	"zunionstore": true, "zinterstore": true, "zdiffstore": true, "zrangestore": true,
+	"hset": true, "hsetnx": true, "hmset": true, "hpexpire": true, "hpersist": true, "hdel": true, "hincrby": true, "hincrbyfloat": true,
+	"lpush": true, "rpush": true, "lpushx": true, "rpushx": true, "linsert": true,
+	"lpop": true, "rpop": true, "blpop": true, "brpop": true, "lrem": true, "lset": true, "ltrim": true,
+	"lmove": true, "blmove": true, "rpoplpush": true, "brpoplpush": true,
+	"geoadd": true, "pfadd": true, "pfmerge": true, "setbit": true, "bitop": true,
+	"cf.add": true, "cf.addnx": true, "cf.del": true, "cms.incrby": true, "topk.add": true, "tdigest.add": true,
+	"json.set": true, "json.del": true, "json.merge": true, "ts.add": true,
+	"eval": true, "evalsha": true, "fcall": true,
+}
+
+func writtenKeys(cmd goredis.Cmder) []string {
+	args := cmd.Args()
+	if len(args) < 2 || !resultCacheWrites[cmd.Name()] {
+		return nil
+	}
+	var keys []string
+	add := func(args []interface{}) {
+		for _, arg := range args {
+			keys = append(keys, fmt.Sprint(arg))
+		}
+	}
+	switch cmd.Name() {
+	case "del", "unlink":
+		add(args[1:])
+	case "mset", "msetnx":
+		for i := 1; i < len(args); i += 2 {
+			keys = append(keys, fmt.Sprint(args[i]))
+		}
+	case "smove", "rename", "renamenx", "lmove", "blmove", "rpoplpush", "brpoplpush":
+		add(args[1:min(3, len(args))])
+	case "copy":
+		if len(args) > 2 {
+			keys = append(keys, fmt.Sprint(args[2]))
+		}
+	case "blpop", "brpop", "bzpopmin", "bzpopmax":
+		// The last argument is the timeout
+		add(args[1 : len(args)-1])
+	case "bitop":
+		if len(args) > 2 {
+			keys = append(keys, fmt.Sprint(args[2]))
+		}
+	case "eval", "evalsha", "fcall":
+		// Scripts may write any of their keys: <script> <numkeys> <keys...>
+		if len(args) > 2 {
+			if n, e := strconv.Atoi(fmt.Sprint(args[2])); e == nil && n > 0 && 3+n <= len(args) {
+				add(args[3 : 3+n])
+			}
+		}
+	default:
+		keys = append(keys, fmt.Sprint(args[1]))
+	}
+	return keys
+}
+
+type resultCacheHook struct {
+	client *Client
+	queue  chan string
+}
+
+func newResultCacheHook(client *Client) *resultCacheHook {
+	return &resultCacheHook{client: client, queue: make(chan string, 4096)}
+}
+
+func (h *resultCacheHook) DialHook(next goredis.DialHook) goredis.DialHook {
+	return next
+}
+
+func (h *resultCacheHook) ProcessHook(next goredis.ProcessHook) goredis.ProcessHook {
+	return func(ctx context.Context, cmd goredis.Cmder) error {
+		e := next(ctx, cmd)
+		if e == nil && ctx.Value(resultCacheOwn{}) == nil {
+			h.enqueue(ctx, writtenKeys(cmd))
+		}
+		return e
+	}
+}
+
+func (h *resultCacheHook) ProcessPipelineHook(next goredis.ProcessPipelineHook) goredis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []goredis.Cmder) error {
+		e := next(ctx, cmds)
+		if ctx.Value(resultCacheOwn{}) != nil {
+			return e
+		}
+		var keys []string
+		for _, cmd := range cmds {
+			if cmd.Err() == nil {
+				keys = append(keys, writtenKeys(cmd)...)
+			}
+		}
+		h.enqueue(ctx, keys)
+		return e
+	}
+}
+
+// enqueue hands the keys to run, a full queue invalidates in the caller
+// rather than dropping them.
+func (h *resultCacheHook) enqueue(ctx context.Context, keys []string) {
+	for i, key := range keys {
+		select {
+		case h.queue <- key:
+		default:
+			h.invalidate(ctx, keys[i:])
+			return
+		}
+	}
+}
+
+func (h *resultCacheHook) run(ctx context.Context) {
+	for {
+		var keys []string
+		select {
+		case <-ctx.Done():
+			return
+		case key := <-h.queue:
+			keys = append(keys, key)
+		}
+	batch:
+		for len(keys) < 256 {
+			select {
+			case key := <-h.queue:
+				keys = append(keys, key)
+			default:
+				break batch
+			}
+		}
+		h.invalidate(ctx, keys)
+	}
+}
+
+// invalidate takes the deps of the keys, reading and deleting each in one
+// transaction so a result stored concurrently either sees its pending member
+// gone or is deleted here.
+func (h *resultCacheHook) invalidate(ctx context.Context, keys []string) {
+	ctx = context.WithValue(ctx, resultCacheOwn{}, true)
+	pipe := h.client.client.TxPipeline()
+	var lookups []*goredis.StringSliceCmd
+	seen := make(map[string]bool, len(keys))
+	deps_prefix := h.client.rcDepsKey("")
+	for _, key := range keys {
+		if seen[key] || strings.HasPrefix(key, deps_prefix) {
+			continue
+		}
+		seen[key] = true
+		deps_key := h.client.rcDepsKey(key)
+		lookups = append(lookups, pipe.SMembers(ctx, deps_key))
+		pipe.Del(ctx, deps_key)
+	}
+	if len(lookups) == 0 {
+		return
+	}
+	if _, e := pipe.Exec(ctx); e != nil && e != goredis.Nil {
+		h.client.log().Warn("RedisResultCache:Invalidate", "error", e)
+	}
+
+	// One DEL per key, derived results live in other cluster slots
+	pipe = h.client.client.Pipeline()
+	for _, cmd := range lookups {
+		for _, k := range cmd.Val() {
+			if cache_key, _, pending := strings.Cut(k, pendingSeparator); !pending {
+				pipe.Del(ctx, cache_key)
+			}
+		}
+	}
+	if pipe.Len() == 0 {
+		return
+	}
+	if _, e := pipe.Exec(ctx); e != nil {
+		h.client.log().Warn("RedisResultCache:Invalidate", "error", e)
+	}
+}
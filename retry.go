@@ -0,0 +1,125 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"strings"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// RetryConfig replaces the go-redis retries when MaxRetries is set. Commands
+// are retried with exponential backoff and full jitter, but only for errors
+// that are safe to retry, see IsRetryable. Like with go-redis, a command that
+// reached the server before the connection broke may run twice.
+type RetryConfig struct {
+	MaxRetries int                  `mapstructure:"max_retries"`
+	MinBackoff time.Duration        `mapstructure:"min_backoff"` // Defaults to 8ms
+	MaxBackoff time.Duration        `mapstructure:"max_backoff"` // Defaults to 512ms
+	Retryable  func(err error) bool `mapstructure:"-"`           // Defaults to IsRetryable
+}
+
+// retriedError keeps the number of attempts of a failed command so that it
+// can be reported in CommandError.
+type retriedError struct {
+	err      error
+	attempts int
+}
+
+func (e *retriedError) Error() string { return e.err.Error() }
+func (e *retriedError) Unwrap() error { return e.err }
+
+// IsRetryable reports network failures and the transient server states of
+// failovers and resharding (LOADING, READONLY, MASTERDOWN, CLUSTERDOWN, TRYAGAIN).
+func IsRetryable(err error) bool {
+	if err == nil || err == goredis.Nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var ne net.Error
+	if errors.As(err, &ne) {
+		return true
+	}
+	s := err.Error()
+	for _, prefix := range []string{"LOADING ", "READONLY ", "MASTERDOWN ", "CLUSTERDOWN ", "TRYAGAIN "} {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (cfg *RetryConfig) backoff(attempt int) time.Duration {
+	min, max := cfg.MinBackoff, cfg.MaxBackoff
+	if min <= 0 {
+		min = 8 * time.Millisecond
+	}
+	if max <= 0 {
+		max = 512 * time.Millisecond
+	}
+	d := min << uint(attempt)
+	if d <= 0 || d > max {
+		d = max
+	}
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
+
+func (cfg *RetryConfig) retryable(err error) bool {
+	if cfg.Retryable != nil {
+		return cfg.Retryable(err)
+	}
+	return IsRetryable(err)
+}
+
+type retryHook struct {
+	cfg *RetryConfig
+}
+
+func (h *retryHook) DialHook(next goredis.DialHook) goredis.DialHook {
+	return next
+}
+
+func (h *retryHook) ProcessHook(next goredis.ProcessHook) goredis.ProcessHook {
+	return func(ctx context.Context, cmd goredis.Cmder) error {
+		return h.run(ctx, func() error { return next(ctx, cmd) }, func(e error) { cmd.SetErr(e) })
+	}
+}
+
+func (h *retryHook) ProcessPipelineHook(next goredis.ProcessPipelineHook) goredis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []goredis.Cmder) error {
+		return h.run(ctx, func() error { return next(ctx, cmds) }, func(e error) {
+			for _, cmd := range cmds {
+				if cmd.Err() != nil {
+					cmd.SetErr(e)
+				}
+			}
+		})
+	}
+}
+
+func (h *retryHook) run(ctx context.Context, do func() error, fail func(error)) error {
+	var e error
+	for attempt := 0; ; attempt++ {
+		if e = do(); e == nil || attempt >= h.cfg.MaxRetries || !h.cfg.retryable(e) {
+			if e != nil && attempt > 0 {
+				e = &retriedError{err: e, attempts: attempt + 1}
+				fail(e)
+			}
+			return e
+		}
+
+		select {
+		case <-ctx.Done():
+			e = &retriedError{err: e, attempts: attempt + 1}
+			fail(e)
+			return e
+		case <-time.After(h.cfg.backoff(attempt)):
+		}
+	}
+}
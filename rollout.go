@@ -0,0 +1,109 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"sync"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Rollout gates a behavior for a stable percentage of ids. The percentage
+// lives in Redis under <prefix>:rollout:<name> so it can be turned up or down
+// at runtime, and is re-read at most once per refresh interval.
+type Rollout struct {
+	client  *Client
+	name    string
+	refresh time.Duration
+
+	mu         sync.Mutex
+	percent    float64
+	loaded     time.Time
+	refreshing bool // A caller is reading the dial, the others keep the cached value
+}
+
+func (client *Client) Rollout(name string, refresh time.Duration) *Rollout {
+	if refresh <= 0 {
+		refresh = 10 * time.Second
+	}
+	return &Rollout{client: client, name: name, refresh: refresh}
+}
+
+func (r *Rollout) key() string {
+	return r.client.fullKey("rollout:" + r.name)
+}
+
+func (r *Rollout) SetPercent(ctx context.Context, percent float64) error {
+	if percent < 0 || percent > 100 {
+		return fmt.Errorf("redis: rollout percentage %v out of range", percent)
+	}
+	if e := r.client.client.Set(ctx, r.key(), strconv.FormatFloat(percent, 'f', -1, 64), 0).Err(); e != nil {
+		return r.client.wrap(e, "RedisRolloutSet", "set", "rollout:"+r.name)
+	}
+	r.mu.Lock()
+	r.percent, r.loaded = percent, time.Now()
+	r.mu.Unlock()
+	return nil
+}
+
+// Percent returns the current dial, a missing dial means 0. When Redis can not
+// be reached the last known value is kept. One caller at a time re-reads the
+// dial, the others get the cached value meanwhile, which is 0 before the
+// first read completed.
+func (r *Rollout) Percent(ctx context.Context) float64 {
+	r.mu.Lock()
+	if r.refreshing || time.Since(r.loaded) < r.refresh {
+		percent := r.percent
+		r.mu.Unlock()
+		return percent
+	}
+	r.refreshing = true
+	loaded := r.loaded
+	r.mu.Unlock()
+
+	percent, ok := r.read(ctx)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.refreshing = false
+	// SetPercent ran meanwhile, its value is newer than the one read
+	if !r.loaded.Equal(loaded) {
+		return r.percent
+	}
+	if ok {
+		r.percent = percent
+	}
+	r.loaded = time.Now()
+	return r.percent
+}
+
+func (r *Rollout) read(ctx context.Context) (float64, bool) {
+	data_str, e := r.client.client.Get(ctx, r.key()).Result()
+	switch {
+	case e == goredis.Nil:
+		return 0, true
+	case e != nil:
+		r.client.log().Warn("RedisRollout:Get", "rollout", r.name, "error", e)
+		return 0, false
+	}
+	p, e := strconv.ParseFloat(data_str, 64)
+	return p, e == nil
+}
+
+// Enabled is deterministic for an id, raising the percentage only adds ids.
+func (r *Rollout) Enabled(ctx context.Context, id string) bool {
+	return float64(r.bucket(id)) < r.Percent(ctx)*100
+}
+
+// Ids are spread over 10000 buckets, salted with the name so that rollouts do
+// not all pick the same ids first.
+func (r *Rollout) bucket(id string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(r.name))
+	h.Write([]byte{0})
+	h.Write([]byte(id))
+	return h.Sum32() % 10000
+}
@@ -0,0 +1,176 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+type ScalingAdvisorConfig struct {
+	Window          time.Duration // Samples older than this are ignored, defaults to five minutes
+	TargetDrainTime time.Duration // How fast an existing backlog should be worked off, defaults to one minute
+	MinConsumers    int
+	MaxConsumers    int // 0 means unlimited
+}
+
+// ScalingSample is reported by every consumer instance, Processed counts the
+// messages it finished since its previous sample.
+type ScalingSample struct {
+	Time      time.Time     `json:"t"`
+	Instance  string        `json:"i"`
+	Backlog   int64         `json:"b"`
+	Processed int64         `json:"p"`
+	Latency   time.Duration `json:"l"` // Average processing time per message
+}
+
+type ScalingAdvice struct {
+	Desired     int
+	Backlog     int64
+	GrowthRate  float64 // Backlog change in messages per second
+	ArrivalRate float64 // Messages per second entering the queue
+	Throughput  float64 // Messages per second processed by all consumers
+	Latency     time.Duration
+}
+
+// ScalingAdvisor turns consumer samples, kept in Redis so every instance sees
+// the same picture, into a desired consumer count for external autoscalers.
+type ScalingAdvisor struct {
+	client *Client
+	queue  string
+	cfg    ScalingAdvisorConfig
+}
+
+func (client *Client) ScalingAdvisor(queue string, cfg ScalingAdvisorConfig) *ScalingAdvisor {
+	if cfg.Window <= 0 {
+		cfg.Window = 5 * time.Minute
+	}
+	if cfg.TargetDrainTime <= 0 {
+		cfg.TargetDrainTime = time.Minute
+	}
+	return &ScalingAdvisor{client: client, queue: queue, cfg: cfg}
+}
+
+func (a *ScalingAdvisor) key() string {
+	return a.client.fullKey("scaling:" + a.queue)
+}
+
+func (a *ScalingAdvisor) Record(ctx context.Context, sample ScalingSample) error {
+	if sample.Time.IsZero() {
+		sample.Time = time.Now()
+	}
+	data_str, e := json.Marshal(&sample)
+	if e != nil {
+		return a.client.wrap(e, "RedisScalingRecord:JSONMarshal", "", "scaling:"+a.queue)
+	}
+
+	key_str := a.key()
+	cutoff := sample.Time.Add(-a.cfg.Window).UnixMilli()
+	pipe := a.client.client.TxPipeline()
+	pipe.ZAdd(ctx, key_str, goredis.Z{Score: float64(sample.Time.UnixMilli()), Member: string(data_str)})
+	pipe.ZRemRangeByScore(ctx, key_str, "-inf", "("+strconv.FormatInt(cutoff, 10))
+	pipe.Expire(ctx, key_str, 2*a.cfg.Window)
+	if _, e := pipe.Exec(ctx); e != nil {
+		return a.client.wrap(e, "RedisScalingRecord", "zadd", "scaling:"+a.queue)
+	}
+	return nil
+}
+
+func (a *ScalingAdvisor) Advise(ctx context.Context) (*ScalingAdvice, error) {
+	min_str := strconv.FormatInt(time.Now().Add(-a.cfg.Window).UnixMilli(), 10)
+	members, e := a.client.client.ZRangeByScore(ctx, a.key(), &goredis.ZRangeBy{Min: min_str, Max: "+inf"}).Result()
+	if e != nil {
+		return nil, a.client.wrap(e, "RedisScalingAdvise", "zrangebyscore", "scaling:"+a.queue)
+	}
+
+	samples := make([]ScalingSample, 0, len(members))
+	for _, m := range members {
+		var s ScalingSample
+		if json.Unmarshal([]byte(m), &s) == nil {
+			samples = append(samples, s)
+		}
+	}
+	return a.advise(samples), nil
+}
+
+func (a *ScalingAdvisor) advise(samples []ScalingSample) *ScalingAdvice {
+	advice := &ScalingAdvice{Desired: a.cfg.MinConsumers}
+	if len(samples) == 0 {
+		return advice
+	}
+	first, last := samples[0], samples[len(samples)-1]
+	advice.Backlog = last.Backlog
+
+	var processed int64
+	var weighted float64
+	for _, s := range samples[1:] {
+		processed += s.Processed
+		weighted += float64(s.Processed) * float64(s.Latency)
+	}
+	if processed > 0 {
+		advice.Latency = time.Duration(weighted / float64(processed))
+	} else {
+		advice.Latency = last.Latency
+	}
+
+	if span := last.Time.Sub(first.Time).Seconds(); span > 0 {
+		advice.Throughput = float64(processed) / span
+		advice.GrowthRate = float64(last.Backlog-first.Backlog) / span
+	}
+	advice.ArrivalRate = math.Max(advice.Throughput+advice.GrowthRate, 0)
+
+	// Consumers needed to keep up with arrivals plus the ones needed to drain
+	// the current backlog within TargetDrainTime
+	demand := advice.ArrivalRate + float64(advice.Backlog)/a.cfg.TargetDrainTime.Seconds()
+	desired := int(math.Ceil(demand * advice.Latency.Seconds()))
+	if desired < a.cfg.MinConsumers {
+		desired = a.cfg.MinConsumers
+	}
+	if a.cfg.MaxConsumers > 0 && desired > a.cfg.MaxConsumers {
+		desired = a.cfg.MaxConsumers
+	}
+	advice.Desired = desired
+	return advice
+}
+
+// Collector exports the advice as gauges, e.g. for a KEDA prometheus scaler.
+// The advice is computed on every scrape.
+func (a *ScalingAdvisor) Collector() prometheus.Collector {
+	labels := prometheus.Labels{"prefix": a.client.config.Prefix, "queue": a.queue}
+	return &scalingCollector{
+		advisor: a,
+		desired: prometheus.NewDesc("redis_queue_desired_consumers", "Consumers needed for the current load.", nil, labels),
+		backlog: prometheus.NewDesc("redis_queue_backlog", "Messages waiting in the queue.", nil, labels),
+		growth:  prometheus.NewDesc("redis_queue_backlog_growth_rate", "Backlog change in messages per second.", nil, labels),
+	}
+}
+
+type scalingCollector struct {
+	advisor *ScalingAdvisor
+	desired *prometheus.Desc
+	backlog *prometheus.Desc
+	growth  *prometheus.Desc
+}
+
+func (c *scalingCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desired
+	ch <- c.backlog
+	ch <- c.growth
+}
+
+func (c *scalingCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	advice, e := c.advisor.Advise(ctx)
+	if e != nil {
+		c.advisor.client.log().Warn("RedisScalingAdvisor:Collect", "queue", c.advisor.queue, "error", e)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(c.desired, prometheus.GaugeValue, float64(advice.Desired))
+	ch <- prometheus.MustNewConstMetric(c.backlog, prometheus.GaugeValue, float64(advice.Backlog))
+	ch <- prometheus.MustNewConstMetric(c.growth, prometheus.GaugeValue, advice.GrowthRate)
+}
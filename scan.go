@@ -0,0 +1,154 @@
+package redis
+
+import (
+	"context"
+	"strings"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// ScanKeys calls fn with every key under the prefix matching pattern, keys are
+// passed without the prefix. In cluster mode every master is scanned.
+func (client *Client) ScanKeys(ctx context.Context, pattern string, count int64, fn func(key string) error) error {
+	match := client.config.Prefix + ":" + pattern
+	scan := func(ctx context.Context, node goredis.Cmdable) error {
+		iter := node.Scan(ctx, 0, match, count).Iterator()
+		for iter.Next(ctx) {
+			if e := fn(strings.TrimPrefix(iter.Val(), client.config.Prefix+":")); e != nil {
+				return e
+			}
+		}
+		return iter.Err()
+	}
+
+	var e error
+	if cc, ok := client.client.(*goredis.ClusterClient); ok {
+		e = cc.ForEachMaster(ctx, func(ctx context.Context, node *goredis.Client) error {
+			return scan(ctx, node)
+		})
+	} else {
+		e = scan(ctx, client.client)
+	}
+	if e != nil {
+		return client.wrap(e, "RedisScanKeys", "scan", pattern)
+	}
+	return nil
+}
+
+type HashEntry struct {
+	Field string
+	Value string
+}
+
+// HScan calls fn with the fields of a hash matching pattern, batches of about
+// count fields are read per round trip so large hashes do not block the server
+// like HGETALL does. Fields changed during the scan may be seen twice or not at
+// all, as with SCAN.
+func (client *Client) HScan(ctx context.Context, key, pattern string, count int64, fn func(field, value string) error) error {
+	iter := client.client.HScan(ctx, client.fullKey(key), 0, pattern, count).Iterator()
+	for iter.Next(ctx) {
+		field := iter.Val()
+		if !iter.Next(ctx) {
+			break
+		}
+		if e := fn(field, iter.Val()); e != nil {
+			return e
+		}
+	}
+	if e := iter.Err(); e != nil {
+		return client.wrap(e, "RedisHScan", "hscan", key)
+	}
+	return nil
+}
+
+// SScan calls fn with the members of a set matching pattern, see HScan.
+func (client *Client) SScan(ctx context.Context, key, pattern string, count int64, fn func(member string) error) error {
+	iter := client.client.SScan(ctx, client.fullKey(key), 0, pattern, count).Iterator()
+	for iter.Next(ctx) {
+		if e := fn(iter.Val()); e != nil {
+			return e
+		}
+	}
+	if e := iter.Err(); e != nil {
+		return client.wrap(e, "RedisSScan", "sscan", key)
+	}
+	return nil
+}
+
+// HScanAll collects a hash with HSCAN, duplicates reported by the scan collapse
+// in the map.
+func (client *Client) HScanAll(ctx context.Context, key string, count int64) (map[string]string, error) {
+	result := make(map[string]string)
+	e := client.HScan(ctx, key, "", count, func(field, value string) error {
+		result[field] = value
+		return nil
+	})
+	if e != nil {
+		return nil, e
+	}
+	return result, nil
+}
+
+// SScanAll collects a set with SSCAN, without the duplicates the scan may
+// report.
+func (client *Client) SScanAll(ctx context.Context, key string, count int64) ([]string, error) {
+	seen := make(map[string]struct{})
+	var members []string
+	e := client.SScan(ctx, key, "", count, func(member string) error {
+		if _, ok := seen[member]; !ok {
+			seen[member] = struct{}{}
+			members = append(members, member)
+		}
+		return nil
+	})
+	if e != nil {
+		return nil, e
+	}
+	return members, nil
+}
+
+// HScanChan streams the fields of a hash, the entries channel is closed when
+// the scan ends or ctx is done. The error channel then yields the error of the
+// scan, if any, and is closed as well.
+func (client *Client) HScanChan(ctx context.Context, key, pattern string, count int64) (<-chan HashEntry, <-chan error) {
+	entries := make(chan HashEntry, max(count, 1))
+	errs := make(chan error, 1)
+	go func() {
+		defer close(errs)
+		defer close(entries)
+		e := client.HScan(ctx, key, pattern, count, func(field, value string) error {
+			select {
+			case entries <- HashEntry{Field: field, Value: value}:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		if e != nil {
+			errs <- e
+		}
+	}()
+	return entries, errs
+}
+
+// SScanChan streams the members of a set like HScanChan.
+func (client *Client) SScanChan(ctx context.Context, key, pattern string, count int64) (<-chan string, <-chan error) {
+	members := make(chan string, max(count, 1))
+	errs := make(chan error, 1)
+	go func() {
+		defer close(errs)
+		defer close(members)
+		e := client.SScan(ctx, key, pattern, count, func(member string) error {
+			select {
+			case members <- member:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		if e != nil {
+			errs <- e
+		}
+	}()
+	return members, errs
+}
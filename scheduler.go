@@ -0,0 +1,293 @@
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+type SchedulerConfig struct {
+	PollInterval time.Duration // Defaults to one second
+	Batch        int           // Jobs claimed per poll, defaults to 10
+	Visibility   time.Duration // A claimed job runs again when it is not completed within this, defaults to one minute
+	MaxAttempts  int           // Failed runs before a job is moved to the dead jobs, defaults to 5
+	Backoff      time.Duration // Delay after the first failure, doubling per attempt up to an hour, defaults to one second
+}
+
+type ScheduledJob struct {
+	ID      string        `json:"id"` // Generated when empty, scheduling an existing ID replaces the job
+	Handler string        `json:"handler"`
+	Payload []byte        `json:"payload,omitempty"`
+	RunAt   time.Time     `json:"run_at"`
+	Every   time.Duration `json:"every,omitempty"` // Runs again Every after each success
+	Attempt int           `json:"attempt,omitempty"`
+	Error   string        `json:"error,omitempty"` // Last failure, kept for dead jobs
+}
+
+var ErrNoJobHandler = errors.New("redis: no handler registered for job")
+
+// Scheduler runs jobs at a point in time with at-least-once semantics. Due
+// jobs are in the zset <prefix>:sched:{<name>} scored by run time, claimed
+// ones move to <prefix>:sched:{<name>}:claimed scored by their visibility
+// deadline, so jobs of a crashed instance run again. The jobs themselves are
+// kept in the hash <prefix>:sched:{<name>}:jobs.
+type Scheduler struct {
+	worker
+	client *Client
+	name   string
+	cfg    SchedulerConfig
+
+	mu       sync.RWMutex
+	handlers map[string]func(ctx context.Context, job *ScheduledJob) error
+}
+
+// NewScheduler returns a scheduler that does not run jobs yet, Start polls
+// it under ctx.
+func (client *Client) NewScheduler(ctx context.Context, name string, cfg SchedulerConfig) *Scheduler {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+	if cfg.Batch <= 0 {
+		cfg.Batch = 10
+	}
+	if cfg.Visibility <= 0 {
+		cfg.Visibility = time.Minute
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 5
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = time.Second
+	}
+	s := &Scheduler{
+		client:   client,
+		name:     name,
+		cfg:      cfg,
+		handlers: make(map[string]func(ctx context.Context, job *ScheduledJob) error),
+	}
+	s.init(client, "RedisScheduler:Poll", ctx, s.loop)
+	return s
+}
+
+// due, claimed, jobs and dead jobs share a hash tag
+func (s *Scheduler) keys() []string {
+	key := "sched:{" + s.name + "}"
+	return []string{key, key + ":claimed", key + ":jobs", key + ":dead"}
+}
+
+func (s *Scheduler) fullKeys() []string {
+	keys := s.keys()
+	for i, k := range keys {
+		keys[i] = s.client.fullKey(k)
+	}
+	return keys
+}
+
+func (s *Scheduler) Handle(handler string, fn func(ctx context.Context, job *ScheduledJob) error) {
+	s.mu.Lock()
+	s.handlers[handler] = fn
+	s.mu.Unlock()
+}
+
+// Schedule stores the job and returns its id, a zero RunAt runs it right away.
+func (s *Scheduler) Schedule(ctx context.Context, job ScheduledJob) (string, error) {
+	if job.ID == "" {
+		raw := make([]byte, 16)
+		if _, e := rand.Read(raw); e != nil {
+			return "", errors.Wrap(e, "RedisSchedule")
+		}
+		job.ID = hex.EncodeToString(raw)
+	}
+	if job.RunAt.IsZero() {
+		job.RunAt = time.Now()
+	}
+	data, e := json.Marshal(job)
+	if e != nil {
+		return "", s.client.wrap(e, "RedisSchedule:JSONMarshal", "", s.keys()[0])
+	}
+
+	keys := s.fullKeys()
+	pipe := s.client.client.TxPipeline()
+	pipe.HSet(ctx, keys[2], job.ID, data)
+	pipe.ZRem(ctx, keys[1], job.ID)
+	pipe.ZAdd(ctx, keys[0], goredis.Z{Score: float64(job.RunAt.UnixMilli()), Member: job.ID})
+	if _, e := pipe.Exec(ctx); e != nil {
+		return "", s.client.wrap(e, "RedisSchedule", "zadd", s.keys()[0])
+	}
+	return job.ID, nil
+}
+
+// Cancel removes a job, a run in progress is not interrupted but the job does
+// not run again.
+func (s *Scheduler) Cancel(ctx context.Context, id string) error {
+	keys := s.fullKeys()
+	pipe := s.client.client.TxPipeline()
+	pipe.ZRem(ctx, keys[0], id)
+	pipe.ZRem(ctx, keys[1], id)
+	pipe.HDel(ctx, keys[2], id)
+	if _, e := pipe.Exec(ctx); e != nil {
+		return s.client.wrap(e, "RedisSchedulerCancel", "zrem", s.keys()[0])
+	}
+	return nil
+}
+
+// DeadJobs returns the jobs that failed MaxAttempts times.
+func (s *Scheduler) DeadJobs(ctx context.Context) ([]ScheduledJob, error) {
+	values, e := s.client.client.HVals(ctx, s.fullKeys()[3]).Result()
+	if e != nil {
+		return nil, s.client.wrap(e, "RedisSchedulerDeadJobs", "hvals", s.keys()[3])
+	}
+	jobs := make([]ScheduledJob, 0, len(values))
+	for _, v := range values {
+		var job ScheduledJob
+		if json.Unmarshal([]byte(v), &job) == nil {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+// Claims move stale claims back to due first, then move up to ARGV[3] due jobs
+// to the claimed set and return them as id, job pairs.
+var schedulerClaimScript = NewScript(`
+local stale = redis.call("ZRANGEBYSCORE", KEYS[2], "-inf", ARGV[1], "LIMIT", 0, ARGV[3])
+for _, id in ipairs(stale) do
+	redis.call("ZREM", KEYS[2], id)
+	redis.call("ZADD", KEYS[1], ARGV[1], id)
+end
+local out = {}
+local ids = redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[1], "LIMIT", 0, ARGV[3])
+for _, id in ipairs(ids) do
+	redis.call("ZREM", KEYS[1], id)
+	local job = redis.call("HGET", KEYS[3], id)
+	if job then
+		redis.call("ZADD", KEYS[2], ARGV[2], id)
+		table.insert(out, id)
+		table.insert(out, job)
+	end
+end
+return out
+`)
+
+// Poll claims and runs the due jobs and returns how many ran.
+func (s *Scheduler) Poll(ctx context.Context) (int, error) {
+	now := time.Now()
+	deadline := now.Add(s.cfg.Visibility).UnixMilli()
+	res, e := schedulerClaimScript.Run(ctx, s.client, s.keys()[:3],
+		now.UnixMilli(), deadline, s.cfg.Batch).StringSlice()
+	if e != nil {
+		return 0, s.client.wrap(e, "RedisSchedulerPoll", "evalsha", s.keys()[0])
+	}
+
+	n := 0
+	for i := 0; i+1 < len(res); i += 2 {
+		var job ScheduledJob
+		if e := json.Unmarshal([]byte(res[i+1]), &job); e != nil {
+			s.client.log().Warn("RedisSchedulerPoll:JSONUnmarshal", "job", res[i], "error", e)
+			continue
+		}
+		if e := s.complete(ctx, &job, res[i+1], deadline, s.run(ctx, &job)); e != nil {
+			return n, e
+		}
+		n++
+	}
+	return n, nil
+}
+
+func (s *Scheduler) run(ctx context.Context, job *ScheduledJob) (e error) {
+	s.mu.RLock()
+	fn := s.handlers[job.Handler]
+	s.mu.RUnlock()
+	if fn == nil {
+		return ErrNoJobHandler
+	}
+	defer s.client.recoverPanic("scheduler", &e)
+	return fn(ctx, job)
+}
+
+// Completion only applies while the job is still claimed with the claim of
+// this run, ARGV[2] the visibility deadline and ARGV[3] the job as claimed. A
+// job cancelled, scheduled again or claimed by another instance during the run
+// is left alone. ARGV[4] is "done", "dead" or "retry" with the job ARGV[5] due
+// at ARGV[6].
+var schedulerCompleteScript = NewScript(`
+local score = redis.call("ZSCORE", KEYS[2], ARGV[1])
+if not score or tonumber(score) ~= tonumber(ARGV[2]) or redis.call("HGET", KEYS[3], ARGV[1]) ~= ARGV[3] then
+	return 0
+end
+redis.call("ZREM", KEYS[2], ARGV[1])
+if ARGV[4] == "done" then
+	redis.call("HDEL", KEYS[3], ARGV[1])
+elseif ARGV[4] == "dead" then
+	redis.call("HDEL", KEYS[3], ARGV[1])
+	redis.call("HSET", KEYS[4], ARGV[1], ARGV[5])
+else
+	redis.call("HSET", KEYS[3], ARGV[1], ARGV[5])
+	redis.call("ZADD", KEYS[1], ARGV[6], ARGV[1])
+end
+return 1
+`)
+
+// complete removes or reschedules a job after a run, claimed is the job data
+// and deadline the visibility deadline it was claimed with.
+func (s *Scheduler) complete(ctx context.Context, job *ScheduledJob, claimed string, deadline int64, run_err error) error {
+	mode := "retry"
+	next := time.Time{}
+	switch {
+	case run_err == nil && job.Every > 0:
+		job.Attempt, job.Error = 0, ""
+		next = job.RunAt.Add(job.Every)
+		if now := time.Now(); next.Before(now) {
+			next = now.Add(job.Every)
+		}
+		job.RunAt = next
+	case run_err == nil:
+		mode = "done"
+	case job.Attempt+1 >= s.cfg.MaxAttempts:
+		mode = "dead"
+		job.Attempt++
+		job.Error = run_err.Error()
+	default:
+		job.Attempt++
+		job.Error = run_err.Error()
+		next = time.Now().Add(min(s.cfg.Backoff<<(job.Attempt-1), time.Hour))
+	}
+	data, _ := json.Marshal(job)
+
+	n, e := schedulerCompleteScript.Run(ctx, s.client, s.keys(), job.ID, deadline, claimed, mode, data, next.UnixMilli()).Int64()
+	if e != nil {
+		return s.client.wrap(e, "RedisSchedulerComplete", "evalsha", s.keys()[0])
+	}
+	if n == 1 && mode == "dead" {
+		s.client.log().Warn("RedisScheduler:Dead", "job", job.ID, "handler", job.Handler, "error", run_err)
+	}
+	return nil
+}
+
+func (s *Scheduler) loop(ctx context.Context, report func(error)) error {
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			// Keep polling while full batches come back
+			for {
+				n, e := s.Poll(ctx)
+				if e != nil && ctx.Err() == nil {
+					report(e)
+				}
+				if e != nil || n < s.cfg.Batch {
+					break
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/acsl-go/redis"
+	"github.com/acsl-go/redis/redistest"
+)
+
+// pollUntil polls s until cond holds or a second passed.
+func pollUntil(t *testing.T, s *redis.Scheduler, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not reached")
+		}
+		if _, e := s.Poll(context.Background()); e != nil {
+			t.Fatal(e)
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+}
+
+func TestSchedulerRetriesWithBackoff(t *testing.T) {
+	client, _ := redistest.NewTestClient(t, nil)
+	ctx := context.Background()
+	s := client.NewScheduler(ctx, "jobs", redis.SchedulerConfig{MaxAttempts: 3, Backoff: time.Millisecond})
+
+	var attempts []int
+	s.Handle("flaky", func(ctx context.Context, job *redis.ScheduledJob) error {
+		attempts = append(attempts, job.Attempt)
+		if len(attempts) < 3 {
+			return errors.New("not yet")
+		}
+		return nil
+	})
+	if _, e := s.Schedule(ctx, redis.ScheduledJob{ID: "a", Handler: "flaky"}); e != nil {
+		t.Fatal(e)
+	}
+	pollUntil(t, s, func() bool { return len(attempts) == 3 })
+
+	for i, a := range attempts {
+		if a != i {
+			t.Fatalf("attempts %v, want 0, 1, 2", attempts)
+		}
+	}
+	if n, _ := s.Poll(ctx); n != 0 {
+		t.Errorf("job ran again after success")
+	}
+	if dead, _ := s.DeadJobs(ctx); len(dead) != 0 {
+		t.Errorf("dead jobs %v, want none", dead)
+	}
+}
+
+func TestSchedulerDeadJobs(t *testing.T) {
+	client, _ := redistest.NewTestClient(t, nil)
+	ctx := context.Background()
+	s := client.NewScheduler(ctx, "jobs", redis.SchedulerConfig{MaxAttempts: 2, Backoff: time.Millisecond})
+	s.Handle("broken", func(context.Context, *redis.ScheduledJob) error { return errors.New("boom") })
+	if _, e := s.Schedule(ctx, redis.ScheduledJob{ID: "b", Handler: "broken"}); e != nil {
+		t.Fatal(e)
+	}
+
+	var dead []redis.ScheduledJob
+	pollUntil(t, s, func() bool {
+		dead, _ = s.DeadJobs(ctx)
+		return len(dead) == 1
+	})
+	if dead[0].ID != "b" || dead[0].Attempt != 2 || dead[0].Error != "boom" {
+		t.Errorf("dead job %+v", dead[0])
+	}
+}
+
+func TestSchedulerCancelDuringRun(t *testing.T) {
+	client, _ := redistest.NewTestClient(t, nil)
+	ctx := context.Background()
+	s := client.NewScheduler(ctx, "jobs", redis.SchedulerConfig{})
+	runs := 0
+	s.Handle("tick", func(ctx context.Context, job *redis.ScheduledJob) error {
+		runs++
+		return s.Cancel(ctx, job.ID)
+	})
+	if _, e := s.Schedule(ctx, redis.ScheduledJob{ID: "c", Handler: "tick", Every: time.Millisecond}); e != nil {
+		t.Fatal(e)
+	}
+	if _, e := s.Poll(ctx); e != nil {
+		t.Fatal(e)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if _, e := s.Poll(ctx); e != nil {
+		t.Fatal(e)
+	}
+	if runs != 1 {
+		t.Errorf("recurring job cancelled during its run ran %d times", runs)
+	}
+}
+
+func TestSchedulerRescheduleDuringRun(t *testing.T) {
+	client, _ := redistest.NewTestClient(t, nil)
+	ctx := context.Background()
+	s := client.NewScheduler(ctx, "jobs", redis.SchedulerConfig{})
+	var payloads []string
+	s.Handle("once", func(ctx context.Context, job *redis.ScheduledJob) error {
+		payloads = append(payloads, string(job.Payload))
+		if len(payloads) == 1 {
+			_, e := s.Schedule(ctx, redis.ScheduledJob{ID: job.ID, Handler: "once", Payload: []byte("second")})
+			return e
+		}
+		return nil
+	})
+	if _, e := s.Schedule(ctx, redis.ScheduledJob{ID: "d", Handler: "once", Payload: []byte("first")}); e != nil {
+		t.Fatal(e)
+	}
+	pollUntil(t, s, func() bool { return len(payloads) == 2 })
+	if payloads[1] != "second" {
+		t.Errorf("runs %v, want first then second", payloads)
+	}
+}
@@ -0,0 +1,76 @@
+package redis
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"strings"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Script is a Lua script run by its SHA. The script is loaded on demand when
+// the server answers NOSCRIPT, after a restart or on a new cluster node, so
+// it can be declared once at package level.
+type Script struct {
+	src string
+	sha string
+}
+
+func NewScript(src string) *Script {
+	sum := sha1.Sum([]byte(src))
+	return &Script{src: src, sha: hex.EncodeToString(sum[:])}
+}
+
+func (s *Script) Hash() string {
+	return s.sha
+}
+
+// Load loads the script on the server, on every master in cluster mode.
+func (s *Script) Load(ctx context.Context, client *Client) error {
+	if e := client.client.ScriptLoad(ctx, s.src).Err(); e != nil {
+		return client.wrap(e, "RedisScriptLoad", "script", "")
+	}
+	return nil
+}
+
+// Run runs the script with the client prefix applied to keys. Errors of the
+// returned command are not wrapped so that script replies can be inspected.
+func (s *Script) Run(ctx context.Context, client *Client, keys []string, args ...interface{}) *goredis.Cmd {
+	return s.run(ctx, client, false, keys, args)
+}
+
+// RunRO runs the script with EVALSHA_RO so it may be served by a replica, the
+// script must not write.
+func (s *Script) RunRO(ctx context.Context, client *Client, keys []string, args ...interface{}) *goredis.Cmd {
+	return s.run(ctx, client, true, keys, args)
+}
+
+func (s *Script) run(ctx context.Context, client *Client, ro bool, keys []string, args []interface{}) *goredis.Cmd {
+	key_strs := make([]string, len(keys))
+	for i, key := range keys {
+		key_strs[i] = client.fullKey(key)
+	}
+	evalsha := client.client.EvalSha
+	if ro {
+		evalsha = client.client.EvalShaRO
+	}
+	return s.eval(ctx, evalsha, client.client, key_strs, args)
+}
+
+// runFull runs the script on c with keys that already carry the prefix.
+func (s *Script) runFull(ctx context.Context, c goredis.Scripter, key_strs []string, args ...interface{}) *goredis.Cmd {
+	return s.eval(ctx, c.EvalSha, c, key_strs, args)
+}
+
+func (s *Script) eval(ctx context.Context, evalsha func(ctx context.Context, sha string, keys []string, args ...interface{}) *goredis.Cmd, c goredis.Scripter, key_strs []string, args []interface{}) *goredis.Cmd {
+	cmd := evalsha(ctx, s.sha, key_strs, args...)
+	if e := cmd.Err(); e == nil || !strings.HasPrefix(e.Error(), "NOSCRIPT") {
+		return cmd
+	}
+	if e := c.ScriptLoad(ctx, s.src).Err(); e != nil {
+		cmd.SetErr(e)
+		return cmd
+	}
+	return evalsha(ctx, s.sha, key_strs, args...)
+}
@@ -0,0 +1,315 @@
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type SessionConfig struct {
+	IdleTimeout time.Duration // Sliding expiry refreshed by Load and Save, defaults to 30 minutes
+	MaxAge      time.Duration // Absolute lifetime since Create, 0 for none
+}
+
+// SessionStore keeps sessions as hashes under <prefix>:sess:<name>:<id>.
+// Fields starting with an underscore are reserved for the store.
+type SessionStore struct {
+	client *Client
+	name   string
+	cfg    SessionConfig
+}
+
+// Session is a loaded session, changes are written by Save. A Session is not
+// safe for concurrent use.
+type Session struct {
+	ID        string
+	CSRFToken string
+	Created   time.Time
+
+	store   *SessionStore
+	values  map[string]string
+	changed map[string]bool // true for set, false for deleted fields
+}
+
+const (
+	sessionCreated = "_created"
+	sessionCSRF    = "_csrf"
+)
+
+func (client *Client) Sessions(name string, cfg SessionConfig) *SessionStore {
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = 30 * time.Minute
+	}
+	return &SessionStore{client: client, name: name, cfg: cfg}
+}
+
+func (st *SessionStore) keyName(id string) string {
+	return "sess:" + st.name + ":" + id
+}
+
+// sessionToken returns 256 random bits, URL safe so it can go into cookies
+// and forms as is.
+func sessionToken() (string, error) {
+	b := make([]byte, 32)
+	if _, e := rand.Read(b); e != nil {
+		return "", e
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Create stores a new empty session with fresh id and CSRF token.
+func (st *SessionStore) Create(ctx context.Context) (*Session, error) {
+	s, e := st.newSession(time.Now())
+	if e != nil {
+		return nil, st.client.wrap(e, "RedisSessionCreate", "", "")
+	}
+	if e := s.write(ctx, true); e != nil {
+		return nil, st.client.wrap(e, "RedisSessionCreate", "hset", st.keyName(s.ID))
+	}
+	return s, nil
+}
+
+func (st *SessionStore) newSession(created time.Time) (*Session, error) {
+	id, e := sessionToken()
+	if e != nil {
+		return nil, e
+	}
+	csrf, e := sessionToken()
+	if e != nil {
+		return nil, e
+	}
+	return &Session{
+		ID:        id,
+		CSRFToken: csrf,
+		Created:   created,
+		store:     st,
+		values:    make(map[string]string),
+		changed:   make(map[string]bool),
+	}, nil
+}
+
+// sessionLoadScript reads a session and slides its expiry like
+// Session.expiry, ARGV are the idle timeout, MaxAge or 0 and the current time
+// in milliseconds.
+var sessionLoadScript = NewScript(`
+local created = redis.call('HGET', KEYS[1], '_created')
+if not created then
+	return {}
+end
+local ttl = tonumber(ARGV[1])
+local max_age = tonumber(ARGV[2])
+created = tonumber(created)
+if max_age > 0 and created then
+	ttl = math.min(ttl, math.max(created + max_age - tonumber(ARGV[3]), 1))
+end
+redis.call('PEXPIRE', KEYS[1], ttl)
+return redis.call('HGETALL', KEYS[1])
+`)
+
+// Load reads a session and slides its expiry, ErrNotFound when it does not
+// exist or is older than MaxAge.
+func (st *SessionStore) Load(ctx context.Context, id string) (*Session, error) {
+	if id == "" {
+		return nil, ErrNotFound
+	}
+	fields, e := sessionLoadScript.Run(ctx, st.client, []string{st.keyName(id)},
+		st.cfg.IdleTimeout.Milliseconds(), st.cfg.MaxAge.Milliseconds(), time.Now().UnixMilli()).StringSlice()
+	if e != nil {
+		return nil, st.client.wrap(e, "RedisSessionLoad", "hgetall", st.keyName(id))
+	}
+
+	values := make(map[string]string, len(fields)/2)
+	for i := 0; i+1 < len(fields); i += 2 {
+		values[fields[i]] = fields[i+1]
+	}
+	ms, e := strconv.ParseInt(values[sessionCreated], 10, 64)
+	if len(values) == 0 || e != nil {
+		return nil, ErrNotFound
+	}
+	s := &Session{
+		ID:        id,
+		CSRFToken: values[sessionCSRF],
+		Created:   time.UnixMilli(ms),
+		store:     st,
+		values:    make(map[string]string, len(values)),
+		changed:   make(map[string]bool),
+	}
+	if st.cfg.MaxAge > 0 && time.Since(s.Created) > st.cfg.MaxAge {
+		_ = s.Destroy(ctx)
+		return nil, ErrNotFound
+	}
+	for k, v := range values {
+		if !strings.HasPrefix(k, "_") {
+			s.values[k] = v
+		}
+	}
+	return s, nil
+}
+
+func (s *Session) write(ctx context.Context, full bool) error {
+	st := s.store
+	key_str := st.client.fullKey(st.keyName(s.ID))
+	pipe := st.client.client.TxPipeline()
+	if full {
+		fields := map[string]interface{}{
+			sessionCreated: s.Created.UnixMilli(),
+			sessionCSRF:    s.CSRFToken,
+		}
+		for k, v := range s.values {
+			fields[k] = v
+		}
+		pipe.HSet(ctx, key_str, fields)
+	} else {
+		var set []interface{}
+		var del []string
+		for k, isSet := range s.changed {
+			if isSet {
+				set = append(set, k, s.values[k])
+			} else {
+				del = append(del, k)
+			}
+		}
+		if len(set) > 0 {
+			pipe.HSet(ctx, key_str, set...)
+		}
+		if len(del) > 0 {
+			pipe.HDel(ctx, key_str, del...)
+		}
+	}
+	pipe.PExpire(ctx, key_str, s.expiry())
+	if _, e := pipe.Exec(ctx); e != nil {
+		return e
+	}
+	s.changed = make(map[string]bool)
+	return nil
+}
+
+// expiry is the idle timeout, capped by what is left of MaxAge.
+func (s *Session) expiry() time.Duration {
+	d := s.store.cfg.IdleTimeout
+	if max_age := s.store.cfg.MaxAge; max_age > 0 {
+		d = min(d, max(time.Until(s.Created.Add(max_age)), time.Millisecond))
+	}
+	return d
+}
+
+// Save writes the changed fields only and slides the expiry.
+func (s *Session) Save(ctx context.Context) error {
+	if e := s.write(ctx, false); e != nil {
+		return s.store.client.wrap(e, "RedisSessionSave", "hset", s.store.keyName(s.ID))
+	}
+	return nil
+}
+
+// Destroy deletes the session, e.g. on logout.
+func (s *Session) Destroy(ctx context.Context) error {
+	if e := s.store.client.client.Del(ctx, s.store.client.fullKey(s.store.keyName(s.ID))).Err(); e != nil {
+		return s.store.client.wrap(e, "RedisSessionDestroy", "del", s.store.keyName(s.ID))
+	}
+	return nil
+}
+
+// Regenerate moves the session to a new id and CSRF token, call it when the
+// privilege level changes such as on login to prevent session fixation.
+func (s *Session) Regenerate(ctx context.Context) error {
+	old := *s
+	fresh, e := s.store.newSession(s.Created)
+	if e != nil {
+		return s.store.client.wrap(e, "RedisSessionRegenerate", "", "")
+	}
+	s.ID, s.CSRFToken = fresh.ID, fresh.CSRFToken
+	if e := s.write(ctx, true); e != nil {
+		s.ID, s.CSRFToken = old.ID, old.CSRFToken
+		return s.store.client.wrap(e, "RedisSessionRegenerate", "hset", s.store.keyName(fresh.ID))
+	}
+	return old.Destroy(ctx)
+}
+
+// VerifyCSRF compares token with the session's CSRF token in constant time.
+func (s *Session) VerifyCSRF(token string) bool {
+	return token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.CSRFToken)) == 1
+}
+
+func (s *Session) Fields() []string {
+	fields := make([]string, 0, len(s.values))
+	for k := range s.values {
+		fields = append(fields, k)
+	}
+	return fields
+}
+
+func (s *Session) Get(field string) (string, bool) {
+	v, ok := s.values[field]
+	return v, ok
+}
+
+func (s *Session) Int64(field string) (int64, bool) {
+	v, e := strconv.ParseInt(s.values[field], 10, 64)
+	return v, e == nil
+}
+
+func (s *Session) Bool(field string) bool {
+	v, _ := strconv.ParseBool(s.values[field])
+	return v
+}
+
+// JSON decodes a field stored with SetJSON into v, ErrNotFound when it is not
+// set.
+func (s *Session) JSON(field string, v interface{}) error {
+	data_str, ok := s.values[field]
+	if !ok {
+		return ErrNotFound
+	}
+	return s.store.client.codec.Unmarshal([]byte(data_str), v)
+}
+
+// Set stores v in its string form, strings and numbers as is. Fields
+// starting with an underscore are reserved and ignored.
+func (s *Session) Set(field string, v interface{}) {
+	if strings.HasPrefix(field, "_") {
+		return
+	}
+	s.values[field] = formatSessionValue(v)
+	s.changed[field] = true
+}
+
+func (s *Session) SetJSON(field string, v interface{}) error {
+	data_str, e := s.store.client.codec.Marshal(v)
+	if e != nil {
+		return e
+	}
+	s.Set(field, string(data_str))
+	return nil
+}
+
+func (s *Session) Delete(field string) {
+	if _, ok := s.values[field]; ok {
+		delete(s.values, field)
+		s.changed[field] = false
+	}
+}
+
+func formatSessionValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(v)
+}
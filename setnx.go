@@ -0,0 +1,121 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+type SetNXOutcome int
+
+const (
+	SetNXSet SetNXOutcome = iota + 1
+	SetNXAlreadyExists
+)
+
+func (o SetNXOutcome) String() string {
+	switch o {
+	case SetNXSet:
+		return "set"
+	case SetNXAlreadyExists:
+		return "already_exists"
+	}
+	return "unknown"
+}
+
+type SetNXResult struct {
+	Outcome  SetNXOutcome
+	Value    string // The encoded value written by this call, only when Outcome is SetNXSet
+	Existing string // The competing value, only filled by the *Get variants when Outcome is SetNXAlreadyExists
+}
+
+func (r *SetNXResult) IsSet() bool {
+	return r.Outcome == SetNXSet
+}
+
+// TrySet JSON encodes v and stores it only when key does not exist yet
+func (client *Client) TrySet(ctx context.Context, key string, v interface{}, ttl int) (*SetNXResult, error) {
+	return client.TrySetFor(ctx, key, v, seconds(ttl))
+}
+
+func (client *Client) TrySetFor(ctx context.Context, key string, v interface{}, ttl time.Duration) (*SetNXResult, error) {
+	data_str, e := client.codec.Marshal(v)
+	if e != nil {
+		return nil, client.wrap(e, "RedisTrySet:JSONMarshal", "", key)
+	}
+	return client.trySet(ctx, "RedisTrySet", key, string(data_str), ttl, false, client.jsonDocs(ctx))
+}
+
+// TrySetGet behaves like TrySet but also returns the competing value, it relies on SET NX GET which requires Redis 7
+func (client *Client) TrySetGet(ctx context.Context, key string, v interface{}, ttl int) (*SetNXResult, error) {
+	return client.TrySetGetFor(ctx, key, v, seconds(ttl))
+}
+
+func (client *Client) TrySetGetFor(ctx context.Context, key string, v interface{}, ttl time.Duration) (*SetNXResult, error) {
+	data_str, e := client.codec.Marshal(v)
+	if e != nil {
+		return nil, client.wrap(e, "RedisTrySetGet:JSONMarshal", "", key)
+	}
+	return client.trySet(ctx, "RedisTrySetGet", key, string(data_str), ttl, true, client.jsonDocs(ctx))
+}
+
+func (client *Client) TrySetStr(ctx context.Context, key string, v string, ttl int) (*SetNXResult, error) {
+	return client.trySet(ctx, "RedisTrySetStr", key, v, seconds(ttl), false, false)
+}
+
+func (client *Client) TrySetStrFor(ctx context.Context, key string, v string, ttl time.Duration) (*SetNXResult, error) {
+	return client.trySet(ctx, "RedisTrySetStr", key, v, ttl, false, false)
+}
+
+func (client *Client) TrySetStrGet(ctx context.Context, key string, v string, ttl int) (*SetNXResult, error) {
+	return client.trySet(ctx, "RedisTrySetStrGet", key, v, seconds(ttl), true, false)
+}
+
+func (client *Client) TrySetStrGetFor(ctx context.Context, key string, v string, ttl time.Duration) (*SetNXResult, error) {
+	return client.trySet(ctx, "RedisTrySetStrGet", key, v, ttl, true, false)
+}
+
+// trySet stores a document instead of a string with doc, the Str variants
+// always store strings.
+func (client *Client) trySet(ctx context.Context, op string, key string, data_str string, ttl time.Duration, get, doc bool) (*SetNXResult, error) {
+	key_str := client.fullKey(key)
+	if doc {
+		r, e := client.setDoc(ctx, key_str, data_str, "NX", client.expirationFor(ttl), get)
+		if e != nil {
+			return nil, client.wrap(e, op, "json.set", key)
+		}
+		if !r.set {
+			return &SetNXResult{Outcome: SetNXAlreadyExists, Existing: r.prev}, nil
+		}
+		client.invalidateLocal(ctx, key_str)
+		return &SetNXResult{Outcome: SetNXSet, Value: data_str}, nil
+	}
+	if !get {
+		ok, e := client.client.SetNX(ctx, key_str, data_str, client.expirationFor(ttl)).Result()
+		if e != nil {
+			return nil, client.wrap(e, op, "set", key)
+		}
+		if !ok {
+			return &SetNXResult{Outcome: SetNXAlreadyExists}, nil
+		}
+		client.invalidateLocal(ctx, key_str)
+		return &SetNXResult{Outcome: SetNXSet, Value: data_str}, nil
+	}
+
+	expiration := client.expirationFor(ttl)
+	existing, e := client.client.SetArgs(ctx, key_str, data_str, goredis.SetArgs{
+		Mode:    "NX",
+		TTL:     expiration,
+		KeepTTL: expiration == goredis.KeepTTL,
+		Get:     true,
+	}).Result()
+	if e == goredis.Nil {
+		client.invalidateLocal(ctx, key_str)
+		return &SetNXResult{Outcome: SetNXSet, Value: data_str}, nil
+	}
+	if e != nil {
+		return nil, client.wrap(e, op, "set", key)
+	}
+	return &SetNXResult{Outcome: SetNXAlreadyExists, Existing: existing}, nil
+}
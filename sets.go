@@ -0,0 +1,203 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// SPop removes and returns a random member, ErrNotFound when the set is empty.
+func (client *Client) SPop(ctx context.Context, key string) (string, error) {
+	member, e := client.client.SPop(ctx, client.fullKey(key)).Result()
+	if e != nil {
+		if e == goredis.Nil {
+			return "", ErrNotFound
+		}
+		return "", client.wrap(e, "RedisSPop", "spop", key)
+	}
+	return member, nil
+}
+
+// SPopN removes and returns up to count random members.
+func (client *Client) SPopN(ctx context.Context, key string, count int64) ([]string, error) {
+	members, e := client.client.SPopN(ctx, client.fullKey(key), count).Result()
+	if e != nil && e != goredis.Nil {
+		return nil, client.wrap(e, "RedisSPopN", "spop", key)
+	}
+	return members, nil
+}
+
+// SRandMember returns a random member without removing it, ErrNotFound when
+// the set is empty.
+func (client *Client) SRandMember(ctx context.Context, key string) (string, error) {
+	member, e := client.reader(ctx).SRandMember(ctx, client.fullKey(key)).Result()
+	if e != nil {
+		if e == goredis.Nil {
+			return "", ErrNotFound
+		}
+		return "", client.wrap(e, "RedisSRandMember", "srandmember", key)
+	}
+	return member, nil
+}
+
+// SRandMembers returns up to count distinct random members, a negative count
+// returns exactly -count members which may repeat.
+func (client *Client) SRandMembers(ctx context.Context, key string, count int64) ([]string, error) {
+	members, e := client.reader(ctx).SRandMemberN(ctx, client.fullKey(key), count).Result()
+	if e != nil {
+		return nil, client.wrap(e, "RedisSRandMembers", "srandmember", key)
+	}
+	return members, nil
+}
+
+// SMove moves member from src to dest, false when it is not in src.
+func (client *Client) SMove(ctx context.Context, src, dest string, member interface{}) (bool, error) {
+	if e := client.checkSlots(src, dest); e != nil {
+		return false, client.wrap(e, "RedisSMove", "smove", src)
+	}
+	ok, e := client.client.SMove(ctx, client.fullKey(src), client.fullKey(dest), member).Result()
+	if e != nil {
+		return false, client.wrap(e, "RedisSMove", "smove", src)
+	}
+	return ok, nil
+}
+
+// SDiff returns the members of the first set that are in none of the others.
+func (client *Client) SDiff(ctx context.Context, keys ...string) ([]string, error) {
+	return client.setOp(ctx, "RedisSDiff", "sdiff", client.reader(ctx).SDiff, keys)
+}
+
+func (client *Client) SInter(ctx context.Context, keys ...string) ([]string, error) {
+	return client.setOp(ctx, "RedisSInter", "sinter", client.reader(ctx).SInter, keys)
+}
+
+func (client *Client) SUnion(ctx context.Context, keys ...string) ([]string, error) {
+	return client.setOp(ctx, "RedisSUnion", "sunion", client.reader(ctx).SUnion, keys)
+}
+
+func (client *Client) setOp(ctx context.Context, op, cmd string, fn func(ctx context.Context, keys ...string) *goredis.StringSliceCmd, keys []string) ([]string, error) {
+	if e := client.checkSlots(keys...); e != nil {
+		return nil, client.wrap(e, op, cmd, firstKey(keys))
+	}
+	members, e := fn(ctx, client.prefixKeys(keys)...).Result()
+	if e != nil {
+		return nil, client.wrap(e, op, cmd, firstKey(keys))
+	}
+	return members, nil
+}
+
+// SDiffStore stores the result of SDiff in dest and returns its size. dest
+// expires after ttl seconds, with 0 the default TTL, if any.
+func (client *Client) SDiffStore(ctx context.Context, dest string, ttl int, keys ...string) (int64, error) {
+	return client.setOpStore(ctx, "RedisSDiffStore", "sdiffstore", dest, ttl, keys, func(pipe goredis.Pipeliner, dest_str string, key_strs []string) *goredis.IntCmd {
+		return pipe.SDiffStore(ctx, dest_str, key_strs...)
+	})
+}
+
+// SInterStore stores the intersection of the sets in dest, e.g. to compute a
+// segment once and page through it, see SDiffStore for ttl.
+func (client *Client) SInterStore(ctx context.Context, dest string, ttl int, keys ...string) (int64, error) {
+	return client.setOpStore(ctx, "RedisSInterStore", "sinterstore", dest, ttl, keys, func(pipe goredis.Pipeliner, dest_str string, key_strs []string) *goredis.IntCmd {
+		return pipe.SInterStore(ctx, dest_str, key_strs...)
+	})
+}
+
+func (client *Client) SUnionStore(ctx context.Context, dest string, ttl int, keys ...string) (int64, error) {
+	return client.setOpStore(ctx, "RedisSUnionStore", "sunionstore", dest, ttl, keys, func(pipe goredis.Pipeliner, dest_str string, key_strs []string) *goredis.IntCmd {
+		return pipe.SUnionStore(ctx, dest_str, key_strs...)
+	})
+}
+
+func (client *Client) setOpStore(ctx context.Context, op, cmd, dest string, ttl int, keys []string, fn func(pipe goredis.Pipeliner, dest_str string, key_strs []string) *goredis.IntCmd) (int64, error) {
+	if e := client.checkSlots(append([]string{dest}, keys...)...); e != nil {
+		return 0, client.wrap(e, op, cmd, dest)
+	}
+	dest_str := client.fullKey(dest)
+	pipe := client.client.TxPipeline()
+	n := fn(pipe, dest_str, client.prefixKeys(keys))
+	if expiration := client.expiration(ttl); expiration > 0 {
+		pipe.Expire(ctx, dest_str, expiration)
+	}
+	if _, e := pipe.Exec(ctx); e != nil {
+		return 0, client.wrap(e, op, cmd, dest)
+	}
+	return n.Val(), nil
+}
+
+// The JSON variants encode members with the client codec, like Set does for
+// values, so structured members round trip instead of being stringified by
+// go-redis. Members are compared by their encoding, which must therefore be
+// deterministic; encoding/json is for structs and maps.
+
+func (client *Client) encodeMembers(op, key string, members []interface{}) ([]interface{}, error) {
+	encoded := make([]interface{}, len(members))
+	for i, m := range members {
+		data_str, e := client.codec.Marshal(m)
+		if e != nil {
+			return nil, client.wrap(e, op+":JSONMarshal", "", key)
+		}
+		encoded[i] = data_str
+	}
+	return encoded, nil
+}
+
+func (client *Client) SAddJSON(ctx context.Context, key string, members ...interface{}) error {
+	encoded, e := client.encodeMembers("RedisSAddJSON", key, members)
+	if e != nil {
+		return e
+	}
+	if e := client.client.SAdd(ctx, client.fullKey(key), encoded...).Err(); e != nil {
+		return client.wrap(e, "RedisSAddJSON", "sadd", key)
+	}
+	return nil
+}
+
+func (client *Client) SRemJSON(ctx context.Context, key string, members ...interface{}) error {
+	encoded, e := client.encodeMembers("RedisSRemJSON", key, members)
+	if e != nil {
+		return e
+	}
+	if e := client.client.SRem(ctx, client.fullKey(key), encoded...).Err(); e != nil {
+		return client.wrap(e, "RedisSRemJSON", "srem", key)
+	}
+	return nil
+}
+
+func (client *Client) SIsMemberJSON(ctx context.Context, key string, member interface{}) (bool, error) {
+	encoded, e := client.encodeMembers("RedisSIsMemberJSON", key, []interface{}{member})
+	if e != nil {
+		return false, e
+	}
+	has, e := client.reader(ctx).SIsMember(ctx, client.fullKey(key), encoded[0]).Result()
+	if e != nil {
+		return false, client.wrap(e, "RedisSIsMemberJSON", "sismember", key)
+	}
+	return has, nil
+}
+
+// SMembersJSON decodes the members into dest, a pointer to a slice, in no
+// particular order.
+func (client *Client) SMembersJSON(ctx context.Context, key string, dest interface{}) error {
+	s := reflect.ValueOf(dest)
+	if s.Kind() != reflect.Pointer || s.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("redis: dest must be a pointer to a slice, got %T", dest)
+	}
+	s = s.Elem()
+
+	members, e := client.reader(ctx).SMembers(ctx, client.fullKey(key)).Result()
+	if e != nil {
+		return client.wrap(e, "RedisSMembersJSON", "smembers", key)
+	}
+	out := reflect.MakeSlice(s.Type(), 0, len(members))
+	for _, m := range members {
+		elem := reflect.New(s.Type().Elem())
+		if e := client.codec.Unmarshal([]byte(m), elem.Interface()); e != nil {
+			return client.wrap(e, "RedisSMembersJSON:JSONUnmarshal", "", key)
+		}
+		out = reflect.Append(out, elem.Elem())
+	}
+	s.Set(out)
+	return nil
+}
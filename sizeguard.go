@@ -0,0 +1,23 @@
+package redis
+
+import (
+	"github.com/pkg/errors"
+)
+
+var ErrValueTooLarge = errors.New("redis: value exceeds Config.MaxValueSize")
+
+// checkSize enforces Config.MaxValueSize on an encoded value. The limit applies
+// after compression, it is about what ends up in the server's memory.
+func (client *Client) checkSize(op, key string, data []byte) error {
+	limit := client.config.MaxValueSize
+	if limit <= 0 || len(data) <= limit {
+		return nil
+	}
+	rejected := !client.config.MaxValueSizeWarn
+	client.metrics.each(func(m Metrics) { m.ObserveOversizedValue(op, len(data), rejected) })
+	if !rejected {
+		client.log().Warn(op+":Size", "key", client.logKey(key), "size", len(data), "limit", limit)
+		return nil
+	}
+	return client.wrap(ErrValueTooLarge, op, "", key)
+}
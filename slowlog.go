@@ -0,0 +1,159 @@
+package redis
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+type SlowLogConfig struct {
+	Interval   time.Duration // Poll interval, defaults to one minute
+	Threshold  time.Duration // Only report commands slower than this
+	Count      int64         // Entries read per node and poll, defaults to 128
+	AllClients bool          // Also report commands issued by other connections than Config.ClientName, always on without a ClientName
+}
+
+type SlowLogEntry struct {
+	ID         int64
+	Node       string
+	Time       time.Time
+	Duration   time.Duration
+	Command    string
+	Args       []string
+	ClientAddr string
+	ClientName string
+}
+
+// SlowLogPoller reads SLOWLOG from every master node and reports each entry
+// at most once.
+type SlowLogPoller struct {
+	worker
+	client  *Client
+	cfg     SlowLogConfig
+	handler func(SlowLogEntry)
+
+	started time.Time
+	mu      sync.Mutex
+	lastIDs map[string]int64
+}
+
+// NewSlowLogPoller returns a stopped poller, Start runs it under ctx.
+func (client *Client) NewSlowLogPoller(ctx context.Context, cfg SlowLogConfig, handler func(SlowLogEntry)) *SlowLogPoller {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Minute
+	}
+	if cfg.Count <= 0 {
+		cfg.Count = 128
+	}
+	// Own connections can only be told apart by their name
+	if client.config.ClientName == "" {
+		cfg.AllClients = true
+	}
+	p := &SlowLogPoller{
+		client:  client,
+		cfg:     cfg,
+		handler: handler,
+		started: time.Now(),
+		lastIDs: make(map[string]int64),
+	}
+	p.init(client, "RedisSlowLog:Poll", ctx, p.loop)
+	return p
+}
+
+func (p *SlowLogPoller) Poll(ctx context.Context) error {
+	if cc, ok := p.client.client.(*goredis.ClusterClient); ok {
+		return cc.ForEachMaster(ctx, func(ctx context.Context, node *goredis.Client) error {
+			return p.pollNode(ctx, node, node.Options().Addr)
+		})
+	}
+	return p.pollNode(ctx, p.client.client, p.client.nodeAddr())
+}
+
+func (p *SlowLogPoller) pollNode(ctx context.Context, node goredis.Cmdable, addr string) error {
+	logs, e := node.SlowLogGet(ctx, p.cfg.Count).Result()
+	if e != nil {
+		return p.client.wrap(e, "RedisSlowLog", "slowlog", "")
+	}
+
+	p.mu.Lock()
+	last, seen := p.lastIDs[addr]
+	var max int64 = -1
+	for _, l := range logs {
+		if l.ID > max {
+			max = l.ID
+		}
+	}
+	if max >= 0 {
+		p.lastIDs[addr] = max
+	}
+	p.mu.Unlock()
+
+	// Ids restart from zero when the server restarts
+	if max < last {
+		seen = false
+	}
+
+	for i := len(logs) - 1; i >= 0; i-- {
+		l := logs[i]
+		if seen && l.ID <= last {
+			continue
+		}
+		if !seen && l.Time.Before(p.started) {
+			continue
+		}
+		if l.Duration < p.cfg.Threshold {
+			continue
+		}
+		if !p.cfg.AllClients && l.ClientName != p.client.config.ClientName {
+			continue
+		}
+
+		entry := SlowLogEntry{
+			ID:         l.ID,
+			Node:       addr,
+			Time:       l.Time,
+			Duration:   l.Duration,
+			Args:       l.Args,
+			ClientAddr: l.ClientAddr,
+			ClientName: l.ClientName,
+		}
+		if len(l.Args) > 0 {
+			entry.Command = strings.ToLower(l.Args[0])
+		}
+		p.client.metrics.each(func(m Metrics) { m.ObserveSlowCommand(entry.Command, entry.Duration) })
+		if p.handler != nil {
+			p.handle(entry)
+		}
+	}
+	return nil
+}
+
+func (p *SlowLogPoller) handle(entry SlowLogEntry) {
+	defer p.client.recoverPanic("slowlog", nil)
+	p.handler(entry)
+}
+
+// Run polls every Interval until ctx is done.
+func (p *SlowLogPoller) Run(ctx context.Context) {
+	p.loop(ctx, func(e error) {
+		p.client.log().Warn("RedisSlowLog:Poll", "error", e)
+	})
+}
+
+func (p *SlowLogPoller) loop(ctx context.Context, report func(error)) error {
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if e := p.Poll(ctx); e != nil && ctx.Err() == nil {
+				report(e)
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Values written by SetSoft start with a header holding the soft expiry in
+// unix milliseconds, Get strips it so they stay readable as plain values.
+const softMarker = "\x00soft:"
+
+type SoftMeta struct {
+	IsStale       bool // The soft TTL passed, the value should be refreshed
+	SoftExpiresAt time.Time
+}
+
+// SetSoft stores v as fresh for softTTL seconds and keeps it for ttl seconds,
+// so that it can still be served while it is refreshed.
+func (client *Client) SetSoft(ctx context.Context, key string, v interface{}, softTTL, ttl int) error {
+	key_str := client.fullKey(key)
+	data_str, e := client.codec.Marshal(v)
+	if e != nil {
+		return client.wrap(e, "RedisSetSoft:JSONMarshal", "", key)
+	}
+	soft := time.Now().Add(time.Duration(softTTL) * time.Second).UnixMilli()
+	value := softMarker + strconv.FormatInt(soft, 10) + "\n" + string(data_str)
+
+	if e := client.client.Set(ctx, key_str, value, client.expiration(ttl)).Err(); e != nil {
+		return client.wrap(e, "RedisSetSoft", "set", key)
+	}
+	client.invalidateLocal(ctx, key_str)
+	return nil
+}
+
+// GetSoft decodes key into v and reports whether its soft TTL passed. Values
+// written without SetSoft are never stale.
+func (client *Client) GetSoft(ctx context.Context, key string, v interface{}) (*SoftMeta, error) {
+	key_str := client.fullKey(key)
+	data_str, e := client.getRaw(ctx, key_str)
+	if e != nil {
+		if e == goredis.Nil {
+			return nil, ErrNotFound
+		}
+		return nil, client.wrap(e, "RedisGetSoft", "get", key)
+	}
+
+	meta := &SoftMeta{}
+	data_str, soft, ok := splitSoft(data_str)
+	if ok {
+		meta.SoftExpiresAt = time.UnixMilli(soft)
+		meta.IsStale = !time.Now().Before(meta.SoftExpiresAt)
+	}
+	if data_str == "" || data_str == notFoundMarker {
+		return nil, ErrNotFound
+	}
+	if e := client.codec.Unmarshal([]byte(data_str), v); e != nil {
+		return nil, client.wrap(e, "RedisGetSoft:JSONUnmarshal", "", key)
+	}
+	return meta, nil
+}
+
+func splitSoft(data_str string) (string, int64, bool) {
+	if !strings.HasPrefix(data_str, softMarker) {
+		return data_str, 0, false
+	}
+	header, body, ok := strings.Cut(data_str[len(softMarker):], "\n")
+	if !ok {
+		return data_str, 0, false
+	}
+	soft, e := strconv.ParseInt(header, 10, 64)
+	if e != nil {
+		return data_str, 0, false
+	}
+	return body, soft, true
+}
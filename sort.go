@@ -0,0 +1,87 @@
+package redis
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+var ErrSortPattern = errors.New("redis: SORT patterns can not be used with a KeyProvider")
+
+type SortLimit struct {
+	Offset int64
+	Count  int64
+}
+
+// SortOptions patterns are keys without the prefix, * is replaced by the
+// element and ->field reads a hash field, e.g. "user:*->age". "#" in Get is
+// the element itself and "nosort" as By keeps the order of key. Patterns
+// reach other keys than key, so they need all keys in one slot on a cluster.
+type SortOptions struct {
+	By    string
+	Get   []string
+	Limit *SortLimit
+	Order string // ASC or DESC, ASC when empty
+	Alpha bool   // Sort lexicographically instead of numerically
+}
+
+func (client *Client) sortArgs(opts *SortOptions) (*goredis.Sort, error) {
+	sort := &goredis.Sort{}
+	if opts == nil {
+		return sort, nil
+	}
+	if client.config.KeyProvider != nil && (opts.By != "" && opts.By != "nosort" || len(opts.Get) > 0) {
+		return nil, ErrSortPattern
+	}
+	pattern := func(p string) string {
+		if p == "#" || p == "nosort" || p == "" {
+			return p
+		}
+		return client.config.Prefix + ":" + p
+	}
+	sort.By = pattern(opts.By)
+	for _, g := range opts.Get {
+		sort.Get = append(sort.Get, pattern(g))
+	}
+	if opts.Limit != nil {
+		sort.Offset, sort.Count = opts.Limit.Offset, opts.Limit.Count
+	}
+	sort.Order = strings.ToUpper(opts.Order)
+	sort.Alpha = opts.Alpha
+	return sort, nil
+}
+
+// Sort returns the sorted elements of a list, set or sorted set, or the
+// values of the Get patterns for every element in order.
+func (client *Client) Sort(ctx context.Context, key string, opts *SortOptions) ([]string, error) {
+	sort, e := client.sortArgs(opts)
+	if e != nil {
+		return nil, client.wrap(e, "RedisSort", "sort", key)
+	}
+	res, e := client.client.Sort(ctx, client.fullKey(key), sort).Result()
+	if e != nil {
+		return nil, client.wrap(e, "RedisSort", "sort", key)
+	}
+	return res, nil
+}
+
+// SortStore stores the result of Sort into the list dest and returns its
+// length.
+func (client *Client) SortStore(ctx context.Context, key, dest string, opts *SortOptions) (int64, error) {
+	sort, e := client.sortArgs(opts)
+	if e != nil {
+		return 0, client.wrap(e, "RedisSortStore", "sort", key)
+	}
+	if e := client.checkSlots(key, dest); e != nil {
+		return 0, client.wrap(e, "RedisSortStore", "sort", key)
+	}
+	dest_str := client.fullKey(dest)
+	n, e := client.client.SortStore(ctx, client.fullKey(key), dest_str, sort).Result()
+	if e != nil {
+		return 0, client.wrap(e, "RedisSortStore", "sort", key)
+	}
+	client.invalidateLocal(ctx, dest_str)
+	return n, nil
+}
@@ -0,0 +1,46 @@
+package redis
+
+import "context"
+
+// Append adds value to the end of the string at key, creating it when
+// missing, and returns the new length. The expiry of the key is kept.
+func (client *Client) Append(ctx context.Context, key, value string) (int64, error) {
+	key_str := client.fullKey(key)
+	n, e := client.client.Append(ctx, key_str, value).Result()
+	if e != nil {
+		return 0, client.wrap(e, "RedisAppend", "append", key)
+	}
+	client.invalidateLocal(ctx, key_str)
+	return n, nil
+}
+
+// StrLen returns the length of the string at key, 0 when it does not exist.
+func (client *Client) StrLen(ctx context.Context, key string) (int64, error) {
+	n, e := client.reader(ctx).StrLen(ctx, client.fullKey(key)).Result()
+	if e != nil {
+		return 0, client.wrap(e, "RedisStrLen", "strlen", key)
+	}
+	return n, nil
+}
+
+// SetRange overwrites the string at key from offset on, padding with zero
+// bytes when it is shorter, and returns the new length.
+func (client *Client) SetRange(ctx context.Context, key string, offset int64, value string) (int64, error) {
+	key_str := client.fullKey(key)
+	n, e := client.client.SetRange(ctx, key_str, offset, value).Result()
+	if e != nil {
+		return 0, client.wrap(e, "RedisSetRange", "setrange", key)
+	}
+	client.invalidateLocal(ctx, key_str)
+	return n, nil
+}
+
+// GetRange returns the bytes between start and end, both included, negative
+// offsets count from the end. A missing key reads as an empty string.
+func (client *Client) GetRange(ctx context.Context, key string, start, end int64) (string, error) {
+	s, e := client.reader(ctx).GetRange(ctx, client.fullKey(key), start, end).Result()
+	if e != nil {
+		return "", client.wrap(e, "RedisGetRange", "getrange", key)
+	}
+	return s, nil
+}
@@ -0,0 +1,109 @@
+package redis
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrInvalidTenant = errors.New("redis: invalid tenant id")
+	ErrCrossTenant   = errors.New("redis: key outside of the tenant")
+)
+
+// Tenant ids are kept to a safe alphabet so that no tenant prefix is the start
+// of another one and ids can not smuggle hash tags or glob patterns.
+var tenantID = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,128}$`)
+
+type TenantConfig struct {
+	// Database of the tenant, for standalone and sentinel servers. The tenant
+	// gets its own connection pool, 0 shares the pool of the client.
+	DB int
+	// Wrap the tenant id in a hash tag, <prefix>:{t:<id>}, so every key of the
+	// tenant lands in one cluster slot and multi-key commands work across
+	// them. Hash tags of the keys themselves are then ignored.
+	HashTag bool
+}
+
+type tenantScope struct {
+	id     string
+	root   string // Prefix of the client ForTenant was called on
+	prefix string
+}
+
+// owns reports whether a full key belongs to the tenant.
+func (t *tenantScope) owns(key string) bool {
+	return strings.HasPrefix(key, t.prefix+":")
+}
+
+// ForTenant returns a client whose keys live under <prefix>:t:<id>. The
+// wrappers can only reach keys of the tenant, Do refuses arguments naming keys
+// of other tenants and the thin client refuses keys outside of the tenant.
+func (client *Client) ForTenant(id string, cfg TenantConfig) (*Client, error) {
+	if client.tenant != nil {
+		return nil, errors.Wrapf(ErrInvalidTenant, "client already scoped to tenant %s", client.tenant.id)
+	}
+	if !tenantID.MatchString(id) {
+		return nil, errors.Wrapf(ErrInvalidTenant, "%q", id)
+	}
+
+	t := &tenantScope{id: id, root: client.config.Prefix}
+	if cfg.HashTag {
+		t.prefix = t.root + ":{t:" + id + "}"
+	} else {
+		t.prefix = t.root + ":t:" + id
+	}
+
+	if cfg.DB == 0 || cfg.DB == client.config.DB {
+		c := *client
+		tcfg := *client.config
+		tcfg.Prefix = t.prefix
+		c.config = &tcfg
+		c.tenant = t
+		return &c, nil
+	}
+
+	if client.config.Cluster {
+		return nil, errors.New("redis: tenant databases are not available in cluster mode")
+	}
+	tcfg := *client.config
+	tcfg.URL = ""
+	tcfg.Prefix = t.prefix
+	tcfg.DB = cfg.DB
+	c, e := NewClient(&tcfg)
+	if e != nil {
+		return nil, e
+	}
+	c.codec = client.codec
+	c.defaultTTL = client.defaultTTL
+	c.ttlJitter = client.ttlJitter
+	c.schemas = client.schemas
+	c.panicHandler = client.panicHandler
+	c.readPref = client.readPref
+	c.tenant = t
+	return c, nil
+}
+
+// Tenant returns the id of the tenant the client is scoped to, if any.
+func (client *Client) Tenant() string {
+	if client.tenant == nil {
+		return ""
+	}
+	return client.tenant.id
+}
+
+// crossTenant returns the first argument naming a key of another tenant, or of
+// the parent client outside of any tenant.
+func (client *Client) crossTenant(args []interface{}) (string, bool) {
+	t := client.tenant
+	if t == nil {
+		return "", false
+	}
+	for _, arg := range args {
+		if s, ok := arg.(string); ok && strings.HasPrefix(s, t.root+":") && !t.owns(s) {
+			return s, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,79 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+type opTimeoutKey struct{}
+
+// WithOperationTimeout overrides Config.OperationTimeout and
+// Config.CommandTimeouts for the commands sent with the returned context, 0
+// sends them without a deadline.
+func WithOperationTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, opTimeoutKey{}, timeout)
+}
+
+// Commands that block on the server by design keep the context of the caller.
+var blockingCommands = map[string]bool{
+	"blpop": true, "brpop": true, "brpoplpush": true, "blmove": true, "blmpop": true,
+	"bzpopmin": true, "bzpopmax": true, "bzmpop": true, "xread": true, "xreadgroup": true, "wait": true,
+}
+
+// timeoutHook puts a deadline on commands whose context has none, so a
+// stalled server fails calls instead of piling up goroutines.
+type timeoutHook struct {
+	client *Client
+}
+
+func (h *timeoutHook) timeout(ctx context.Context, cmd string) time.Duration {
+	if d, ok := ctx.Value(opTimeoutKey{}).(time.Duration); ok {
+		return d
+	}
+	if d, ok := h.client.config.CommandTimeouts[cmd]; ok {
+		return d
+	}
+	return h.client.config.OperationTimeout
+}
+
+func (h *timeoutHook) DialHook(next goredis.DialHook) goredis.DialHook {
+	return next
+}
+
+func (h *timeoutHook) ProcessHook(next goredis.ProcessHook) goredis.ProcessHook {
+	return func(ctx context.Context, cmd goredis.Cmder) error {
+		if _, ok := ctx.Deadline(); ok || blockingCommands[cmd.Name()] {
+			return next(ctx, cmd)
+		}
+		if d := h.timeout(ctx, cmd.Name()); d > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, d)
+			defer cancel()
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (h *timeoutHook) ProcessPipelineHook(next goredis.ProcessPipelineHook) goredis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []goredis.Cmder) error {
+		if _, ok := ctx.Deadline(); ok {
+			return next(ctx, cmds)
+		}
+		// The longest timeout of the commands bounds the whole pipeline
+		var d time.Duration
+		for _, cmd := range cmds {
+			if blockingCommands[cmd.Name()] {
+				return next(ctx, cmds)
+			}
+			d = max(d, h.timeout(ctx, cmd.Name()))
+		}
+		if d > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, d)
+			defer cancel()
+		}
+		return next(ctx, cmds)
+	}
+}
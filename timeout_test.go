@@ -0,0 +1,96 @@
+package redis_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/acsl-go/redis"
+	"github.com/acsl-go/redis/redistest"
+)
+
+// deadlineHook records the deadline every command is sent with.
+type deadlineHook struct {
+	mu   sync.Mutex
+	left map[string]time.Duration // 0 without deadline
+}
+
+func (h *deadlineHook) BeforeCommand(ctx context.Context, info *redis.CommandInfo) context.Context {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.left[info.Name] = 0
+	if deadline, ok := ctx.Deadline(); ok {
+		h.left[info.Name] = time.Until(deadline)
+	}
+	return ctx
+}
+
+func (h *deadlineHook) AfterCommand(ctx context.Context, info *redis.CommandInfo) {}
+
+func (h *deadlineHook) deadline(name string) time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.left[name]
+}
+
+func TestOperationTimeout(t *testing.T) {
+	h := &deadlineHook{left: map[string]time.Duration{}}
+	client, _ := redistest.NewTestClient(t, &redis.Config{
+		Prefix:           "test",
+		OperationTimeout: time.Second,
+		CommandTimeouts:  map[string]time.Duration{"get": 5 * time.Second},
+		Hooks:            []redis.Hook{h},
+	})
+	ctx := context.Background()
+
+	if e := client.SetStr(ctx, "k", "v", 60); e != nil {
+		t.Fatal(e)
+	}
+	if d := h.deadline("set"); d <= 0 || d > time.Second {
+		t.Errorf("set deadline %v, want OperationTimeout", d)
+	}
+	if _, e := client.GetStr(ctx, "k"); e != nil {
+		t.Fatal(e)
+	}
+	if d := h.deadline("get"); d <= time.Second || d > 5*time.Second {
+		t.Errorf("get deadline %v, want the CommandTimeouts entry", d)
+	}
+
+	// The deadline of the caller wins
+	short, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	if _, e := client.GetStr(short, "k"); e != nil {
+		t.Fatal(e)
+	}
+	if d := h.deadline("get"); d <= 0 || d > 100*time.Millisecond {
+		t.Errorf("get deadline %v, want the one of the caller", d)
+	}
+
+	// Blocking commands keep the context of the caller
+	if _, _, e := client.BLPop(ctx, 10*time.Millisecond, "missing"); e != redis.ErrNotFound {
+		t.Fatalf("BLPop = %v, want ErrNotFound", e)
+	}
+	if d := h.deadline("blpop"); d != 0 {
+		t.Errorf("blpop deadline %v, want none", d)
+	}
+}
+
+func TestWithOperationTimeoutWithoutConfig(t *testing.T) {
+	h := &deadlineHook{left: map[string]time.Duration{}}
+	client, _ := redistest.NewTestClient(t, &redis.Config{Prefix: "test", Hooks: []redis.Hook{h}})
+	ctx := context.Background()
+
+	if e := client.SetStr(ctx, "k", "v", 60); e != nil {
+		t.Fatal(e)
+	}
+	if d := h.deadline("set"); d != 0 {
+		t.Errorf("set deadline %v, want none", d)
+	}
+	if e := client.SetStr(redis.WithOperationTimeout(ctx, 200*time.Millisecond), "k", "v", 60); e != nil {
+		t.Fatal(e)
+	}
+	if d := h.deadline("set"); d <= 0 || d > 200*time.Millisecond {
+		t.Errorf("set deadline %v, want the one of WithOperationTimeout", d)
+	}
+}
@@ -0,0 +1,159 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// TS.* commands need the RedisTimeSeries module. Every series gets a
+// tsPrefixLabel label with the client prefix so TSMRange only sees the series
+// of this prefix.
+const tsPrefixLabel = "__prefix"
+
+type TSSample struct {
+	Time  time.Time
+	Value float64
+}
+
+type TSAggregation struct {
+	Aggregator goredis.Aggregator // goredis.Avg, goredis.Min, goredis.Max, ...
+	Bucket     time.Duration
+}
+
+type TSSeries struct {
+	Labels  map[string]string
+	Samples []TSSample
+}
+
+// TSCreate creates a series keeping samples for retention, 0 keeps them
+// forever.
+func (client *Client) TSCreate(ctx context.Context, key string, retention time.Duration, labels map[string]string) error {
+	key_str := client.fullKey(key)
+	all := map[string]string{tsPrefixLabel: client.config.Prefix}
+	for k, v := range labels {
+		all[k] = v
+	}
+	opts := &goredis.TSOptions{Retention: int(retention.Milliseconds()), Labels: all}
+	if e := client.client.TSCreateWithArgs(ctx, key_str, opts).Err(); e != nil {
+		return client.wrap(e, "RedisTSCreate", "ts.create", key)
+	}
+	return nil
+}
+
+// TSAdd adds a sample, a zero time means the server time. The series is
+// created without retention when missing.
+func (client *Client) TSAdd(ctx context.Context, key string, at time.Time, value float64) error {
+	key_str := client.fullKey(key)
+	var ts interface{} = "*"
+	if !at.IsZero() {
+		ts = at.UnixMilli()
+	}
+	opts := &goredis.TSOptions{Labels: map[string]string{tsPrefixLabel: client.config.Prefix}}
+	if e := client.client.TSAddWithArgs(ctx, key_str, ts, value, opts).Err(); e != nil {
+		return client.wrap(e, "RedisTSAdd", "ts.add", key)
+	}
+	return nil
+}
+
+// tsBounds sends open ends as "-" and "+", go-redis only takes timestamps
+// there, so the range commands are built here.
+func tsBounds(from, to time.Time) (interface{}, interface{}) {
+	var start, end interface{} = "-", "+"
+	if !from.IsZero() {
+		start = from.UnixMilli()
+	}
+	if !to.IsZero() {
+		end = to.UnixMilli()
+	}
+	return start, end
+}
+
+func tsAggregation(args []interface{}, agg *TSAggregation) []interface{} {
+	if agg == nil {
+		return args
+	}
+	return append(args, "AGGREGATION", agg.Aggregator.String(), agg.Bucket.Milliseconds())
+}
+
+// TSRange returns the samples in [from, to], a zero from meaning from the
+// first and a zero to up to the last sample, aggregated in buckets when agg
+// is set.
+func (client *Client) TSRange(ctx context.Context, key string, from, to time.Time, agg *TSAggregation) ([]TSSample, error) {
+	start, end := tsBounds(from, to)
+	args := tsAggregation([]interface{}{"TS.RANGE", client.fullKey(key), start, end}, agg)
+	values, e := client.client.Do(ctx, args...).Slice()
+	if e != nil {
+		return nil, client.wrap(e, "RedisTSRange", "ts.range", key)
+	}
+	s := &TSSeries{Labels: map[string]string{}}
+	parseTSPart(s, values)
+	return s.Samples, nil
+}
+
+// TSMRange queries every series of the prefix matching the label filters,
+// e.g. "region=eu". Series are keyed without the prefix.
+func (client *Client) TSMRange(ctx context.Context, from, to time.Time, filters []string, agg *TSAggregation) (map[string]*TSSeries, error) {
+	start, end := tsBounds(from, to)
+	args := tsAggregation([]interface{}{"TS.MRANGE", start, end, "WITHLABELS"}, agg)
+	args = append(args, "FILTER", tsPrefixLabel+"="+client.config.Prefix)
+	for _, f := range filters {
+		args = append(args, f)
+	}
+	cmd := goredis.NewMapStringSliceInterfaceCmd(ctx, args...)
+	_ = client.client.Process(ctx, cmd)
+	res, e := cmd.Result()
+	if e != nil {
+		return nil, client.wrap(e, "RedisTSMRange", "ts.mrange", "")
+	}
+
+	series := make(map[string]*TSSeries, len(res))
+	for key_str, parts := range res {
+		s := &TSSeries{Labels: map[string]string{}}
+		for _, part := range parts {
+			parseTSPart(s, part)
+		}
+		delete(s.Labels, tsPrefixLabel)
+		series[strings.TrimPrefix(key_str, client.config.Prefix+":")] = s
+	}
+	return series, nil
+}
+
+// The reply of TS.MRANGE differs between RESP2 (label pairs) and RESP3
+// (label map), samples are [timestamp, value] pairs in both.
+func parseTSPart(s *TSSeries, part interface{}) {
+	switch p := part.(type) {
+	case map[interface{}]interface{}:
+		for k, v := range p {
+			if ks, ok := k.(string); ok {
+				s.Labels[ks] = stringOf(v)
+			}
+		}
+	case []interface{}:
+		for _, item := range p {
+			pair, ok := item.([]interface{})
+			if !ok || len(pair) != 2 {
+				continue
+			}
+			if name, ok := pair[0].(string); ok {
+				s.Labels[name] = stringOf(pair[1])
+				continue
+			}
+			ts, ok := pair[0].(int64)
+			if !ok {
+				continue
+			}
+			var value float64
+			switch v := pair[1].(type) {
+			case float64:
+				value = v
+			case string:
+				value, _ = strconv.ParseFloat(v, 64)
+			}
+			s.Samples = append(s.Samples, TSSample{Time: time.UnixMilli(ts), Value: value})
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package redis
+
+import (
+	"hash/maphash"
+)
+
+// frequencySketch is a count-min sketch with 4 bit counters. All counters are
+// halved every sampleSize increments so that the frequencies age.
+type frequencySketch struct {
+	seed       maphash.Seed
+	table      []uint64 // 16 counters per word
+	mask       uint64
+	additions  int
+	sampleSize int
+}
+
+func newFrequencySketch(size int) *frequencySketch {
+	n := 1
+	for n < size {
+		n <<= 1
+	}
+	n = max(n/4, 1)
+	return &frequencySketch{
+		seed:       maphash.MakeSeed(),
+		table:      make([]uint64, n),
+		mask:       uint64(n - 1),
+		sampleSize: 10 * max(size, 1),
+	}
+}
+
+func (s *frequencySketch) indexes(key string) [4]uint64 {
+	h := maphash.String(s.seed, key)
+	var idx [4]uint64
+	for i := range idx {
+		// Word and counter of row i, derived from different bits of the hash
+		word := (h >> (i * 8)) & s.mask
+		counter := (h >> (32 + i*4)) & 15
+		idx[i] = word<<4 | counter
+	}
+	return idx
+}
+
+func (s *frequencySketch) increment(key string) {
+	added := false
+	for _, i := range s.indexes(key) {
+		word, shift := i>>4, (i&15)*4
+		if (s.table[word]>>shift)&15 < 15 {
+			s.table[word] += 1 << shift
+			added = true
+		}
+	}
+	if added {
+		s.additions++
+		if s.additions >= s.sampleSize {
+			s.reset()
+		}
+	}
+}
+
+func (s *frequencySketch) frequency(key string) uint64 {
+	min := uint64(15)
+	for _, i := range s.indexes(key) {
+		word, shift := i>>4, (i&15)*4
+		if c := (s.table[word] >> shift) & 15; c < min {
+			min = c
+		}
+	}
+	return min
+}
+
+func (s *frequencySketch) reset() {
+	for i := range s.table {
+		s.table[i] = (s.table[i] >> 1) & 0x7777777777777777
+	}
+	s.additions /= 2
+}
@@ -0,0 +1,221 @@
+package redis
+
+import (
+	"context"
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+type TouchTrackingConfig struct {
+	SampleRate float64       `mapstructure:"sample_rate"` // Share of keys tracked, 0 disables tracking
+	Retention  time.Duration `mapstructure:"retention"`   // Drop tracking data older than this, defaults to one day
+}
+
+var touchReads = map[string]bool{
+	"get": true, "getex": true, "getdel": true, "getrange": true, "strlen": true, "mget": true,
+	"hget": true, "hmget": true, "hgetall": true, "hexists": true,
+	"smembers": true, "sismember": true, "smismember": true, "scard": true,
+	"zrange": true, "zrevrange": true, "zrangebyscore": true, "zscore": true, "zrank": true, "zrevrank": true,
+	"lrange": true, "lindex": true, "llen": true,
+	"geosearch": true, "geodist": true, "geopos": true, "pfcount": true, "getbit": true, "bitcount": true, "bitpos": true,
+}
+
+type touchEvent struct {
+	key   string
+	write bool
+	at    time.Time
+}
+
+// Sampled key accesses are buffered and flushed into the <prefix>:touch:w and
+// <prefix>:touch:r zsets (member = key, score = last access in ms). Sampling
+// is by key hash so a tracked key has both its reads and writes recorded.
+type touchTracker struct {
+	client *Client
+	cfg    TouchTrackingConfig
+	events chan touchEvent
+}
+
+func newTouchTracker(client *Client, cfg TouchTrackingConfig) *touchTracker {
+	if cfg.Retention <= 0 {
+		cfg.Retention = 24 * time.Hour
+	}
+	return &touchTracker{client: client, cfg: cfg, events: make(chan touchEvent, 4096)}
+}
+
+func (t *touchTracker) sampled(key string) bool {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return float64(h.Sum32()%10000) < t.cfg.SampleRate*10000
+}
+
+func (t *touchTracker) observe(cmd goredis.Cmder) {
+	if cmd.Err() != nil && cmd.Err() != goredis.Nil {
+		return
+	}
+	var write bool
+	var keys []string
+	if touchReads[cmd.Name()] {
+		if args := cmd.Args(); len(args) > 1 {
+			if s, ok := args[1].(string); ok {
+				keys = []string{s}
+			}
+		}
+	} else if keys = writtenKeys(cmd); keys != nil {
+		write = true
+	}
+
+	prefix := t.client.config.Prefix + ":"
+	for _, key := range keys {
+		if !strings.HasPrefix(key, prefix) || strings.HasPrefix(key, prefix+"touch:") || !t.sampled(key) {
+			continue
+		}
+		select {
+		case t.events <- touchEvent{key: key, write: write, at: time.Now()}:
+		default: // Tracking is best effort, never slow down commands
+		}
+	}
+}
+
+func (t *touchTracker) run(ctx context.Context) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	pending := make(map[touchEvent]struct{})
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev := <-t.events:
+			ev.at = ev.at.Truncate(time.Second)
+			pending[ev] = struct{}{}
+		case <-ticker.C:
+			if len(pending) > 0 {
+				t.flush(ctx, pending)
+				pending = make(map[touchEvent]struct{})
+			}
+		}
+	}
+}
+
+func (t *touchTracker) flush(ctx context.Context, events map[touchEvent]struct{}) {
+	writes, reads := t.client.touchKeys()
+	pipe := t.client.client.Pipeline()
+	for ev := range events {
+		z := goredis.Z{Score: float64(ev.at.UnixMilli()), Member: ev.key}
+		if ev.write {
+			pipe.ZAddArgs(ctx, writes, goredis.ZAddArgs{GT: true, Members: []goredis.Z{z}})
+		} else {
+			pipe.ZAddArgs(ctx, reads, goredis.ZAddArgs{GT: true, Members: []goredis.Z{z}})
+		}
+	}
+	cutoff := "(" + strconv.FormatInt(time.Now().Add(-t.cfg.Retention).UnixMilli(), 10)
+	pipe.ZRemRangeByScore(ctx, writes, "-inf", cutoff)
+	pipe.ZRemRangeByScore(ctx, reads, "-inf", cutoff)
+	if _, e := pipe.Exec(ctx); e != nil {
+		t.client.log().Warn("RedisTouchTracking:Flush", "error", e)
+	}
+}
+
+func (client *Client) touchKeys() (string, string) {
+	return client.config.Prefix + ":touch:w", client.config.Prefix + ":touch:r"
+}
+
+type UnreadKey struct {
+	Key       string // Without the prefix
+	WrittenAt time.Time
+	Bytes     int64 // MEMORY USAGE, 0 when the key is gone
+}
+
+type TouchReport struct {
+	Tracked     int64       // Sampled keys with a recorded write
+	Unread      []UnreadKey // Written at least minAge ago and not read since
+	UnreadBytes int64
+	SampleRate  float64 // Divide counts by it to extrapolate to all keys
+}
+
+// TouchReport lists the sampled keys that were not read after their last
+// write, with their memory cost. Requires Config.TouchTracking.
+func (client *Client) TouchReport(ctx context.Context, minAge time.Duration, limit int) (*TouchReport, error) {
+	writes, reads := client.touchKeys()
+	cutoff := strconv.FormatInt(time.Now().Add(-minAge).UnixMilli(), 10)
+	written, e := client.client.ZRangeByScoreWithScores(ctx, writes, &goredis.ZRangeBy{Min: "-inf", Max: cutoff}).Result()
+	if e != nil {
+		return nil, client.wrap(e, "RedisTouchReport", "zrangebyscore", "touch:w")
+	}
+	tracked, e := client.client.ZCard(ctx, writes).Result()
+	if e != nil {
+		return nil, client.wrap(e, "RedisTouchReport", "zcard", "touch:w")
+	}
+
+	report := &TouchReport{Tracked: tracked, SampleRate: client.config.TouchTracking.SampleRate}
+	if len(written) == 0 {
+		return report, nil
+	}
+
+	pipe := client.client.Pipeline()
+	readAt := make([]*goredis.FloatCmd, len(written))
+	for i, z := range written {
+		readAt[i] = pipe.ZScore(ctx, reads, z.Member.(string))
+	}
+	pipe.Exec(ctx)
+
+	prefix := client.config.Prefix + ":"
+	var candidates []UnreadKey
+	for i, z := range written {
+		if r, e := readAt[i].Result(); e == nil && r >= z.Score {
+			continue
+		}
+		candidates = append(candidates, UnreadKey{
+			Key:       strings.TrimPrefix(z.Member.(string), prefix),
+			WrittenAt: time.UnixMilli(int64(z.Score)),
+		})
+		if limit > 0 && len(candidates) >= limit {
+			break
+		}
+	}
+
+	pipe = client.client.Pipeline()
+	usage := make([]*goredis.IntCmd, len(candidates))
+	for i, c := range candidates {
+		usage[i] = pipe.MemoryUsage(ctx, prefix+c.Key)
+	}
+	pipe.Exec(ctx)
+	for i, c := range candidates {
+		if usage[i].Err() != nil {
+			continue // Deleted since, nothing to trim
+		}
+		c.Bytes = usage[i].Val()
+		report.UnreadBytes += c.Bytes
+		report.Unread = append(report.Unread, c)
+	}
+	return report, nil
+}
+
+type touchHook struct {
+	t *touchTracker
+}
+
+func (h *touchHook) DialHook(next goredis.DialHook) goredis.DialHook {
+	return next
+}
+
+func (h *touchHook) ProcessHook(next goredis.ProcessHook) goredis.ProcessHook {
+	return func(ctx context.Context, cmd goredis.Cmder) error {
+		e := next(ctx, cmd)
+		h.t.observe(cmd)
+		return e
+	}
+}
+
+func (h *touchHook) ProcessPipelineHook(next goredis.ProcessPipelineHook) goredis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []goredis.Cmder) error {
+		e := next(ctx, cmds)
+		for _, cmd := range cmds {
+			h.t.observe(cmd)
+		}
+		return e
+	}
+}
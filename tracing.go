@@ -0,0 +1,65 @@
+package redis
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/acsl-go/redis"
+
+// EnableTracing records a span for every command, tp defaults to the global
+// OpenTelemetry provider. Config.Tracing does the same at construction time.
+func (client *Client) EnableTracing(tp trace.TracerProvider) {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	client.AddHook(&tracingHook{
+		client: client,
+		tracer: tp.Tracer(tracerName),
+	})
+}
+
+type tracingHook struct {
+	client *Client
+	tracer trace.Tracer
+}
+
+func (h *tracingHook) BeforeCommand(ctx context.Context, info *CommandInfo) context.Context {
+	attrs := []attribute.KeyValue{
+		attribute.String("db.system", "redis"),
+		attribute.String("db.operation", info.Name),
+		attribute.Int("db.redis.database_index", h.client.config.DB),
+	}
+	if info.Key != "" {
+		key := info.Key
+		if h.client.config.RedactKeys {
+			key = h.client.redact(key)
+		}
+		attrs = append(attrs, attribute.String("db.redis.key", key))
+	}
+	if info.Pipeline {
+		attrs = append(attrs, attribute.Bool("db.redis.pipeline", true))
+	}
+	if addr := h.client.nodeAddr(); addr != "" {
+		attrs = append(attrs, attribute.String("server.address", addr))
+	}
+
+	ctx, _ = h.tracer.Start(ctx, "redis."+info.Name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(info.Start),
+		trace.WithAttributes(attrs...))
+	return ctx
+}
+
+func (h *tracingHook) AfterCommand(ctx context.Context, info *CommandInfo) {
+	span := trace.SpanFromContext(ctx)
+	if info.Err != nil {
+		span.RecordError(info.Err)
+		span.SetStatus(codes.Error, info.Err.Error())
+	}
+	span.End(trace.WithTimestamp(info.Start.Add(info.Duration)))
+}
@@ -0,0 +1,77 @@
+package redis
+
+import (
+	"context"
+	"sync"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// trackInvalidations keeps the local cache coherent with server assisted
+// client side caching. A separate client with RESP2 connections turns on
+// CLIENT TRACKING in broadcasting mode for the prefix on every new
+// connection, redirected to the connection itself, which then subscribes to
+// __redis__:invalidate. Broadcasting does not depend on which connection read
+// a key, so the pooled connections of the main client need no tracking.
+//
+// The local cache is purged whenever a tracking connection is established, as
+// invalidations sent while it was down are lost. go-redis can not parse the
+// invalidation sent on FLUSHALL, Config.LocalCache.TTL bounds staleness then.
+func (client *Client) trackInvalidations(ctx context.Context, opts goredis.UniversalOptions) {
+	prefix := client.config.Prefix + ":"
+	opts.Protocol = 2
+	opts.PoolSize = 1
+	opts.MinIdleConns = 0
+	opts.OnConnect = func(ctx context.Context, cn *goredis.Conn) error {
+		id, e := cn.ClientID(ctx).Result()
+		if e != nil {
+			return e
+		}
+		client.local.purge()
+		return cn.Process(ctx, goredis.NewStatusCmd(ctx, "CLIENT", "TRACKING", "ON", "REDIRECT", id, "BCAST", "PREFIX", prefix))
+	}
+	tc := newUniversalClient(client.config, &opts)
+	defer tc.Close()
+
+	subscribe := func(node goredis.UniversalClient) {
+		sub := node.Subscribe(ctx, "__redis__:invalidate")
+		defer sub.Close()
+		ch := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+				for _, key := range msg.PayloadSlice {
+					client.local.remove(key)
+				}
+				if msg.Payload != "" {
+					client.local.remove(msg.Payload)
+				}
+			}
+		}
+	}
+
+	cc, ok := tc.(*goredis.ClusterClient)
+	if !ok {
+		subscribe(tc)
+		return
+	}
+	// Tracking is per node, every master gets its own subscription
+	var wg sync.WaitGroup
+	e := cc.ForEachMaster(ctx, func(ctx context.Context, node *goredis.Client) error {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			subscribe(node)
+		}()
+		return nil
+	})
+	if e != nil {
+		client.log().Warn("RedisLocalCache:Tracking", "error", e)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,127 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/acsl-go/redis"
+	"github.com/acsl-go/redis/redistest"
+)
+
+func TestSetTTLMapping(t *testing.T) {
+	client, mr := redistest.NewTestClient(t, nil, redis.WithDefaultTTL(30))
+	ctx := context.Background()
+
+	cases := []struct {
+		name string
+		set  func(key string) error
+		want time.Duration
+	}{
+		{"seconds", func(key string) error { return client.Set(ctx, key, 1, 60) }, time.Minute},
+		{"default", func(key string) error { return client.Set(ctx, key, 1, 0) }, 30 * time.Second},
+		{"duration", func(key string) error { return client.SetStrFor(ctx, key, "v", 1500*time.Millisecond) }, 1500 * time.Millisecond},
+		{"default duration", func(key string) error { return client.SetFor(ctx, key, 1, 0) }, 30 * time.Second},
+	}
+	for _, c := range cases {
+		if e := c.set(c.name); e != nil {
+			t.Fatalf("%s: %v", c.name, e)
+		}
+		if got := mr.TTL("test:" + c.name); got != c.want {
+			t.Errorf("%s: ttl %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestSetWithoutDefaultTTL(t *testing.T) {
+	client, mr := redistest.NewTestClient(t, nil)
+	if e := client.Set(context.Background(), "k", 1, 0); e != nil {
+		t.Fatal(e)
+	}
+	if got := mr.TTL("test:k"); got != 0 {
+		t.Errorf("ttl %v, want no expiry", got)
+	}
+}
+
+func TestSetKeepTTL(t *testing.T) {
+	client, mr := redistest.NewTestClient(t, nil, redis.WithDefaultTTL(30))
+	ctx := context.Background()
+	if e := client.Set(ctx, "k", 1, 60); e != nil {
+		t.Fatal(e)
+	}
+	if e := client.Set(ctx, "k", 2, redis.KeepTTL); e != nil {
+		t.Fatal(e)
+	}
+	if got := mr.TTL("test:k"); got != time.Minute {
+		t.Errorf("ttl %v, want the one kept", got)
+	}
+	var v int
+	if e := client.Get(ctx, "k", &v); e != nil || v != 2 {
+		t.Errorf("Get = %d, %v, want the new value", v, e)
+	}
+}
+
+func TestSetTTLJitter(t *testing.T) {
+	client, mr := redistest.NewTestClient(t, nil, redis.WithTTLJitter(0.1))
+	ctx := context.Background()
+	for i := 0; i < 20; i++ {
+		if e := client.Set(ctx, "k", 1, 100); e != nil {
+			t.Fatal(e)
+		}
+		if got := mr.TTL("test:k"); got < 90*time.Second || got > 110*time.Second {
+			t.Fatalf("ttl %v outside of 100s ±10%%", got)
+		}
+	}
+}
+
+func TestExpireIfTTL(t *testing.T) {
+	client, mr := redistest.NewTestClient(t, nil)
+	ctx := context.Background()
+	if e := client.SetStr(ctx, "k", "v", 0); e != nil {
+		t.Fatal(e)
+	}
+
+	if _, e := client.ExpireIf(ctx, "k", 0, redis.ExpireAlways); !errors.Is(e, redis.ErrInvalidTTL) {
+		t.Errorf("ExpireIf(0) = %v, want ErrInvalidTTL", e)
+	}
+	if !mr.Exists("test:k") {
+		t.Fatal("ExpireIf(0) deleted the key")
+	}
+
+	ok, e := client.ExpireIf(ctx, "k", 500*time.Microsecond, redis.ExpireAlways)
+	if e != nil || !ok {
+		t.Fatalf("ExpireIf = %v, %v", ok, e)
+	}
+	if got := mr.TTL("test:k"); got != time.Millisecond {
+		t.Errorf("ttl %v, want it rounded up to 1ms", got)
+	}
+}
+
+func TestGetExTTL(t *testing.T) {
+	client, mr := redistest.NewTestClient(t, nil)
+	ctx := context.Background()
+	if e := client.Set(ctx, "k", 1, 60); e != nil {
+		t.Fatal(e)
+	}
+
+	var v int
+	if e := client.GetEx(ctx, "k", &v, redis.KeepTTL); e != nil || v != 1 {
+		t.Fatalf("got %d, %v", v, e)
+	}
+	if got := mr.TTL("test:k"); got != time.Minute {
+		t.Errorf("ttl %v after KeepTTL, want 1m", got)
+	}
+	if e := client.GetEx(ctx, "k", &v, 30); e != nil {
+		t.Fatal(e)
+	}
+	if got := mr.TTL("test:k"); got != 30*time.Second {
+		t.Errorf("ttl %v, want 30s", got)
+	}
+	if e := client.GetExFor(ctx, "k", &v, redis.Persist); e != nil {
+		t.Fatal(e)
+	}
+	if got := mr.TTL("test:k"); got != 0 {
+		t.Errorf("ttl %v after Persist, want no expiry", got)
+	}
+}
@@ -0,0 +1,101 @@
+package redis
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+var ErrUpdateConflict = errors.New("redis: concurrent updates kept conflicting")
+
+const defaultUpdateRetries = 10
+
+// Update runs an optimistic read-modify-write of key. fn gets the current raw
+// value, nil when the key is missing, and returns the new one, nil deletes the
+// key. fn is called again with the fresh value when another client wrote the
+// key in between, up to Config.UpdateRetries times.
+func (client *Client) Update(ctx context.Context, key string, ttl int, fn func(current []byte) ([]byte, error)) error {
+	key_str := client.fullKey(key)
+	retries := client.config.UpdateRetries
+	if retries <= 0 {
+		retries = defaultUpdateRetries
+	}
+
+	docs := client.jsonDocs(ctx)
+	txf := func(tx *goredis.Tx) error {
+		var current []byte
+		var e error
+		if docs {
+			var data_str string
+			data_str, e = readDoc(ctx, tx, key_str)
+			current = []byte(data_str)
+		} else {
+			current, e = tx.Get(ctx, key_str).Bytes()
+		}
+		if e != nil && e != goredis.Nil {
+			return e
+		}
+		if e == goredis.Nil || string(current) == notFoundMarker {
+			current = nil
+		}
+		next, e := client.callUpdate(fn, current)
+		if e != nil {
+			return errUserUpdate{e}
+		}
+		_, e = tx.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+			if next == nil {
+				pipe.Del(ctx, key_str)
+			} else if docs {
+				queueSetDoc(ctx, pipe, key_str, string(next), client.expiration(ttl))
+			} else {
+				pipe.Set(ctx, key_str, next, client.expiration(ttl))
+			}
+			return nil
+		})
+		return e
+	}
+
+	for i := 0; i < retries; i++ {
+		e := client.client.Watch(ctx, txf, key_str)
+		switch {
+		case e == nil:
+			client.invalidateLocal(ctx, key_str)
+			return nil
+		case e == goredis.TxFailedErr:
+			continue
+		}
+		if ue, ok := e.(errUserUpdate); ok {
+			return ue.error
+		}
+		return client.wrap(e, "RedisUpdate", "watch", key)
+	}
+	return client.wrap(ErrUpdateConflict, "RedisUpdate", "exec", key)
+}
+
+// UpdateValue is Update with the value decoded into and encoded from v by the
+// codec, v must be a pointer. fn changes v in place.
+func (client *Client) UpdateValue(ctx context.Context, key string, ttl int, v interface{}, fn func(found bool) error) error {
+	return client.Update(ctx, key, ttl, func(current []byte) ([]byte, error) {
+		if current != nil {
+			data_str := stripMeta(string(current))
+			if e := client.codec.Unmarshal([]byte(data_str), v); e != nil {
+				return nil, client.wrap(e, "RedisUpdate:JSONUnmarshal", "", key)
+			}
+		}
+		if e := fn(current != nil); e != nil {
+			return nil, e
+		}
+		return client.codec.Marshal(v)
+	})
+}
+
+// Errors of fn are returned as is and not wrapped as Redis errors.
+type errUserUpdate struct {
+	error
+}
+
+func (client *Client) callUpdate(fn func(current []byte) ([]byte, error), current []byte) (next []byte, e error) {
+	defer client.recoverPanic("update", &e)
+	return fn(current)
+}
@@ -0,0 +1,105 @@
+package redis
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Service is implemented by the background components of the package so that
+// they can be supervised the same way: Start runs the component in its own
+// goroutine, Stop cancels it and waits for it to return, Errors reports the
+// failures it keeps running through.
+type Service interface {
+	Start() error
+	Stop() error
+	Errors() <-chan error
+}
+
+var (
+	ErrServiceStarted    = errors.New("redis: service already started")
+	ErrServiceNotStarted = errors.New("redis: service not started")
+)
+
+// worker implements Service for the components embedding it. The goroutine
+// runs under the context given to the constructor and also ends on Close of
+// the client.
+type worker struct {
+	client *Client
+	name   string
+	parent context.Context
+	run    func(ctx context.Context, report func(error)) error
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+	errs   chan error
+}
+
+func (w *worker) init(client *Client, name string, parent context.Context, run func(ctx context.Context, report func(error)) error) {
+	if parent == nil {
+		parent = context.Background()
+	}
+	w.client, w.name, w.parent, w.run = client, name, parent, run
+	w.errs = make(chan error, 16)
+}
+
+// Start returns ErrServiceStarted when called more than once, a stopped
+// component is not restarted.
+func (w *worker) Start() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.done != nil {
+		return ErrServiceStarted
+	}
+
+	ctx, cancel := context.WithCancel(w.parent)
+	stop := context.AfterFunc(w.client.lifecycle.ctx, cancel)
+	w.cancel = func() {
+		stop()
+		cancel()
+	}
+	w.done = make(chan struct{})
+	go func() {
+		defer close(w.done)
+		defer close(w.errs)
+		defer w.cancel()
+		if e := w.call(ctx); e != nil && ctx.Err() == nil {
+			w.report(e)
+		}
+	}()
+	return nil
+}
+
+func (w *worker) call(ctx context.Context) (e error) {
+	defer w.client.recoverPanic(w.name, &e)
+	return w.run(ctx, w.report)
+}
+
+// Stop cancels the component and waits until its goroutine returned.
+func (w *worker) Stop() error {
+	w.mu.Lock()
+	cancel, done := w.cancel, w.done
+	w.mu.Unlock()
+	if done == nil {
+		return ErrServiceNotStarted
+	}
+	cancel()
+	<-done
+	return nil
+}
+
+// Errors is closed once the component stopped. Errors are dropped while the
+// channel is full, they are logged either way.
+func (w *worker) Errors() <-chan error {
+	return w.errs
+}
+
+func (w *worker) report(e error) {
+	w.client.log().Warn(w.name, "error", e)
+	select {
+	case w.errs <- e:
+	default:
+	}
+}
@@ -0,0 +1,151 @@
+package redis
+
+import (
+	"context"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+func (client *Client) ZAddMember(ctx context.Context, key string, member interface{}, score float64) error {
+	key_str := client.fullKey(key)
+	if e := client.client.ZAdd(ctx, key_str, goredis.Z{Score: score, Member: member}).Err(); e != nil {
+		return client.wrap(e, "RedisZAdd", "zadd", key)
+	}
+	return nil
+}
+
+func (client *Client) ZRangeWithScores(ctx context.Context, key string, start, stop int64) ([]goredis.Z, error) {
+	key_str := client.fullKey(key)
+	zs, e := client.reader(ctx).ZRangeWithScores(ctx, key_str, start, stop).Result()
+	if e != nil {
+		return nil, client.wrap(e, "RedisZRangeWithScores", "zrange", key)
+	}
+	return zs, nil
+}
+
+func (client *Client) ZRevRangeWithScores(ctx context.Context, key string, start, stop int64) ([]goredis.Z, error) {
+	key_str := client.fullKey(key)
+	zs, e := client.reader(ctx).ZRevRangeWithScores(ctx, key_str, start, stop).Result()
+	if e != nil {
+		return nil, client.wrap(e, "RedisZRevRangeWithScores", "zrevrange", key)
+	}
+	return zs, nil
+}
+
+func (client *Client) ZRangeByScore(ctx context.Context, key string, opt *goredis.ZRangeBy) ([]string, error) {
+	key_str := client.fullKey(key)
+	members, e := client.reader(ctx).ZRangeByScore(ctx, key_str, opt).Result()
+	if e != nil {
+		return nil, client.wrap(e, "RedisZRangeByScore", "zrangebyscore", key)
+	}
+	return members, nil
+}
+
+func (client *Client) ZScore(ctx context.Context, key string, member string) (float64, error) {
+	key_str := client.fullKey(key)
+	score, e := client.reader(ctx).ZScore(ctx, key_str, member).Result()
+	if e != nil {
+		if e == goredis.Nil {
+			return 0, ErrNotFound
+		}
+		return 0, client.wrap(e, "RedisZScore", "zscore", key)
+	}
+	return score, nil
+}
+
+func (client *Client) ZRank(ctx context.Context, key string, member string) (int64, error) {
+	key_str := client.fullKey(key)
+	rank, e := client.reader(ctx).ZRank(ctx, key_str, member).Result()
+	if e != nil {
+		if e == goredis.Nil {
+			return 0, ErrNotFound
+		}
+		return 0, client.wrap(e, "RedisZRank", "zrank", key)
+	}
+	return rank, nil
+}
+
+func (client *Client) ZRevRank(ctx context.Context, key string, member string) (int64, error) {
+	key_str := client.fullKey(key)
+	rank, e := client.reader(ctx).ZRevRank(ctx, key_str, member).Result()
+	if e != nil {
+		if e == goredis.Nil {
+			return 0, ErrNotFound
+		}
+		return 0, client.wrap(e, "RedisZRevRank", "zrevrank", key)
+	}
+	return rank, nil
+}
+
+func (client *Client) ZRem(ctx context.Context, key string, members ...interface{}) error {
+	key_str := client.fullKey(key)
+	if e := client.client.ZRem(ctx, key_str, members...).Err(); e != nil {
+		return client.wrap(e, "RedisZRem", "zrem", key)
+	}
+	return nil
+}
+
+func (client *Client) ZIncrBy(ctx context.Context, key string, member string, incr float64) (float64, error) {
+	key_str := client.fullKey(key)
+	score, e := client.client.ZIncrBy(ctx, key_str, incr, member).Result()
+	if e != nil {
+		return 0, client.wrap(e, "RedisZIncrBy", "zincrby", key)
+	}
+	return score, nil
+}
+
+func (client *Client) ZCard(ctx context.Context, key string) (int64, error) {
+	key_str := client.fullKey(key)
+	n, e := client.reader(ctx).ZCard(ctx, key_str).Result()
+	if e != nil {
+		return 0, client.wrap(e, "RedisZCard", "zcard", key)
+	}
+	return n, nil
+}
+
+// ZCount counts members with a score between min and max, both accept the
+// usual "-inf", "+inf" and "(" exclusive notation.
+func (client *Client) ZCount(ctx context.Context, key string, min, max string) (int64, error) {
+	key_str := client.fullKey(key)
+	n, e := client.reader(ctx).ZCount(ctx, key_str, min, max).Result()
+	if e != nil {
+		return 0, client.wrap(e, "RedisZCount", "zcount", key)
+	}
+	return n, nil
+}
+
+func (client *Client) ZRemRangeByRank(ctx context.Context, key string, start, stop int64) (int64, error) {
+	key_str := client.fullKey(key)
+	n, e := client.client.ZRemRangeByRank(ctx, key_str, start, stop).Result()
+	if e != nil {
+		return 0, client.wrap(e, "RedisZRemRangeByRank", "zremrangebyrank", key)
+	}
+	return n, nil
+}
+
+func (client *Client) ZRemRangeByScore(ctx context.Context, key string, min, max string) (int64, error) {
+	key_str := client.fullKey(key)
+	n, e := client.client.ZRemRangeByScore(ctx, key_str, min, max).Result()
+	if e != nil {
+		return 0, client.wrap(e, "RedisZRemRangeByScore", "zremrangebyscore", key)
+	}
+	return n, nil
+}
+
+func (client *Client) ZPopMin(ctx context.Context, key string, count int64) ([]goredis.Z, error) {
+	key_str := client.fullKey(key)
+	zs, e := client.client.ZPopMin(ctx, key_str, count).Result()
+	if e != nil {
+		return nil, client.wrap(e, "RedisZPopMin", "zpopmin", key)
+	}
+	return zs, nil
+}
+
+func (client *Client) ZPopMax(ctx context.Context, key string, count int64) ([]goredis.Z, error) {
+	key_str := client.fullKey(key)
+	zs, e := client.client.ZPopMax(ctx, key_str, count).Result()
+	if e != nil {
+		return nil, client.wrap(e, "RedisZPopMax", "zpopmax", key)
+	}
+	return zs, nil
+}